@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"task_manager/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -94,14 +95,14 @@ func (m *UserService) Promote(id int) error {
 			// Return the string "user not found" if the user is not found in an error format
 			return fmt.Errorf("user not found")
 		}
-		fmt.Println("Error finding user:", err)
+		slog.Error("user lookup failed", "id", id, "error", err)
 		return err
 	}
 
 	update := bson.M{"$set": bson.M{"role": "admin"}}
 	_, err := user_collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("user not promoted", "id", id, "error", err)
 		return err
 	}
 	return nil
@@ -0,0 +1,113 @@
+// Package client is a Go client of the API. Its types and methods are
+// generated from the OpenAPI document of the API, into client_gen.go; this
+// file holds what they are sent with.
+package client
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API at BaseURL, such as "http://localhost:8080", with
+// the token returned by Login, when set.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// Error is a request the API failed, with the message of its body.
+// RetryAfter is how long to wait before retrying requests over the rate
+// limit.
+type Error struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// do sends a request and decodes its JSON response into out, when not nil.
+// in is the body of the request: a value sent as JSON, or a reader of the
+// contentType given.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in interface{}, contentType string, out interface{}) error {
+	var body io.Reader
+	if reader, ok := in.(io.Reader); ok {
+		body = reader
+	} else if value := reflect.ValueOf(in); in != nil && !(value.Kind() == reflect.Pointer && value.IsNil()) {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body, contentType = bytes.NewReader(encoded), "application/json"
+	}
+
+	response, err := c.send(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if out == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// send sends a request and returns its response, or an *Error when it
+// failed.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 400 {
+		return response, nil
+	}
+
+	defer response.Body.Close()
+	failure := &Error{StatusCode: response.StatusCode}
+	var failed ErrorResponse
+	if err := json.NewDecoder(response.Body).Decode(&failed); err == nil {
+		failure.Message = failed.Error
+	}
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+		failure.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return nil, failure
+}
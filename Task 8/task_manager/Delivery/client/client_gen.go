@@ -0,0 +1,1707 @@
+// Code generated by go run ./gen from the OpenAPI document of the API. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Activity struct {
+	Actor     string        `json:"actor,omitempty"`
+	Comment   *Comment      `json:"comment,omitempty"`
+	Revision  *TaskRevision `json:"revision,omitempty"`
+	Timestamp *time.Time    `json:"timestamp,omitempty"`
+	Type      string        `json:"type,omitempty"`
+}
+
+type Attachment struct {
+	ContentType string     `json:"content_type,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Filename    string     `json:"filename,omitempty"`
+	ID          int        `json:"id,omitempty"`
+	SHA256      string     `json:"sha256,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	TaskID      int        `json:"task_id,omitempty"`
+	UploadedBy  string     `json:"uploaded_by,omitempty"`
+}
+
+type AuditEntry struct {
+	Action     string                 `json:"action,omitempty"`
+	Actor      string                 `json:"actor,omitempty"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+	ID         int                    `json:"id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	TargetID   int                    `json:"target_id,omitempty"`
+	TargetType string                 `json:"target_type,omitempty"`
+	Timestamp  *time.Time             `json:"timestamp,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+}
+
+type BoardColumn struct {
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type BoardColumnTasks struct {
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status,omitempty"`
+	Tasks  []Task `json:"tasks,omitempty"`
+}
+
+type BoardMoveRequest struct {
+	Position int    `json:"position,omitempty"`
+	Status   string `json:"status"`
+	TaskID   int    `json:"task_id"`
+}
+
+type BulkFailure struct {
+	Error   string       `json:"error,omitempty"`
+	Results []BulkResult `json:"results,omitempty"`
+}
+
+type BulkOperation struct {
+	Filter *TaskFilter            `json:"filter,omitempty"`
+	ID     int                    `json:"id,omitempty"`
+	Op     string                 `json:"op,omitempty"`
+	Set    map[string]interface{} `json:"set,omitempty"`
+	Task   *Task                  `json:"task,omitempty"`
+}
+
+// BulkRequest is a list of operations on tasks, applied in order.
+type BulkRequest struct {
+	Atomic     bool            `json:"atomic,omitempty"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+	Operations []BulkOperation `json:"operations,omitempty"`
+}
+
+type BulkResponse struct {
+	Results []BulkResult `json:"results,omitempty"`
+}
+
+type BulkResult struct {
+	Error  string `json:"error,omitempty"`
+	ID     int    `json:"id,omitempty"`
+	Index  int    `json:"index,omitempty"`
+	Op     string `json:"op,omitempty"`
+	Status string `json:"status,omitempty"`
+	Task   *Task  `json:"task,omitempty"`
+}
+
+type ChecklistItem struct {
+	Done bool   `json:"done,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+type Comment struct {
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Deleted   bool       `json:"deleted,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Mentions  []string   `json:"mentions,omitempty"`
+	ParentID  int        `json:"parent_id,omitempty"`
+	TaskID    int        `json:"task_id,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type CycleTimeReport struct {
+	CycleTimeHours *Percentiles `json:"cycle_time_hours,omitempty"`
+	LeadTimeHours  *Percentiles `json:"lead_time_hours,omitempty"`
+}
+
+type DeliveryAttempt struct {
+	DurationMs int64      `json:"duration_ms,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StatusCode int        `json:"status_code,omitempty"`
+	Timestamp  *time.Time `json:"timestamp,omitempty"`
+}
+
+type DependenciesResponse struct {
+	BlockedBy []Task `json:"blocked_by,omitempty"`
+	Blocks    []Task `json:"blocks,omitempty"`
+}
+
+type DependencyRequest struct {
+	BlockedBy int `json:"blocked_by"`
+}
+
+// ErrorResponse is the body of every failed request.
+type ErrorResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Event is a change in the workspace, as streamed to clients and webhooks.
+type Event struct {
+	Actor     string      `json:"actor,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	ID        int         `json:"id,omitempty"`
+	Timestamp *time.Time  `json:"timestamp,omitempty"`
+	Type      string      `json:"type,omitempty"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string        `json:"message,omitempty"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type GraphQLRequest struct {
+	OperationName string                 `json:"operationName,omitempty"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is the data a GraphQL operation resolved, and its errors.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type HealthResponse struct {
+	Error  string `json:"error,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type ImportFailure struct {
+	Error  string        `json:"error,omitempty"`
+	Report *ImportReport `json:"report,omitempty"`
+}
+
+// ImportReport is the outcome of an import, row by row.
+type ImportReport struct {
+	Created int         `json:"created,omitempty"`
+	DryRun  bool        `json:"dry_run,omitempty"`
+	Failed  int         `json:"failed,omitempty"`
+	Ignored []string    `json:"ignored,omitempty"`
+	Rows    []ImportRow `json:"rows,omitempty"`
+	Updated int         `json:"updated,omitempty"`
+}
+
+type ImportRow struct {
+	Error      string `json:"error,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	Op         string `json:"op,omitempty"`
+	Row        int    `json:"row,omitempty"`
+	Status     string `json:"status,omitempty"`
+	TaskID     int    `json:"task_id,omitempty"`
+}
+
+type Label struct {
+	Color string `json:"color,omitempty"`
+	ID    int    `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+type LoginRequest struct {
+	Password    string `json:"password,omitempty"`
+	Username    string `json:"username,omitempty"`
+	WorkspaceID int    `json:"workspace_id,omitempty"`
+}
+
+// Membership is the role of a user in a workspace: admin or member.
+type Membership struct {
+	JoinedAt    *time.Time `json:"joined_at,omitempty"`
+	Role        string     `json:"role,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	WorkspaceID int        `json:"workspace_id,omitempty"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message,omitempty"`
+}
+
+type OverdueReport struct {
+	ByAssignee map[string]int `json:"by_assignee,omitempty"`
+	ByPriority map[string]int `json:"by_priority,omitempty"`
+	Total      int            `json:"total,omitempty"`
+}
+
+type Percentiles struct {
+	Count int     `json:"count,omitempty"`
+	Max   float64 `json:"max,omitempty"`
+	Mean  float64 `json:"mean,omitempty"`
+	P50   float64 `json:"p50,omitempty"`
+	P75   float64 `json:"p75,omitempty"`
+	P90   float64 `json:"p90,omitempty"`
+	P95   float64 `json:"p95,omitempty"`
+}
+
+type Progress struct {
+	ChecklistDone     int     `json:"checklist_done,omitempty"`
+	ChecklistTotal    int     `json:"checklist_total,omitempty"`
+	CompletedSubtasks int     `json:"completed_subtasks,omitempty"`
+	Percent           float64 `json:"percent,omitempty"`
+	TotalSubtasks     int     `json:"total_subtasks,omitempty"`
+}
+
+// Project is a project, grouping tasks on a board of columns.
+type Project struct {
+	Columns     []BoardColumn   `json:"columns,omitempty"`
+	CreatedAt   *time.Time      `json:"created_at,omitempty"`
+	Description string          `json:"description,omitempty"`
+	ID          int             `json:"id,omitempty"`
+	Members     []ProjectMember `json:"members,omitempty"`
+	Name        string          `json:"name,omitempty"`
+}
+
+// ProjectMember is the role of a user in a project: owner, maintainer, member or viewer.
+type ProjectMember struct {
+	Role     string `json:"role,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type Reminder struct {
+	DueDate     string     `json:"due_date,omitempty"`
+	ID          int        `json:"id,omitempty"`
+	Kind        string     `json:"kind,omitempty"`
+	LeadMinutes int        `json:"lead_minutes,omitempty"`
+	Recipient   string     `json:"recipient,omitempty"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	TaskID      int        `json:"task_id,omitempty"`
+}
+
+type SearchHit struct {
+	CommentID  int               `json:"comment_id,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+	Score      float64           `json:"score,omitempty"`
+	TaskID     int               `json:"task_id,omitempty"`
+	Title      string            `json:"title,omitempty"`
+	Type       string            `json:"type,omitempty"`
+}
+
+type SubtasksResponse struct {
+	Progress *Progress `json:"progress,omitempty"`
+	Subtasks []Task    `json:"subtasks,omitempty"`
+}
+
+// Task is a task. Its id, timestamps and revision are kept by the API.
+type Task struct {
+	Assignee        string          `json:"assignee,omitempty"`
+	BlockedBy       []int           `json:"blocked_by,omitempty"`
+	Checklist       []ChecklistItem `json:"checklist,omitempty"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt       *time.Time      `json:"created_at,omitempty"`
+	DeletedAt       *time.Time      `json:"deleted_at,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	DueDate         string          `json:"due_date,omitempty"`
+	EstimateMinutes int             `json:"estimate_minutes,omitempty"`
+	ExternalID      string          `json:"external_id,omitempty"`
+	ID              int             `json:"id,omitempty"`
+	Labels          []string        `json:"labels,omitempty"`
+	Occurrence      int             `json:"occurrence,omitempty"`
+	Overdue         bool            `json:"overdue,omitempty"`
+	ParentID        int             `json:"parent_id,omitempty"`
+	Priority        string          `json:"priority,omitempty"`
+	ProjectID       int             `json:"project_id,omitempty"`
+	Rank            float64         `json:"rank,omitempty"`
+	Recurrence      string          `json:"recurrence,omitempty"`
+	SeriesID        int             `json:"series_id,omitempty"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	Status          string          `json:"status,omitempty"`
+	StoryPoints     float64         `json:"story_points,omitempty"`
+	Title           string          `json:"title,omitempty"`
+}
+
+type TaskFacets struct {
+	Labels   map[string]int `json:"labels,omitempty"`
+	Priority map[string]int `json:"priority,omitempty"`
+	Status   map[string]int `json:"status,omitempty"`
+	Total    int            `json:"total,omitempty"`
+}
+
+type TaskFilter struct {
+	AnyLabels []string `json:"any_labels,omitempty"`
+	Assignee  []string `json:"assignee,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Overdue   bool     `json:"overdue,omitempty"`
+	Priority  []string `json:"priority,omitempty"`
+	Status    []string `json:"status,omitempty"`
+}
+
+type TaskRevision struct {
+	Action    string                 `json:"action,omitempty"`
+	Actor     string                 `json:"actor,omitempty"`
+	Changes   map[string]FieldChange `json:"changes,omitempty"`
+	Revision  int                    `json:"revision,omitempty"`
+	Task      *Task                  `json:"task,omitempty"`
+	TaskID    int                    `json:"task_id,omitempty"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+}
+
+type TaskTimeUsage struct {
+	Assignee        string         `json:"assignee,omitempty"`
+	EstimateMinutes int            `json:"estimate_minutes,omitempty"`
+	LoggedMinutes   int            `json:"logged_minutes,omitempty"`
+	OverEstimate    bool           `json:"over_estimate,omitempty"`
+	Status          string         `json:"status,omitempty"`
+	TaskID          int            `json:"task_id,omitempty"`
+	Title           string         `json:"title,omitempty"`
+	Users           map[string]int `json:"users,omitempty"`
+	VarianceMinutes int            `json:"variance_minutes,omitempty"`
+}
+
+type ThroughputBucket struct {
+	Completed int    `json:"completed,omitempty"`
+	Created   int    `json:"created,omitempty"`
+	Period    string `json:"period,omitempty"`
+}
+
+type TimeReport struct {
+	EstimateMinutes int             `json:"estimate_minutes,omitempty"`
+	LoggedMinutes   int             `json:"logged_minutes,omitempty"`
+	Tasks           []TaskTimeUsage `json:"tasks,omitempty"`
+}
+
+type TimeTotal struct {
+	Day     string `json:"day,omitempty"`
+	Entries int    `json:"entries,omitempty"`
+	Minutes int    `json:"minutes,omitempty"`
+	TaskID  int    `json:"task_id,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
+type TimerStopRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+type TokenResponse struct {
+	Message     string `json:"message,omitempty"`
+	Token       string `json:"token,omitempty"`
+	WorkspaceID int    `json:"workspace_id,omitempty"`
+}
+
+// User is an account. Its password is only ever written.
+type User struct {
+	Email    string `json:"email,omitempty"`
+	ID       int    `json:"id,omitempty"`
+	Password string `json:"password,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// UserWorkspace is a workspace along with the role of the user in it.
+type UserWorkspace struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Role      string     `json:"role,omitempty"`
+}
+
+// Webhook is a URL events of the workspace are posted to.
+type Webhook struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Disabled  bool       `json:"disabled,omitempty"`
+	Events    []string   `json:"events,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Secret    string     `json:"secret,omitempty"`
+	URL       string     `json:"url,omitempty"`
+}
+
+// WebhookDelivery is the posting of an event to a webhook, with its attempts.
+type WebhookDelivery struct {
+	Attempts      []DeliveryAttempt `json:"attempts,omitempty"`
+	CreatedAt     *time.Time        `json:"created_at,omitempty"`
+	Event         string            `json:"event,omitempty"`
+	ID            int               `json:"id,omitempty"`
+	NextAttemptAt *time.Time        `json:"next_attempt_at,omitempty"`
+	Payload       string            `json:"payload,omitempty"`
+	RedeliveryOf  int               `json:"redelivery_of,omitempty"`
+	Status        string            `json:"status,omitempty"`
+	WebhookID     int               `json:"webhook_id,omitempty"`
+}
+
+type Workload struct {
+	Assignee        string  `json:"assignee,omitempty"`
+	EstimateMinutes int     `json:"estimate_minutes,omitempty"`
+	InProgress      int     `json:"in_progress,omitempty"`
+	Open            int     `json:"open,omitempty"`
+	Overdue         int     `json:"overdue,omitempty"`
+	StoryPoints     float64 `json:"story_points,omitempty"`
+}
+
+type Worklog struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Minutes   int        `json:"minutes,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Running   bool       `json:"running,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	TaskID    int        `json:"task_id,omitempty"`
+	User      string     `json:"user,omitempty"`
+}
+
+// Workspace is a workspace, which holds its own tasks, projects and labels.
+type Workspace struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+}
+
+// AddDependency makes a task blocked by another, with POST /v1/tasks/{id}/dependencies.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) AddDependency(ctx context.Context, id int, body DependencyRequest) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/dependencies", nil, body, "", &out)
+	return out, err
+}
+
+// BulkTasks runs operations on several tasks at once, with POST /v1/tasks/bulk.
+//
+// Atomic requests apply all their operations or none. Requires the admin role in the workspace of the token.
+func (c *Client) BulkTasks(ctx context.Context, body BulkRequest) (BulkResponse, error) {
+	var out BulkResponse
+	err := c.do(ctx, "POST", "/v1/tasks/bulk", nil, body, "", &out)
+	return out, err
+}
+
+// CreateComment comments on a task, with POST /v1/tasks/{id}/comments.
+func (c *Client) CreateComment(ctx context.Context, id int, body Comment) (Comment, error) {
+	var out Comment
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/comments", nil, body, "", &out)
+	return out, err
+}
+
+// CreateLabel creates a label, with POST /v1/labels.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) CreateLabel(ctx context.Context, body Label) (Label, error) {
+	var out Label
+	err := c.do(ctx, "POST", "/v1/labels", nil, body, "", &out)
+	return out, err
+}
+
+// CreateProject creates a project, with POST /v1/projects.
+//
+// The user becomes its owner.
+func (c *Client) CreateProject(ctx context.Context, body Project) (Project, error) {
+	var out Project
+	err := c.do(ctx, "POST", "/v1/projects", nil, body, "", &out)
+	return out, err
+}
+
+// CreateProjectTask creates a task in a project, with POST /v1/projects/{id}/tasks.
+func (c *Client) CreateProjectTask(ctx context.Context, id int, body Task) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/projects/"+strconv.Itoa(id)+"/tasks", nil, body, "", &out)
+	return out, err
+}
+
+// CreateSubtask creates a subtask, with POST /v1/tasks/{id}/subtasks.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) CreateSubtask(ctx context.Context, id int, body Task) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/subtasks", nil, body, "", &out)
+	return out, err
+}
+
+// CreateTask creates a task, with POST /v1/tasks.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) CreateTask(ctx context.Context, body Task) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/tasks", nil, body, "", &out)
+	return out, err
+}
+
+// CreateWebhook creates a webhook, with POST /v1/webhooks.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) CreateWebhook(ctx context.Context, body Webhook) (Webhook, error) {
+	var out Webhook
+	err := c.do(ctx, "POST", "/v1/webhooks", nil, body, "", &out)
+	return out, err
+}
+
+// CreateWorkspace creates a workspace, with POST /v1/workspaces.
+//
+// The user becomes its admin.
+func (c *Client) CreateWorkspace(ctx context.Context, body Workspace) (Workspace, error) {
+	var out Workspace
+	err := c.do(ctx, "POST", "/v1/workspaces", nil, body, "", &out)
+	return out, err
+}
+
+// DeleteAttachment deletes an attachment, with DELETE /v1/tasks/{id}/attachments/{attachment_id}.
+func (c *Client) DeleteAttachment(ctx context.Context, id int, attachmentID int) error {
+	return c.do(ctx, "DELETE", "/v1/tasks/"+strconv.Itoa(id)+"/attachments/"+strconv.Itoa(attachmentID), nil, nil, "", nil)
+}
+
+// DeleteComment deletes a comment, with DELETE /v1/tasks/{id}/comments/{comment_id}.
+func (c *Client) DeleteComment(ctx context.Context, id int, commentID int) error {
+	return c.do(ctx, "DELETE", "/v1/tasks/"+strconv.Itoa(id)+"/comments/"+strconv.Itoa(commentID), nil, nil, "", nil)
+}
+
+// DeleteLabel deletes a label, with DELETE /v1/labels/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) DeleteLabel(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/v1/labels/"+strconv.Itoa(id), nil, nil, "", nil)
+}
+
+// DeleteProject deletes a project, with DELETE /v1/projects/{id}.
+func (c *Client) DeleteProject(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/v1/projects/"+strconv.Itoa(id), nil, nil, "", nil)
+}
+
+// DeleteTask moves a task to the trash, with DELETE /v1/tasks/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) DeleteTask(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/v1/tasks/"+strconv.Itoa(id), nil, nil, "", nil)
+}
+
+// DeleteWebhook deletes a webhook, with DELETE /v1/webhooks/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/v1/webhooks/"+strconv.Itoa(id), nil, nil, "", nil)
+}
+
+// DeleteWorklog deletes a worklog, with DELETE /v1/tasks/{id}/worklogs/{worklog_id}.
+func (c *Client) DeleteWorklog(ctx context.Context, id int, worklogID int) error {
+	return c.do(ctx, "DELETE", "/v1/tasks/"+strconv.Itoa(id)+"/worklogs/"+strconv.Itoa(worklogID), nil, nil, "", nil)
+}
+
+// Docs browses this document, with GET /docs.
+//
+// The caller closes the body of the response.
+func (c *Client) Docs(ctx context.Context) (*http.Response, error) {
+	return c.send(ctx, "GET", "/docs", nil, nil, "")
+}
+
+// DownloadAttachment downloads an attachment, with GET /v1/tasks/{id}/attachments/{attachment_id}.
+//
+// Supports range requests; the ETag is the SHA-256 of the file.
+//
+// The caller closes the body of the response.
+func (c *Client) DownloadAttachment(ctx context.Context, id int, attachmentID int) (*http.Response, error) {
+	return c.send(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/attachments/"+strconv.Itoa(attachmentID), nil, nil, "")
+}
+
+// ExportAuditLogParams are the query parameters of ExportAuditLog.
+type ExportAuditLogParams struct {
+	// User who acted
+	Actor string
+	// Action, such as task.updated
+	Action string
+	// Type of the target, such as task
+	TargetType string
+	// ID of the target
+	TargetID int
+	// Start of the range
+	From time.Time
+	// End of the range
+	To time.Time
+}
+
+func (p *ExportAuditLogParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Actor != "" {
+		values.Set("actor", p.Actor)
+	}
+	if p.Action != "" {
+		values.Set("action", p.Action)
+	}
+	if p.TargetType != "" {
+		values.Set("target_type", p.TargetType)
+	}
+	if p.TargetID != 0 {
+		values.Set("target_id", strconv.Itoa(p.TargetID))
+	}
+	if !p.From.IsZero() {
+		values.Set("from", p.From.Format(time.RFC3339))
+	}
+	if !p.To.IsZero() {
+		values.Set("to", p.To.Format(time.RFC3339))
+	}
+	return values
+}
+
+// ExportAuditLog exports the audit log, with GET /v1/audit/export.
+//
+// Requires the admin role in the workspace of the token.
+//
+// The caller closes the body of the response.
+func (c *Client) ExportAuditLog(ctx context.Context, params *ExportAuditLogParams) (*http.Response, error) {
+	return c.send(ctx, "GET", "/v1/audit/export", params.values(), nil, "")
+}
+
+// ExportTasksParams are the query parameters of ExportTasks.
+type ExportTasksParams struct {
+	// Format of the export
+	Format string
+	// Labels the tasks all have
+	Labels []string
+	// Labels the tasks have at least one of
+	AnyLabels []string
+	// Priorities of the tasks
+	Priority []string
+	// Statuses of the tasks
+	Status []string
+	// Assignees of the tasks
+	Assignee []string
+	// Only the overdue tasks
+	Overdue bool
+}
+
+func (p *ExportTasksParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Format != "" {
+		values.Set("format", p.Format)
+	}
+	if len(p.Labels) > 0 {
+		values.Set("labels", strings.Join(p.Labels, ","))
+	}
+	if len(p.AnyLabels) > 0 {
+		values.Set("any_labels", strings.Join(p.AnyLabels, ","))
+	}
+	if len(p.Priority) > 0 {
+		values.Set("priority", strings.Join(p.Priority, ","))
+	}
+	if len(p.Status) > 0 {
+		values.Set("status", strings.Join(p.Status, ","))
+	}
+	if len(p.Assignee) > 0 {
+		values.Set("assignee", strings.Join(p.Assignee, ","))
+	}
+	if p.Overdue {
+		values.Set("overdue", "true")
+	}
+	return values
+}
+
+// ExportTasks exports tasks, with GET /v1/tasks/export.
+//
+// The caller closes the body of the response.
+func (c *Client) ExportTasks(ctx context.Context, params *ExportTasksParams) (*http.Response, error) {
+	return c.send(ctx, "GET", "/v1/tasks/export", params.values(), nil, "")
+}
+
+// GetBoard gets the board of a project, with GET /v1/projects/{id}/board.
+func (c *Client) GetBoard(ctx context.Context, id int) ([]BoardColumnTasks, error) {
+	var out []BoardColumnTasks
+	err := c.do(ctx, "GET", "/v1/projects/"+strconv.Itoa(id)+"/board", nil, nil, "", &out)
+	return out, err
+}
+
+// GetCycleTimeReportParams are the query parameters of GetCycleTimeReport.
+type GetCycleTimeReportParams struct {
+	// Interval of the buckets
+	Interval string
+	// Project of the tasks
+	ProjectID int
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *GetCycleTimeReportParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Interval != "" {
+		values.Set("interval", p.Interval)
+	}
+	if p.ProjectID != 0 {
+		values.Set("project_id", strconv.Itoa(p.ProjectID))
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// GetCycleTimeReport measures how long tasks take to complete, with GET /v1/reports/cycle-time.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetCycleTimeReport(ctx context.Context, params *GetCycleTimeReportParams) (CycleTimeReport, error) {
+	var out CycleTimeReport
+	err := c.do(ctx, "GET", "/v1/reports/cycle-time", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetOverdueReportParams are the query parameters of GetOverdueReport.
+type GetOverdueReportParams struct {
+	// Interval of the buckets
+	Interval string
+	// Project of the tasks
+	ProjectID int
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *GetOverdueReportParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Interval != "" {
+		values.Set("interval", p.Interval)
+	}
+	if p.ProjectID != 0 {
+		values.Set("project_id", strconv.Itoa(p.ProjectID))
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// GetOverdueReport lists the overdue tasks, with GET /v1/reports/overdue.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetOverdueReport(ctx context.Context, params *GetOverdueReportParams) (OverdueReport, error) {
+	var out OverdueReport
+	err := c.do(ctx, "GET", "/v1/reports/overdue", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetProject gets a project, with GET /v1/projects/{id}.
+func (c *Client) GetProject(ctx context.Context, id int) (Project, error) {
+	var out Project
+	err := c.do(ctx, "GET", "/v1/projects/"+strconv.Itoa(id), nil, nil, "", &out)
+	return out, err
+}
+
+// GetTask gets a task, with GET /v1/tasks/{id}.
+func (c *Client) GetTask(ctx context.Context, id int) (Task, error) {
+	var out Task
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id), nil, nil, "", &out)
+	return out, err
+}
+
+// GetTaskFacetsParams are the query parameters of GetTaskFacets.
+type GetTaskFacetsParams struct {
+	// Labels the tasks all have
+	Labels []string
+	// Labels the tasks have at least one of
+	AnyLabels []string
+	// Priorities of the tasks
+	Priority []string
+	// Statuses of the tasks
+	Status []string
+	// Assignees of the tasks
+	Assignee []string
+	// Only the overdue tasks
+	Overdue bool
+}
+
+func (p *GetTaskFacetsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Labels) > 0 {
+		values.Set("labels", strings.Join(p.Labels, ","))
+	}
+	if len(p.AnyLabels) > 0 {
+		values.Set("any_labels", strings.Join(p.AnyLabels, ","))
+	}
+	if len(p.Priority) > 0 {
+		values.Set("priority", strings.Join(p.Priority, ","))
+	}
+	if len(p.Status) > 0 {
+		values.Set("status", strings.Join(p.Status, ","))
+	}
+	if len(p.Assignee) > 0 {
+		values.Set("assignee", strings.Join(p.Assignee, ","))
+	}
+	if p.Overdue {
+		values.Set("overdue", "true")
+	}
+	return values
+}
+
+// GetTaskFacets counts tasks per label, status and priority, with GET /v1/tasks/facets.
+func (c *Client) GetTaskFacets(ctx context.Context, params *GetTaskFacetsParams) (TaskFacets, error) {
+	var out TaskFacets
+	err := c.do(ctx, "GET", "/v1/tasks/facets", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetTaskHistory lists the revisions of a task, with GET /v1/tasks/{id}/history.
+func (c *Client) GetTaskHistory(ctx context.Context, id int) ([]TaskRevision, error) {
+	var out []TaskRevision
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/history", nil, nil, "", &out)
+	return out, err
+}
+
+// GetThroughputReportParams are the query parameters of GetThroughputReport.
+type GetThroughputReportParams struct {
+	// Interval of the buckets
+	Interval string
+	// Project of the tasks
+	ProjectID int
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *GetThroughputReportParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Interval != "" {
+		values.Set("interval", p.Interval)
+	}
+	if p.ProjectID != 0 {
+		values.Set("project_id", strconv.Itoa(p.ProjectID))
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// GetThroughputReport counts the tasks created and completed per interval, with GET /v1/reports/throughput.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetThroughputReport(ctx context.Context, params *GetThroughputReportParams) ([]ThroughputBucket, error) {
+	var out []ThroughputBucket
+	err := c.do(ctx, "GET", "/v1/reports/throughput", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetTimeReportParams are the query parameters of GetTimeReport.
+type GetTimeReportParams struct {
+	// Project of the tasks
+	ProjectID int
+	// Labels the tasks all have
+	Labels []string
+	// Labels the tasks have at least one of
+	AnyLabels []string
+	// Priorities of the tasks
+	Priority []string
+	// Statuses of the tasks
+	Status []string
+	// Assignees of the tasks
+	Assignee []string
+	// Only the overdue tasks
+	Overdue bool
+	// User who logged the time
+	User string
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *GetTimeReportParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.ProjectID != 0 {
+		values.Set("project_id", strconv.Itoa(p.ProjectID))
+	}
+	if len(p.Labels) > 0 {
+		values.Set("labels", strings.Join(p.Labels, ","))
+	}
+	if len(p.AnyLabels) > 0 {
+		values.Set("any_labels", strings.Join(p.AnyLabels, ","))
+	}
+	if len(p.Priority) > 0 {
+		values.Set("priority", strings.Join(p.Priority, ","))
+	}
+	if len(p.Status) > 0 {
+		values.Set("status", strings.Join(p.Status, ","))
+	}
+	if len(p.Assignee) > 0 {
+		values.Set("assignee", strings.Join(p.Assignee, ","))
+	}
+	if p.Overdue {
+		values.Set("overdue", "true")
+	}
+	if p.User != "" {
+		values.Set("user", p.User)
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// GetTimeReport compares the time logged on tasks to their estimates, with GET /v1/reports/time.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetTimeReport(ctx context.Context, params *GetTimeReportParams) (TimeReport, error) {
+	var out TimeReport
+	err := c.do(ctx, "GET", "/v1/reports/time", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetTimeTotalsParams are the query parameters of GetTimeTotals.
+type GetTimeTotalsParams struct {
+	// User who logged the time
+	User string
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+	// Task the time was logged on
+	TaskID int
+	// Grouping of the totals, by task when not given
+	GroupBy []string
+}
+
+func (p *GetTimeTotalsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.User != "" {
+		values.Set("user", p.User)
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	if p.TaskID != 0 {
+		values.Set("task_id", strconv.Itoa(p.TaskID))
+	}
+	if len(p.GroupBy) > 0 {
+		values.Set("group_by", strings.Join(p.GroupBy, ","))
+	}
+	return values
+}
+
+// GetTimeTotals totals the time logged, with GET /v1/worklogs/totals.
+func (c *Client) GetTimeTotals(ctx context.Context, params *GetTimeTotalsParams) ([]TimeTotal, error) {
+	var out []TimeTotal
+	err := c.do(ctx, "GET", "/v1/worklogs/totals", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GetWebhook gets a webhook, with GET /v1/webhooks/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetWebhook(ctx context.Context, id int) (Webhook, error) {
+	var out Webhook
+	err := c.do(ctx, "GET", "/v1/webhooks/"+strconv.Itoa(id), nil, nil, "", &out)
+	return out, err
+}
+
+// GetWebhookDelivery gets a delivery, with GET /v1/webhooks/{id}/deliveries/{delivery_id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetWebhookDelivery(ctx context.Context, id int, deliveryID int) (WebhookDelivery, error) {
+	var out WebhookDelivery
+	err := c.do(ctx, "GET", "/v1/webhooks/"+strconv.Itoa(id)+"/deliveries/"+strconv.Itoa(deliveryID), nil, nil, "", &out)
+	return out, err
+}
+
+// GetWorkloadReportParams are the query parameters of GetWorkloadReport.
+type GetWorkloadReportParams struct {
+	// Interval of the buckets
+	Interval string
+	// Project of the tasks
+	ProjectID int
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *GetWorkloadReportParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Interval != "" {
+		values.Set("interval", p.Interval)
+	}
+	if p.ProjectID != 0 {
+		values.Set("project_id", strconv.Itoa(p.ProjectID))
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// GetWorkloadReport counts the open tasks of each assignee, with GET /v1/reports/workload.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) GetWorkloadReport(ctx context.Context, params *GetWorkloadReportParams) ([]Workload, error) {
+	var out []Workload
+	err := c.do(ctx, "GET", "/v1/reports/workload", params.values(), nil, "", &out)
+	return out, err
+}
+
+// GraphQL runs a GraphQL query or mutation, with POST /graphql.
+//
+// The schema is Delivery/graphql/schema.graphql; introspection is allowed. The failures of the operation, such as a mutation needing the admin role or an operation over the complexity limit, are in the errors of a 200 response.
+func (c *Client) GraphQL(ctx context.Context, body GraphQLRequest) (GraphQLResponse, error) {
+	var out GraphQLResponse
+	err := c.do(ctx, "POST", "/graphql", nil, body, "", &out)
+	return out, err
+}
+
+// Healthz checks the service is alive, with GET /healthz.
+func (c *Client) Healthz(ctx context.Context) (HealthResponse, error) {
+	var out HealthResponse
+	err := c.do(ctx, "GET", "/healthz", nil, nil, "", &out)
+	return out, err
+}
+
+// ImportTasksParams are the query parameters of ImportTasks.
+type ImportTasksParams struct {
+	// Format of the import
+	Format string
+	// Check the import without applying it
+	DryRun bool
+	// Import every row or none
+	Atomic bool
+	// Pairs of column:field mapping the columns of a CSV import to task fields
+	Map []string
+}
+
+func (p *ImportTasksParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Format != "" {
+		values.Set("format", p.Format)
+	}
+	if p.DryRun {
+		values.Set("dry_run", "true")
+	}
+	if p.Atomic {
+		values.Set("atomic", "true")
+	}
+	for _, value := range p.Map {
+		values.Add("map", value)
+	}
+	return values
+}
+
+// ImportTasks imports tasks, with POST /v1/tasks/import.
+//
+// Takes up to 10 MB of CSV or JSON lines; the format is guessed from the content type when not given. Requires the admin role in the workspace of the token.
+func (c *Client) ImportTasks(ctx context.Context, params *ImportTasksParams, body io.Reader, contentType string) (ImportReport, error) {
+	var out ImportReport
+	err := c.do(ctx, "POST", "/v1/tasks/import", params.values(), body, contentType, &out)
+	return out, err
+}
+
+// ListActivity lists the comments and status changes of a task, with GET /v1/tasks/{id}/activity.
+func (c *Client) ListActivity(ctx context.Context, id int) ([]Activity, error) {
+	var out []Activity
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/activity", nil, nil, "", &out)
+	return out, err
+}
+
+// ListAttachments lists the attachments of a task, with GET /v1/tasks/{id}/attachments.
+func (c *Client) ListAttachments(ctx context.Context, id int) ([]Attachment, error) {
+	var out []Attachment
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/attachments", nil, nil, "", &out)
+	return out, err
+}
+
+// ListAuditEntriesParams are the query parameters of ListAuditEntries.
+type ListAuditEntriesParams struct {
+	// User who acted
+	Actor string
+	// Action, such as task.updated
+	Action string
+	// Type of the target, such as task
+	TargetType string
+	// ID of the target
+	TargetID int
+	// Start of the range
+	From time.Time
+	// End of the range
+	To time.Time
+	// Entries skipped
+	Skip int
+	// Entries returned at most
+	Limit int
+}
+
+func (p *ListAuditEntriesParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Actor != "" {
+		values.Set("actor", p.Actor)
+	}
+	if p.Action != "" {
+		values.Set("action", p.Action)
+	}
+	if p.TargetType != "" {
+		values.Set("target_type", p.TargetType)
+	}
+	if p.TargetID != 0 {
+		values.Set("target_id", strconv.Itoa(p.TargetID))
+	}
+	if !p.From.IsZero() {
+		values.Set("from", p.From.Format(time.RFC3339))
+	}
+	if !p.To.IsZero() {
+		values.Set("to", p.To.Format(time.RFC3339))
+	}
+	if p.Skip != 0 {
+		values.Set("skip", strconv.Itoa(p.Skip))
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return values
+}
+
+// ListAuditEntries lists the audit log, with GET /v1/audit.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) ListAuditEntries(ctx context.Context, params *ListAuditEntriesParams) ([]AuditEntry, error) {
+	var out []AuditEntry
+	err := c.do(ctx, "GET", "/v1/audit", params.values(), nil, "", &out)
+	return out, err
+}
+
+// ListCommentsParams are the query parameters of ListComments.
+type ListCommentsParams struct {
+	// Comments skipped
+	Skip int
+	// Comments returned at most
+	Limit int
+}
+
+func (p *ListCommentsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Skip != 0 {
+		values.Set("skip", strconv.Itoa(p.Skip))
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return values
+}
+
+// ListComments lists the comments of a task, with GET /v1/tasks/{id}/comments.
+func (c *Client) ListComments(ctx context.Context, id int, params *ListCommentsParams) ([]Comment, error) {
+	var out []Comment
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/comments", params.values(), nil, "", &out)
+	return out, err
+}
+
+// ListDependencies lists the tasks a task blocks and is blocked by, with GET /v1/tasks/{id}/dependencies.
+func (c *Client) ListDependencies(ctx context.Context, id int) (DependenciesResponse, error) {
+	var out DependenciesResponse
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/dependencies", nil, nil, "", &out)
+	return out, err
+}
+
+// ListLabels lists the labels, with GET /v1/labels.
+func (c *Client) ListLabels(ctx context.Context) ([]Label, error) {
+	var out []Label
+	err := c.do(ctx, "GET", "/v1/labels", nil, nil, "", &out)
+	return out, err
+}
+
+// ListOccurrences lists the occurrences of a recurring task, with GET /v1/tasks/{id}/occurrences.
+func (c *Client) ListOccurrences(ctx context.Context, id int) ([]Task, error) {
+	var out []Task
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/occurrences", nil, nil, "", &out)
+	return out, err
+}
+
+// ListProjectTasks lists the tasks of a project, with GET /v1/projects/{id}/tasks.
+func (c *Client) ListProjectTasks(ctx context.Context, id int) ([]Task, error) {
+	var out []Task
+	err := c.do(ctx, "GET", "/v1/projects/"+strconv.Itoa(id)+"/tasks", nil, nil, "", &out)
+	return out, err
+}
+
+// ListProjects lists the projects of the user, with GET /v1/projects.
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	var out []Project
+	err := c.do(ctx, "GET", "/v1/projects", nil, nil, "", &out)
+	return out, err
+}
+
+// ListReminders lists the reminders of a task, with GET /v1/tasks/{id}/reminders.
+func (c *Client) ListReminders(ctx context.Context, id int) ([]Reminder, error) {
+	var out []Reminder
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/reminders", nil, nil, "", &out)
+	return out, err
+}
+
+// ListSubtasks lists the subtasks of a task, with GET /v1/tasks/{id}/subtasks.
+func (c *Client) ListSubtasks(ctx context.Context, id int) (SubtasksResponse, error) {
+	var out SubtasksResponse
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/subtasks", nil, nil, "", &out)
+	return out, err
+}
+
+// ListTasksParams are the query parameters of ListTasks.
+type ListTasksParams struct {
+	// Labels the tasks all have
+	Labels []string
+	// Labels the tasks have at least one of
+	AnyLabels []string
+	// Priorities of the tasks
+	Priority []string
+	// Statuses of the tasks
+	Status []string
+	// Assignees of the tasks
+	Assignee []string
+	// Only the overdue tasks
+	Overdue bool
+}
+
+func (p *ListTasksParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Labels) > 0 {
+		values.Set("labels", strings.Join(p.Labels, ","))
+	}
+	if len(p.AnyLabels) > 0 {
+		values.Set("any_labels", strings.Join(p.AnyLabels, ","))
+	}
+	if len(p.Priority) > 0 {
+		values.Set("priority", strings.Join(p.Priority, ","))
+	}
+	if len(p.Status) > 0 {
+		values.Set("status", strings.Join(p.Status, ","))
+	}
+	if len(p.Assignee) > 0 {
+		values.Set("assignee", strings.Join(p.Assignee, ","))
+	}
+	if p.Overdue {
+		values.Set("overdue", "true")
+	}
+	return values
+}
+
+// ListTasks lists tasks, with GET /v1/tasks.
+func (c *Client) ListTasks(ctx context.Context, params *ListTasksParams) ([]Task, error) {
+	var out []Task
+	err := c.do(ctx, "GET", "/v1/tasks", params.values(), nil, "", &out)
+	return out, err
+}
+
+// ListTrash lists the tasks in the trash, with GET /v1/tasks/trash.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) ListTrash(ctx context.Context) ([]Task, error) {
+	var out []Task
+	err := c.do(ctx, "GET", "/v1/tasks/trash", nil, nil, "", &out)
+	return out, err
+}
+
+// ListUsers lists the users, with GET /v1/users.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var out []User
+	err := c.do(ctx, "GET", "/v1/users", nil, nil, "", &out)
+	return out, err
+}
+
+// ListWebhookDeliveriesParams are the query parameters of ListWebhookDeliveries.
+type ListWebhookDeliveriesParams struct {
+	// Status of the deliveries
+	Status string
+}
+
+func (p *ListWebhookDeliveriesParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Status != "" {
+		values.Set("status", p.Status)
+	}
+	return values
+}
+
+// ListWebhookDeliveries lists the deliveries of a webhook, with GET /v1/webhooks/{id}/deliveries.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int, params *ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	var out []WebhookDelivery
+	err := c.do(ctx, "GET", "/v1/webhooks/"+strconv.Itoa(id)+"/deliveries", params.values(), nil, "", &out)
+	return out, err
+}
+
+// ListWebhooks lists the webhooks, with GET /v1/webhooks.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var out []Webhook
+	err := c.do(ctx, "GET", "/v1/webhooks", nil, nil, "", &out)
+	return out, err
+}
+
+// ListWorklogsParams are the query parameters of ListWorklogs.
+type ListWorklogsParams struct {
+	// User who logged the time
+	User string
+	// Start of the range, as an RFC 3339 time or a date
+	From string
+	// End of the range, as an RFC 3339 time or a date, which includes that day
+	To string
+}
+
+func (p *ListWorklogsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.User != "" {
+		values.Set("user", p.User)
+	}
+	if p.From != "" {
+		values.Set("from", p.From)
+	}
+	if p.To != "" {
+		values.Set("to", p.To)
+	}
+	return values
+}
+
+// ListWorklogs lists the time logged on a task, with GET /v1/tasks/{id}/worklogs.
+func (c *Client) ListWorklogs(ctx context.Context, id int, params *ListWorklogsParams) ([]Worklog, error) {
+	var out []Worklog
+	err := c.do(ctx, "GET", "/v1/tasks/"+strconv.Itoa(id)+"/worklogs", params.values(), nil, "", &out)
+	return out, err
+}
+
+// ListWorkspaceMembers lists the members of a workspace, with GET /v1/workspaces/{id}/members.
+func (c *Client) ListWorkspaceMembers(ctx context.Context, id int) ([]Membership, error) {
+	var out []Membership
+	err := c.do(ctx, "GET", "/v1/workspaces/"+strconv.Itoa(id)+"/members", nil, nil, "", &out)
+	return out, err
+}
+
+// ListWorkspaces lists the workspaces of the user, with GET /v1/workspaces.
+func (c *Client) ListWorkspaces(ctx context.Context) ([]UserWorkspace, error) {
+	var out []UserWorkspace
+	err := c.do(ctx, "GET", "/v1/workspaces", nil, nil, "", &out)
+	return out, err
+}
+
+// LogTime logs time on a task, with POST /v1/tasks/{id}/worklogs.
+func (c *Client) LogTime(ctx context.Context, id int, body Worklog) (Worklog, error) {
+	var out Worklog
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/worklogs", nil, body, "", &out)
+	return out, err
+}
+
+// Login logs in, with POST /v1/login.
+//
+// Returns a token for the workspace asked for, or the default workspace of the user.
+func (c *Client) Login(ctx context.Context, body LoginRequest) (TokenResponse, error) {
+	var out TokenResponse
+	err := c.do(ctx, "POST", "/v1/login", nil, body, "", &out)
+	return out, err
+}
+
+// Metrics gets the metrics of the service, with GET /metrics.
+//
+// The caller closes the body of the response.
+func (c *Client) Metrics(ctx context.Context) (*http.Response, error) {
+	return c.send(ctx, "GET", "/metrics", nil, nil, "")
+}
+
+// MoveBoardTask moves a task on the board, with POST /v1/projects/{id}/board/move.
+func (c *Client) MoveBoardTask(ctx context.Context, id int, body BoardMoveRequest) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/projects/"+strconv.Itoa(id)+"/board/move", nil, body, "", &out)
+	return out, err
+}
+
+// OpenAPI gets this document, with GET /openapi.json.
+//
+// The caller closes the body of the response.
+func (c *Client) OpenAPI(ctx context.Context) (*http.Response, error) {
+	return c.send(ctx, "GET", "/openapi.json", nil, nil, "")
+}
+
+// PromoteUser promotes a user to admin, with POST /v1/users/promote/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) PromoteUser(ctx context.Context, id int) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/v1/users/promote/"+strconv.Itoa(id), nil, nil, "", &out)
+	return out, err
+}
+
+// Readyz checks the service can serve requests, with GET /readyz.
+func (c *Client) Readyz(ctx context.Context) (HealthResponse, error) {
+	var out HealthResponse
+	err := c.do(ctx, "GET", "/readyz", nil, nil, "", &out)
+	return out, err
+}
+
+// RedeliverWebhook delivers an event again, with POST /v1/webhooks/{id}/deliveries/{delivery_id}/redeliver.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) RedeliverWebhook(ctx context.Context, id int, deliveryID int) (WebhookDelivery, error) {
+	var out WebhookDelivery
+	err := c.do(ctx, "POST", "/v1/webhooks/"+strconv.Itoa(id)+"/deliveries/"+strconv.Itoa(deliveryID)+"/redeliver", nil, nil, "", &out)
+	return out, err
+}
+
+// Register creates an account, with POST /v1/register.
+//
+// Every account gets a workspace of its own, which it is the admin of.
+func (c *Client) Register(ctx context.Context, body User) (MessageResponse, error) {
+	var out MessageResponse
+	err := c.do(ctx, "POST", "/v1/register", nil, body, "", &out)
+	return out, err
+}
+
+// RemoveDependency unblocks a task, with DELETE /v1/tasks/{id}/dependencies/{blocker_id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) RemoveDependency(ctx context.Context, id int, blockerID int) error {
+	return c.do(ctx, "DELETE", "/v1/tasks/"+strconv.Itoa(id)+"/dependencies/"+strconv.Itoa(blockerID), nil, nil, "", nil)
+}
+
+// RemoveProjectMember removes a member, with DELETE /v1/projects/{id}/members/{username}.
+func (c *Client) RemoveProjectMember(ctx context.Context, id int, username string) (Project, error) {
+	var out Project
+	err := c.do(ctx, "DELETE", "/v1/projects/"+strconv.Itoa(id)+"/members/"+url.PathEscape(username), nil, nil, "", &out)
+	return out, err
+}
+
+// RemoveWorkspaceMember removes a member, with DELETE /v1/workspaces/{id}/members/{username}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) RemoveWorkspaceMember(ctx context.Context, id int, username string) error {
+	return c.do(ctx, "DELETE", "/v1/workspaces/"+strconv.Itoa(id)+"/members/"+url.PathEscape(username), nil, nil, "", nil)
+}
+
+// RestoreTask restores a task from the trash, with POST /v1/tasks/{id}/restore.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) RestoreTask(ctx context.Context, id int) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/restore", nil, nil, "", &out)
+	return out, err
+}
+
+// RevertTask reverts a task to a revision, with POST /v1/tasks/{id}/revert/{rev}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) RevertTask(ctx context.Context, id int, rev int) (Task, error) {
+	var out Task
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/revert/"+strconv.Itoa(rev), nil, nil, "", &out)
+	return out, err
+}
+
+// SearchParams are the query parameters of Search.
+type SearchParams struct {
+	// Query
+	Q string
+	// Hits returned at most
+	Limit int
+}
+
+func (p *SearchParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Q != "" {
+		values.Set("q", p.Q)
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return values
+}
+
+// Search searches tasks and comments, with GET /v1/search.
+//
+// Words are matched in full text; status:, assignee:, label:, priority: and due: narrow the hits.
+func (c *Client) Search(ctx context.Context, params *SearchParams) ([]SearchHit, error) {
+	var out []SearchHit
+	err := c.do(ctx, "GET", "/v1/search", params.values(), nil, "", &out)
+	return out, err
+}
+
+// SetProjectMember adds a member or change their role, with PUT /v1/projects/{id}/members.
+func (c *Client) SetProjectMember(ctx context.Context, id int, body ProjectMember) (Project, error) {
+	var out Project
+	err := c.do(ctx, "PUT", "/v1/projects/"+strconv.Itoa(id)+"/members", nil, body, "", &out)
+	return out, err
+}
+
+// SetWorkspaceMember adds a member or change their role, with PUT /v1/workspaces/{id}/members.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) SetWorkspaceMember(ctx context.Context, id int, body Membership) (Membership, error) {
+	var out Membership
+	err := c.do(ctx, "PUT", "/v1/workspaces/"+strconv.Itoa(id)+"/members", nil, body, "", &out)
+	return out, err
+}
+
+// StartTimer starts a timer on a task, with POST /v1/tasks/{id}/timer/start.
+func (c *Client) StartTimer(ctx context.Context, id int) (Worklog, error) {
+	var out Worklog
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/timer/start", nil, nil, "", &out)
+	return out, err
+}
+
+// StopTimer stops the running timer of a task, with POST /v1/tasks/{id}/timer/stop.
+//
+// The body, and the note it holds, are optional.
+func (c *Client) StopTimer(ctx context.Context, id int, body *TimerStopRequest) (Worklog, error) {
+	var out Worklog
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/timer/stop", nil, body, "", &out)
+	return out, err
+}
+
+// StreamEventsParams are the query parameters of StreamEvents.
+type StreamEventsParams struct {
+	// Event the stream resumes after
+	LastEventID int
+}
+
+func (p *StreamEventsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.LastEventID != 0 {
+		values.Set("last_event_id", strconv.Itoa(p.LastEventID))
+	}
+	return values
+}
+
+// StreamEvents streams the events of the workspace, with GET /v1/events.
+//
+// A server-sent events stream of Event, resumed after the last_event_id parameter or the Last-Event-ID header.
+//
+// The caller closes the body of the response.
+func (c *Client) StreamEvents(ctx context.Context, params *StreamEventsParams) (*http.Response, error) {
+	return c.send(ctx, "GET", "/v1/events", params.values(), nil, "")
+}
+
+// SwitchWorkspace gets a token for another workspace, with POST /v1/workspaces/{id}/token.
+func (c *Client) SwitchWorkspace(ctx context.Context, id int) (TokenResponse, error) {
+	var out TokenResponse
+	err := c.do(ctx, "POST", "/v1/workspaces/"+strconv.Itoa(id)+"/token", nil, nil, "", &out)
+	return out, err
+}
+
+// UpdateComment edits a comment, with PUT /v1/tasks/{id}/comments/{comment_id}.
+//
+// Only the body of a comment can be edited, by its author.
+func (c *Client) UpdateComment(ctx context.Context, id int, commentID int, body Comment) (Comment, error) {
+	var out Comment
+	err := c.do(ctx, "PUT", "/v1/tasks/"+strconv.Itoa(id)+"/comments/"+strconv.Itoa(commentID), nil, body, "", &out)
+	return out, err
+}
+
+// UpdateLabel updates a label, with PUT /v1/labels/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) UpdateLabel(ctx context.Context, id int, body Label) (Label, error) {
+	var out Label
+	err := c.do(ctx, "PUT", "/v1/labels/"+strconv.Itoa(id), nil, body, "", &out)
+	return out, err
+}
+
+// UpdateProject updates a project, with PUT /v1/projects/{id}.
+func (c *Client) UpdateProject(ctx context.Context, id int, body Project) (Project, error) {
+	var out Project
+	err := c.do(ctx, "PUT", "/v1/projects/"+strconv.Itoa(id), nil, body, "", &out)
+	return out, err
+}
+
+// UpdateTaskParams are the query parameters of UpdateTask.
+type UpdateTaskParams struct {
+	// Occurrences of a recurring task updated
+	Scope string
+}
+
+func (p *UpdateTaskParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Scope != "" {
+		values.Set("scope", p.Scope)
+	}
+	return values
+}
+
+// UpdateTask updates a task, with PUT /v1/tasks/{id}.
+//
+// Returns null, or with scope=future the future occurrences of the recurring task, which the update applies to. Requires the admin role in the workspace of the token.
+func (c *Client) UpdateTask(ctx context.Context, id int, params *UpdateTaskParams, body Task) ([]Task, error) {
+	var out []Task
+	err := c.do(ctx, "PUT", "/v1/tasks/"+strconv.Itoa(id), params.values(), body, "", &out)
+	return out, err
+}
+
+// UpdateWebhook updates a webhook, with PUT /v1/webhooks/{id}.
+//
+// Requires the admin role in the workspace of the token.
+func (c *Client) UpdateWebhook(ctx context.Context, id int, body Webhook) (Webhook, error) {
+	var out Webhook
+	err := c.do(ctx, "PUT", "/v1/webhooks/"+strconv.Itoa(id), nil, body, "", &out)
+	return out, err
+}
+
+// UploadAttachment attaches a file to a task, with POST /v1/tasks/{id}/attachments.
+func (c *Client) UploadAttachment(ctx context.Context, id int, body io.Reader, contentType string) (Attachment, error) {
+	var out Attachment
+	err := c.do(ctx, "POST", "/v1/tasks/"+strconv.Itoa(id)+"/attachments", nil, body, contentType, &out)
+	return out, err
+}
@@ -0,0 +1,33 @@
+// Command gen generates the client of the API, into client_gen.go, from the
+// API's OpenAPI document or the one given by -spec. It is run by
+//
+//	go generate ./Delivery/client
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"task_manager/Delivery/openapi"
+)
+
+func main() {
+	spec := flag.String("spec", "", "OpenAPI document to generate the client from, instead of the API's")
+	out := flag.String("o", "client_gen.go", "file the client is written to")
+	flag.Parse()
+
+	document := openapi.JSON()
+	if *spec != "" {
+		var err error
+		if document, err = os.ReadFile(*spec); err != nil {
+			log.Fatal(err)
+		}
+	}
+	code, err := openapi.GenerateClient(document, "client")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
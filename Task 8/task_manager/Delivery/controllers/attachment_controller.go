@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var attachmentService Usecases.IAttachmentService
+
+func (t *Controller) UploadAttachment(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// The limit leaves room for the multipart headers and form fields.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, Usecases.AttachmentMaxSize+1<<20)
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": Usecases.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required in the file field"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := forRequest(c, attachmentService).Upload(taskID, Usecases.Upload{
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Content:     file,
+		SHA256:      c.PostForm("sha256"),
+	}, c.GetString("username"))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, Domain.ActionAttachmentAdded, "attachment", attachment.ID, nil, attachment)
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (t *Controller) GetAttachments(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	attachments, err := forRequest(c, attachmentService).GetAttachments(taskID)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment sends the content of an attachment. Range and
+// conditional requests are answered from the blob store without reading the
+// whole attachment.
+func (t *Controller) DownloadAttachment(c *gin.Context) {
+	taskID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	service := forRequest(c, attachmentService)
+	attachment, err := service.GetAttachment(taskID, attachmentID)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	blob, err := service.Open(attachment)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", `"`+attachment.SHA256+`"`)
+	if sum, err := hex.DecodeString(attachment.SHA256); err == nil {
+		c.Header("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+	}
+	http.ServeContent(c.Writer, c.Request, "", attachment.CreatedAt, blob)
+}
+
+func (t *Controller) DeleteAttachment(c *gin.Context) {
+	taskID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := forRequest(c, attachmentService).Delete(taskID, attachmentID, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, Domain.ActionAttachmentDeleted, "attachment", attachment.ID, attachment, nil)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func attachmentParams(c *gin.Context) (int, int, bool) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return 0, 0, false
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return 0, 0, false
+	}
+	return taskID, attachmentID, true
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, Usecases.ErrAttachmentType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, Usecases.ErrChecksumMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrNotUploader):
+		return http.StatusForbidden
+	case errors.Is(err, Usecases.ErrNoBlobStore):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, Usecases.ErrBlobStore):
+		return http.StatusBadGateway
+	default:
+		return http.StatusNotFound
+	}
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (t *Controller) GetAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := forRequest(c, auditService).GetEntries(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (t *Controller) ExportAuditLog(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	c.Status(http.StatusOK)
+	if err := forRequest(c, auditService).Export(filter, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// parseAuditFilter reads the audit query parameters: actor, action,
+// target_type, target_id, from and to (RFC 3339), skip and limit.
+func parseAuditFilter(c *gin.Context) (Domain.AuditFilter, error) {
+	filter := Domain.AuditFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+
+	var err error
+	if value := c.Query("target_id"); value != "" {
+		if filter.TargetID, err = strconv.Atoi(value); err != nil {
+			return filter, errInvalidParam("target_id")
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errInvalidParam("from")
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errInvalidParam("to")
+		}
+	}
+	if value := c.Query("skip"); value != "" {
+		if filter.Skip, err = strconv.Atoi(value); err != nil || filter.Skip < 0 {
+			return filter, errInvalidParam("skip")
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 0 {
+			return filter, errInvalidParam("limit")
+		}
+	}
+	return filter, nil
+}
+
+func errInvalidParam(name string) error {
+	return fmt.Errorf("invalid %s parameter", name)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var bulkAuditActions = map[string]string{
+	Domain.BulkCreate: Domain.ActionTaskCreated,
+	Domain.BulkUpdate: Domain.ActionTaskUpdated,
+	Domain.BulkDelete: Domain.ActionTaskDeleted,
+}
+
+func (t *Controller) BulkTasks(c *gin.Context) {
+	var request Domain.BulkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := forRequest(c, taskService).BulkTasks(request, c.GetString("username"))
+	switch {
+	case errors.Is(err, Usecases.ErrInvalidBulk):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, Usecases.ErrAtomicUnsupported):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, Usecases.ErrBulkFailed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "results": results})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, result := range results {
+		if result.Status == Domain.BulkSucceeded {
+			recordBulkAudit(c, result.Op, result.ID, result.Before, result.Task)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// recordBulkAudit records a change made by a bulk request or an import.
+func recordBulkAudit(c *gin.Context, op string, id int, before, task *Domain.Task) {
+	var beforeValue, afterValue interface{}
+	if before != nil {
+		beforeValue = *before
+	}
+	if op != Domain.BulkDelete && task != nil {
+		afterValue = *task
+	}
+	recordAudit(c, bulkAuditActions[op], "task", id, beforeValue, afterValue)
+}
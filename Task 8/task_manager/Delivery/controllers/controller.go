@@ -1,9 +1,14 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"task_manager/Delivery/graphql"
 	"task_manager/Domain"
+	"task_manager/Infrastructure"
 	"task_manager/Usecases"
 
 	"github.com/gin-gonic/gin"
@@ -18,20 +23,117 @@ type IController interface {
 	GetUsers(c *gin.Context)
 	CreateUser(c *gin.Context)
 	Promote(c *gin.Context)
+	GetAuditLog(c *gin.Context)
+	ExportAuditLog(c *gin.Context)
+	GetTaskHistory(c *gin.Context)
+	RevertTask(c *gin.Context)
+	GetTrash(c *gin.Context)
+	RestoreTask(c *gin.Context)
+	GetSubtasks(c *gin.Context)
+	CreateSubtask(c *gin.Context)
+	GetDependencies(c *gin.Context)
+	AddDependency(c *gin.Context)
+	RemoveDependency(c *gin.Context)
+	CreateProject(c *gin.Context)
+	GetProjects(c *gin.Context)
+	GetProject(c *gin.Context)
+	UpdateProject(c *gin.Context)
+	DeleteProject(c *gin.Context)
+	SetProjectMember(c *gin.Context)
+	RemoveProjectMember(c *gin.Context)
+	GetProjectTasks(c *gin.Context)
+	CreateProjectTask(c *gin.Context)
+	GetBoard(c *gin.Context)
+	MoveBoardTask(c *gin.Context)
+	GetTaskFacets(c *gin.Context)
+	GetLabels(c *gin.Context)
+	CreateLabel(c *gin.Context)
+	UpdateLabel(c *gin.Context)
+	DeleteLabel(c *gin.Context)
+	Search(c *gin.Context)
+	CreateComment(c *gin.Context)
+	GetComments(c *gin.Context)
+	UpdateComment(c *gin.Context)
+	DeleteComment(c *gin.Context)
+	GetActivity(c *gin.Context)
+	GetOccurrences(c *gin.Context)
+	GetReminders(c *gin.Context)
+	CreateWebhook(c *gin.Context)
+	GetWebhooks(c *gin.Context)
+	GetWebhook(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	GetWebhookDeliveries(c *gin.Context)
+	GetWebhookDelivery(c *gin.Context)
+	RedeliverWebhook(c *gin.Context)
+	StreamEvents(c *gin.Context)
+	StreamEventsWS(c *gin.Context)
+	BulkTasks(c *gin.Context)
+	ExportTasks(c *gin.Context)
+	ImportTasks(c *gin.Context)
+	UploadAttachment(c *gin.Context)
+	GetAttachments(c *gin.Context)
+	DownloadAttachment(c *gin.Context)
+	DeleteAttachment(c *gin.Context)
+	LogTime(c *gin.Context)
+	GetWorklogs(c *gin.Context)
+	DeleteWorklog(c *gin.Context)
+	StartTimer(c *gin.Context)
+	StopTimer(c *gin.Context)
+	GetTimeTotals(c *gin.Context)
+	GetTimeReport(c *gin.Context)
+	GetThroughputReport(c *gin.Context)
+	GetCycleTimeReport(c *gin.Context)
+	GetOverdueReport(c *gin.Context)
+	GetWorkloadReport(c *gin.Context)
+	GetWorkspaces(c *gin.Context)
+	CreateWorkspace(c *gin.Context)
+	SwitchWorkspace(c *gin.Context)
+	GetWorkspaceMembers(c *gin.Context)
+	SetWorkspaceMember(c *gin.Context)
+	RemoveWorkspaceMember(c *gin.Context)
+	Healthz(c *gin.Context)
+	Readyz(c *gin.Context)
+	GraphQL(c *gin.Context)
+	GraphQLWS(c *gin.Context)
 }
 
 type Controller struct{}
 
-func NewController() IController {
+// NewController returns the controller of the API, working with the instance
+// database dbName.
+func NewController(dbName string) IController {
+	taskService = Usecases.NewTaskService(dbName)
+	userService = Usecases.NewUserService(dbName)
+	auditService = Usecases.NewAuditService(dbName)
+	projectService = Usecases.NewProjectService(dbName)
+	labelService = Usecases.NewLabelService(dbName)
+	commentService = Usecases.NewCommentService(dbName)
+	attachmentService = Usecases.NewAttachmentService(dbName)
+	worklogService = Usecases.NewWorklogService(dbName)
+	reminderService = Usecases.NewReminderService(dbName)
+	reportService = Usecases.NewReportService(dbName)
+	searchService = Usecases.NewSearchService(dbName)
+	webhookService = Usecases.NewWebhookService(dbName)
+	eventService = Usecases.NewEventService(dbName)
+	workspaceService = Usecases.NewWorkspaceService(dbName)
+	graphqlSchema = graphql.NewSchema(graphql.Services{
+		Tasks:    taskService,
+		Users:    userService,
+		Comments: commentService,
+		Events:   eventService,
+		Audit:    recordGraphQLAudit,
+	})
 	return &Controller{}
 }
 
-var taskService Usecases.ITaskService = Usecases.NewTaskService("task_manager")
-var userService Usecases.IUserService = Usecases.NewUserService("task_manager")
+var taskService Usecases.ITaskService
+var userService Usecases.IUserService
+var auditService Usecases.IAuditService
 
 func (t *Controller) GetTasks(c *gin.Context) {
 
-	tasks := taskService.GetTasks()
+	tasks := forRequest(c, taskService).FindTasks(parseTaskFilter(c))
 
 	c.JSON(http.StatusOK, tasks)
 }
@@ -44,7 +146,7 @@ func (t *Controller) GetTaskByID(c *gin.Context) {
 		return
 	}
 
-	task, err := taskService.GetTaskByID(id)
+	task, err := forRequest(c, taskService).GetTaskByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -62,7 +164,12 @@ func (t *Controller) CreateTask(c *gin.Context) {
 		return
 	}
 
-	task, _ = taskService.CreateTask(task)
+	task, err := forRequest(c, taskService).CreateTask(task, c.GetString("username"))
+	if err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskCreated, "task", task.ID, nil, task)
 
 	c.JSON(http.StatusCreated, task)
 }
@@ -80,11 +187,25 @@ func (t *Controller) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	if err := taskService.UpdateTask(id, updatedTask); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	switch c.DefaultQuery("scope", "this") {
+	case "this":
+	case "future":
+		updateFutureOccurrences(c, id, updatedTask)
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be this or future"})
 		return
 	}
 
+	service := forRequest(c, taskService)
+	before, _ := service.GetTaskByID(id)
+	if err := service.UpdateTask(id, updatedTask, c.GetString("username")); err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	after, _ := service.GetTaskByID(id)
+	recordAudit(c, Domain.ActionTaskUpdated, "task", id, before, after)
+
 	c.JSON(http.StatusOK, nil)
 }
 
@@ -95,16 +216,19 @@ func (t *Controller) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := taskService.DeleteTask(id); err != nil {
+	service := forRequest(c, taskService)
+	before, _ := service.GetTaskByID(id)
+	if err := service.DeleteTask(id, c.GetString("username")); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(c, Domain.ActionTaskDeleted, "task", id, before, nil)
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
 func (t *Controller) GetUsers(c *gin.Context) {
-	users := userService.GetUsers()
+	users := forRequest(c, userService).GetUsers()
 	c.JSON(http.StatusOK, users)
 }
 
@@ -115,10 +239,17 @@ func (t *Controller) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if err := userService.CreateUser(user); err != nil {
+	if err := userService.WithContext(requestContext(c)).CreateUser(user); err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	created, _ := userService.WithContext(requestContext(c)).GetUserbyUsername(user.Username)
+	created.Password = ""
+	// The registration goes to the audit log of the user's own workspace.
+	if member, err := workspaceService.WithContext(requestContext(c)).GetDefaultMembership(user.Username); err == nil {
+		c.Set("workspace", member.WorkspaceID)
+	}
+	recordAudit(c, Domain.ActionUserRegistered, "user", created.ID, nil, created)
 
 	c.JSON(201, gin.H{"message": "User created successfully"})
 }
@@ -129,9 +260,69 @@ func (t *Controller) Promote(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid user ID"})
 		return
 	}
-	if err := userService.Promote(id); err != nil {
+	service := forRequest(c, userService)
+	before, _ := service.GetUserByID(id)
+	if err := service.Promote(id); err != nil {
 		c.JSON(401, gin.H{"error": err.Error()})
 		return
 	}
+	after, _ := service.GetUserByID(id)
+	recordAudit(c, Domain.ActionUserPromoted, "user", id, before, after)
 	c.JSON(200, gin.H{"message": "User promoted successfully"})
 }
+
+// workspaceOf returns the workspace the request's token is for, which the
+// handlers work in.
+func workspaceOf(c *gin.Context) int {
+	if id := c.GetInt("workspace"); id != 0 {
+		return id
+	}
+	return Domain.DefaultWorkspaceID
+}
+
+// scopedService is a service that works in one workspace, within the
+// context of a request.
+type scopedService[S any] interface {
+	In(workspaceID int) S
+	WithContext(ctx context.Context) S
+}
+
+// forRequest returns the service working in the workspace of the request c
+// handles, within its context.
+func forRequest[S scopedService[S]](c *gin.Context, service S) S {
+	return service.In(workspaceOf(c)).WithContext(requestContext(c))
+}
+
+// requestContext is the context of the request c handles, or the background
+// context when c was built without a request, as in tests.
+func requestContext(c *gin.Context) context.Context {
+	if c.Request == nil {
+		return context.Background()
+	}
+	return c.Request.Context()
+}
+
+// recordAudit appends an entry describing a successful mutation to the audit log.
+// The mutation stands even if the entry cannot be written, which is logged.
+func recordAudit(c *gin.Context, action, targetType string, targetID int, before, after interface{}) {
+	entry := Infrastructure.NewAuditEntry(c, action, targetType, targetID)
+	entry.Changes = Usecases.Diff(before, after)
+	if err := forRequest(c, auditService).Record(entry); err != nil {
+		slog.ErrorContext(requestContext(c), "audit entry not recorded", "action", action, "target_type", targetType, "target_id", targetID, "error", err)
+	}
+}
+
+// taskErrorStatus maps the errors of the task service to a status code. Errors
+// that are not rule violations are treated as a missing task.
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrInvalidTask):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrBlocked),
+		errors.Is(err, Usecases.ErrDependencyCycle),
+		errors.Is(err, Usecases.ErrParentCycle):
+		return http.StatusConflict
+	default:
+		return http.StatusNotFound
+	}
+}
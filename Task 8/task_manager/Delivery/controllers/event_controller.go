@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+var eventService Usecases.IEventService
+
+// eventHeartbeat is how often an idle event stream sends a comment, so that
+// proxies do not close it.
+const eventHeartbeat = 25 * time.Second
+
+// StreamEvents streams the task events the user may see as server-sent
+// events.
+func (t *Controller) StreamEvents(c *gin.Context) {
+	lastID, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	// The stream outlives the server's write timeout.
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Status(http.StatusOK)
+	io.WriteString(c.Writer, "retry: 3000\n\n")
+	c.Writer.Flush()
+
+	send := func(event Domain.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	ping := func() error {
+		if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	streamEvents(c, lastID, requestContext(c).Done(), send, ping)
+}
+
+// StreamEventsWS streams the task events the user may see over a WebSocket,
+// one JSON message per event. Messages from the client are ignored. Like
+// the GraphQL WebSocket, it is refused to the pages of origins not allowed.
+func (t *Controller) StreamEventsWS(c *gin.Context) {
+	lastID, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID"})
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error { return checkOrigin(r) },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			keepOpen(ws)
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(io.Discard, ws)
+				close(closed)
+			}()
+
+			send := func(event Domain.Event) error {
+				return websocket.JSON.Send(ws, event)
+			}
+			streamEvents(c, lastID, closed, send, nil)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// streamEvents sends the events published after lastID that the user may
+// see, then the new ones as they come, until the client goes away or falls
+// too far behind. ping, when given, is called when the stream is idle.
+func streamEvents(c *gin.Context, lastID int, done <-chan struct{}, send func(Domain.Event) error, ping func() error) {
+	service := forRequest(c, eventService)
+	missed, events, cancel := service.Subscribe(lastID)
+	defer cancel()
+
+	username, admin := c.GetString("username"), isAdmin(c)
+	for _, event := range missed {
+		if !service.CanSee(event, username, admin) {
+			continue
+		}
+		if err := send(event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !service.CanSee(event, username, admin) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if ping != nil {
+				if err := ping(); err != nil {
+					return
+				}
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// lastEventID reads the ID of the last event a client received, from the
+// Last-Event-ID header browsers send when they reconnect, or else from the
+// last_event_id query parameter. It is 0 for a new stream.
+func lastEventID(c *gin.Context) (int, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"task_manager/Delivery/graphql"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+var graphqlSchema *graphql.Schema
+
+// graphqlWSProtocol is the WebSocket subprotocol GraphQL operations are run
+// over, the one of the graphql-ws library.
+const graphqlWSProtocol = "graphql-transport-ws"
+
+type ginContextKey struct{}
+
+// graphqlContext returns the context the operations of a request run in,
+// for the user of its token.
+func graphqlContext(c *gin.Context) context.Context {
+	ctx := context.WithValue(requestContext(c), ginContextKey{}, c)
+	return graphql.WithViewer(ctx, graphql.Viewer{
+		Username:    c.GetString("username"),
+		Admin:       isAdmin(c),
+		WorkspaceID: workspaceOf(c),
+	})
+}
+
+// recordGraphQLAudit records the mutations of GraphQL operations as
+// recordAudit does the ones of the REST routes.
+func recordGraphQLAudit(ctx context.Context, action, targetType string, targetID int, before, after interface{}) {
+	if c, ok := ctx.Value(ginContextKey{}).(*gin.Context); ok {
+		recordAudit(c, action, targetType, targetID, before, after)
+	}
+}
+
+// GraphQL runs a query or a mutation. As with any GraphQL server, the
+// failures of the operation are reported in the errors of its response,
+// whose status is 200.
+func (t *Controller) GraphQL(c *gin.Context) {
+	var request graphql.Request
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, graphqlSchema.Exec(graphqlContext(c), request))
+}
+
+// graphqlWSMessage is a message of the graphql-transport-ws protocol.
+type graphqlWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// GraphQLWS runs operations, subscriptions above all, over a WebSocket
+// speaking the graphql-transport-ws protocol. The connection is
+// authenticated by its token, when it is opened, and refused to the pages
+// of origins not allowed.
+func (t *Controller) GraphQLWS(c *gin.Context) {
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if err := checkOrigin(r); err != nil {
+				return err
+			}
+			for _, protocol := range config.Protocol {
+				if protocol == graphqlWSProtocol {
+					config.Protocol = []string{protocol}
+					return nil
+				}
+			}
+			return fmt.Errorf("the %s subprotocol is required", graphqlWSProtocol)
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			keepOpen(ws)
+			serveGraphQLWS(graphqlContext(c), ws)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveGraphQLWS runs the operations the client subscribes to, sending their
+// responses as they come, until the client goes away. Operations run once
+// the client has initialised the connection; the ones it completes are
+// stopped.
+func serveGraphQLWS(ctx context.Context, ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sendMu sync.Mutex
+	send := func(message graphqlWSMessage) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		websocket.JSON.Send(ws, message)
+	}
+
+	var operationsMu sync.Mutex
+	operations := map[string]context.CancelFunc{}
+	initialised := false
+	for {
+		var message graphqlWSMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			return
+		}
+
+		switch message.Type {
+		case "connection_init":
+			initialised = true
+			send(graphqlWSMessage{Type: "connection_ack"})
+		case "ping":
+			send(graphqlWSMessage{Type: "pong"})
+		case "subscribe":
+			var request graphql.Request
+			if !initialised || json.Unmarshal(message.Payload, &request) != nil {
+				return
+			}
+			operationsMu.Lock()
+			if _, running := operations[message.ID]; running {
+				operationsMu.Unlock()
+				return
+			}
+			operationCtx, stop := context.WithCancel(ctx)
+			operations[message.ID] = stop
+			operationsMu.Unlock()
+
+			responses, err := graphqlSchema.Subscribe(operationCtx, request)
+			if err != nil {
+				operationsMu.Lock()
+				delete(operations, message.ID)
+				operationsMu.Unlock()
+				stop()
+				payload, _ := json.Marshal([]gin.H{{"message": err.Error()}})
+				send(graphqlWSMessage{ID: message.ID, Type: "error", Payload: payload})
+				continue
+			}
+			go func(id string) {
+				defer stop()
+				for response := range responses {
+					payload, err := json.Marshal(response)
+					if err != nil {
+						continue
+					}
+					send(graphqlWSMessage{ID: id, Type: "next", Payload: payload})
+				}
+				// Operations the client completed are not completed back, and
+				// are forgotten already.
+				if operationCtx.Err() == nil {
+					send(graphqlWSMessage{ID: id, Type: "complete"})
+					operationsMu.Lock()
+					delete(operations, id)
+					operationsMu.Unlock()
+				}
+			}(message.ID)
+		case "complete":
+			operationsMu.Lock()
+			if stop, ok := operations[message.ID]; ok {
+				stop()
+				delete(operations, message.ID)
+			}
+			operationsMu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz tells that the process is alive, without looking at the database,
+// so that a database outage does not get the server restarted.
+func (t *Controller) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz tells whether the server can serve requests, that is whether the
+// database answers in time.
+func (t *Controller) Readyz(c *gin.Context) {
+	if err := Usecases.CheckReadiness(requestContext(c)); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var labelService Usecases.ILabelService
+
+func (t *Controller) GetTaskFacets(c *gin.Context) {
+	facets, err := forRequest(c, taskService).FacetTasks(parseTaskFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, facets)
+}
+
+func (t *Controller) GetLabels(c *gin.Context) {
+	labels, err := forRequest(c, labelService).GetLabels()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+func (t *Controller) CreateLabel(c *gin.Context) {
+	var label Domain.Label
+	if err := c.ShouldBindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := forRequest(c, labelService).CreateLabel(label)
+	if err != nil {
+		c.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+func (t *Controller) UpdateLabel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid label ID"})
+		return
+	}
+
+	var label Domain.Label
+	if err := c.ShouldBindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err = forRequest(c, labelService).UpdateLabel(id, label)
+	if err != nil {
+		c.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+func (t *Controller) DeleteLabel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid label ID"})
+		return
+	}
+
+	if err := forRequest(c, labelService).DeleteLabel(id); err != nil {
+		c.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// parseTaskFilter reads the task listing filters. Each takes a comma
+// separated list: labels (tasks having all of them), any_labels (tasks having
+// at least one), priority, status and assignee.
+func parseTaskFilter(c *gin.Context) Domain.TaskFilter {
+	return Domain.TaskFilter{
+		Labels:     splitQuery(c, "labels"),
+		AnyLabels:  splitQuery(c, "any_labels"),
+		Priorities: splitQuery(c, "priority"),
+		Statuses:   splitQuery(c, "status"),
+		Assignees:  splitQuery(c, "assignee"),
+		Overdue:    c.Query("overdue") == "true",
+	}
+}
+
+func splitQuery(c *gin.Context, name string) []string {
+	values := []string{}
+	for _, value := range strings.Split(c.Query(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func labelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrInvalidLabel):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrLabelNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var projectService Usecases.IProjectService
+
+func (t *Controller) CreateProject(c *gin.Context) {
+	var project Domain.Project
+	if err := c.ShouldBindJSON(&project); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := forRequest(c, projectService).CreateProject(project, c.GetString("username"))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+func (t *Controller) GetProjects(c *gin.Context) {
+	projects, err := forRequest(c, projectService).GetProjects(c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+func (t *Controller) GetProject(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	project, err := forRequest(c, projectService).GetProject(id, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (t *Controller) UpdateProject(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	var project Domain.Project
+	if err := c.ShouldBindJSON(&project); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := forRequest(c, projectService).UpdateProject(id, project, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (t *Controller) DeleteProject(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	if err := forRequest(c, projectService).DeleteProject(id, c.GetString("username"), isAdmin(c)); err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (t *Controller) SetProjectMember(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	var member Domain.ProjectMember
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := forRequest(c, projectService).SetMember(id, member, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (t *Controller) RemoveProjectMember(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	project, err := forRequest(c, projectService).RemoveMember(id, c.Param("username"), c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (t *Controller) GetProjectTasks(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := forRequest(c, projectService).GetProject(id, c.GetString("username"), isAdmin(c)); err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, forRequest(c, taskService).GetTasksByProject(id))
+}
+
+func (t *Controller) CreateProjectTask(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	var task Domain.Task
+	if err := c.ShouldBindJSON(&task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := forRequest(c, projectService).CreateProjectTask(id, task, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskCreated, "task", task.ID, nil, task)
+
+	c.JSON(http.StatusCreated, task)
+}
+
+func (t *Controller) GetBoard(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	board, err := forRequest(c, projectService).GetBoard(id, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+func (t *Controller) MoveBoardTask(c *gin.Context) {
+	id, ok := projectID(c)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		TaskID   int    `json:"task_id" binding:"required"`
+		Status   string `json:"status" binding:"required"`
+		Position int    `json:"position"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, _ := forRequest(c, taskService).GetTaskByID(payload.TaskID)
+	task, err := forRequest(c, projectService).MoveTask(id, payload.TaskID, payload.Status, payload.Position, c.GetString("username"), isAdmin(c))
+	if err != nil {
+		c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskUpdated, "task", task.ID, before, task)
+
+	c.JSON(http.StatusOK, task)
+}
+
+func projectID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func isAdmin(c *gin.Context) bool {
+	return c.GetString("role") == Domain.WorkspaceRoleAdmin
+}
+
+func projectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrProjectForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, Usecases.ErrInvalidProject):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrProjectNotEmpty):
+		return http.StatusConflict
+	default:
+		return taskErrorStatus(err)
+	}
+}
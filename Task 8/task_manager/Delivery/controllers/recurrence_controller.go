@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (t *Controller) GetOccurrences(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	occurrences, err := forRequest(c, taskService).GetOccurrences(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, occurrences)
+}
+
+// updateFutureOccurrences handles PUT /tasks/:id?scope=future, which changes
+// a task and the occurrences following it in its series.
+func updateFutureOccurrences(c *gin.Context, id int, updatedTask Domain.Task) {
+	before := map[int]Domain.Task{}
+	service := forRequest(c, taskService)
+	occurrences, _ := service.GetOccurrences(id)
+	for _, occurrence := range occurrences {
+		before[occurrence.ID] = occurrence
+	}
+
+	tasks, err := service.UpdateFutureOccurrences(id, updatedTask, c.GetString("username"))
+	for _, task := range tasks {
+		recordAudit(c, Domain.ActionTaskUpdated, "task", task.ID, before[task.ID], task)
+	}
+	if err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var reminderService Usecases.IReminderService
+
+func (t *Controller) GetReminders(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	reminders, err := forRequest(c, reminderService).GetReminders(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
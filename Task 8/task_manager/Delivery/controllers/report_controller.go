@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var reportService Usecases.IReportService
+
+func (t *Controller) GetThroughputReport(c *gin.Context) {
+	filter, err := parseReportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	buckets, err := forRequest(c, reportService).Throughput(filter)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buckets)
+}
+
+func (t *Controller) GetCycleTimeReport(c *gin.Context) {
+	filter, err := parseReportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := forRequest(c, reportService).CycleTime(filter)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (t *Controller) GetOverdueReport(c *gin.Context) {
+	filter, err := parseReportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := forRequest(c, reportService).Overdue(filter)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (t *Controller) GetWorkloadReport(c *gin.Context) {
+	filter, err := parseReportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workloads, err := forRequest(c, reportService).Workload(filter)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workloads)
+}
+
+func parseReportFilter(c *gin.Context) (Domain.ReportFilter, error) {
+	filter := Domain.ReportFilter{Interval: c.Query("interval")}
+
+	var err error
+	if value := c.Query("project_id"); value != "" {
+		if filter.ProjectID, err = strconv.Atoi(value); err != nil {
+			return filter, errInvalidParam("project_id")
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = parseRangeBound(value, false); err != nil {
+			return filter, errInvalidParam("from")
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = parseRangeBound(value, true); err != nil {
+			return filter, errInvalidParam("to")
+		}
+	}
+	return filter, nil
+}
+
+func reportErrorStatus(err error) int {
+	if errors.Is(err, Usecases.ErrInvalidReport) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var searchService Usecases.ISearchService
+
+func (t *Controller) Search(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		return
+	}
+
+	hits, err := forRequest(c, searchService).Search(c.Query("q"), limit)
+	if err != nil {
+		if errors.Is(err, Usecases.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hits)
+}
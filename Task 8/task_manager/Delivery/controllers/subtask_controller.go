@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (t *Controller) GetSubtasks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	subtasks, progress, err := forRequest(c, taskService).GetSubtasks(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subtasks": subtasks, "progress": progress})
+}
+
+func (t *Controller) CreateSubtask(c *gin.Context) {
+	parentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var task Domain.Task
+	if err := c.ShouldBindJSON(&task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err = forRequest(c, taskService).CreateSubtask(parentID, task, c.GetString("username"))
+	if err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskCreated, "task", task.ID, nil, task)
+
+	c.JSON(http.StatusCreated, task)
+}
+
+func (t *Controller) GetDependencies(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	blockedBy, blocks, err := forRequest(c, taskService).GetDependencies(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocked_by": blockedBy, "blocks": blocks})
+}
+
+func (t *Controller) AddDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var payload struct {
+		BlockedBy int `json:"blocked_by" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	service := forRequest(c, taskService)
+	before, _ := service.GetTaskByID(id)
+	if err := service.AddDependency(id, payload.BlockedBy, c.GetString("username")); err != nil {
+		c.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	after, _ := service.GetTaskByID(id)
+	recordAudit(c, Domain.ActionTaskUpdated, "task", id, before, after)
+
+	c.JSON(http.StatusCreated, after)
+}
+
+func (t *Controller) RemoveDependency(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	blockerID, err := strconv.Atoi(c.Param("blocker_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blocking task ID"})
+		return
+	}
+
+	service := forRequest(c, taskService)
+	before, _ := service.GetTaskByID(id)
+	if err := service.RemoveDependency(id, blockerID, c.GetString("username")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	after, _ := service.GetTaskByID(id)
+	recordAudit(c, Domain.ActionTaskUpdated, "task", id, before, after)
+
+	c.JSON(http.StatusNoContent, nil)
+}
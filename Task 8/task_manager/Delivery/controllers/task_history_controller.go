@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (t *Controller) GetTaskHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	revisions, err := forRequest(c, taskService).GetHistory(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(revisions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no history for this task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (t *Controller) RevertTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+
+	service := forRequest(c, taskService)
+	before, _ := service.GetTaskByID(id)
+	task, err := service.RevertTask(id, revision, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskReverted, "task", id, before, task)
+
+	c.JSON(http.StatusOK, task)
+}
+
+func (t *Controller) GetTrash(c *gin.Context) {
+	tasks := forRequest(c, taskService).GetTrash()
+	c.JSON(http.StatusOK, tasks)
+}
+
+func (t *Controller) RestoreTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	task, err := forRequest(c, taskService).RestoreTask(id, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(c, Domain.ActionTaskRestored, "task", id, nil, task)
+
+	c.JSON(http.StatusOK, task)
+}
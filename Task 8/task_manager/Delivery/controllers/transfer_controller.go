@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importMaxBytes is the largest import accepted.
+const importMaxBytes = 10 << 20
+
+// exportTypes are the content type and file extension of each export format.
+var exportTypes = map[string][2]string{
+	Domain.FormatCSV:   {"text/csv; charset=utf-8", "csv"},
+	Domain.FormatJSONL: {"application/x-ndjson", "jsonl"},
+	Domain.FormatICS:   {"text/calendar; charset=utf-8", "ics"},
+}
+
+func (t *Controller) ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", Domain.FormatCSV)
+	exportType, ok := exportTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, jsonl or ics"})
+		return
+	}
+
+	c.Header("Content-Type", exportType[0])
+	c.Header("Content-Disposition", `attachment; filename="tasks.`+exportType[1]+`"`)
+	c.Status(http.StatusOK)
+	if err := forRequest(c, taskService).ExportTasks(parseTaskFilter(c), format, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+func (t *Controller) ImportTasks(c *gin.Context) {
+	options := Domain.ImportOptions{
+		Format:  c.Query("format"),
+		Mapping: map[string]string{},
+		DryRun:  c.Query("dry_run") == "true",
+		Atomic:  c.Query("atomic") == "true",
+	}
+	if options.Format == "" {
+		options.Format = importFormat(c.GetHeader("Content-Type"))
+	}
+	for _, pair := range c.QueryArray("map") {
+		source, field, found := strings.Cut(pair, ":")
+		if !found || source == "" || field == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "map must be given as column:field"})
+			return
+		}
+		options.Mapping[source] = field
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, importMaxBytes)
+	report, err := forRequest(c, taskService).ImportTasks(body, options, c.GetString("username"))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, Usecases.ErrUnknownFormat), errors.Is(err, Usecases.ErrInvalidImport):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, Usecases.ErrAtomicUnsupported):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, Usecases.ErrBulkFailed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "report": report})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !report.DryRun {
+		for _, row := range report.Rows {
+			if row.Status == Domain.BulkSucceeded {
+				recordBulkAudit(c, row.Op, row.TaskID, row.Before, row.Task)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importFormat guesses the format of an import from its content type.
+func importFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return Domain.FormatCSV
+	case "application/json", "application/x-ndjson", "application/jsonl":
+		return Domain.FormatJSON
+	default:
+		return ""
+	}
+}
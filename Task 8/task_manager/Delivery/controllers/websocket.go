@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"task_manager/Infrastructure"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// checkOrigin refuses WebSockets opened by the pages of origins that are not
+// allowed. Connections are authenticated by their token, so clients outside
+// browsers, which send no origin, need not send one.
+func checkOrigin(r *http.Request) error {
+	if !Infrastructure.OriginAllowed(r) {
+		return fmt.Errorf("origin %s is not allowed", r.Header.Get("Origin"))
+	}
+	return nil
+}
+
+// keepOpen clears the deadlines of a WebSocket. The server's read and write
+// timeouts are for requests, while a WebSocket stays open until the client
+// leaves.
+func keepOpen(ws *websocket.Conn) {
+	ws.SetDeadline(time.Time{})
+}
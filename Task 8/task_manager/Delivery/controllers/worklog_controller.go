@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var worklogService Usecases.IWorklogService
+
+func (t *Controller) LogTime(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var worklog Domain.Worklog
+	if err := c.ShouldBindJSON(&worklog); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	worklog, err = forRequest(c, worklogService).LogTime(taskID, worklog, c.GetString("username"))
+	if err != nil {
+		c.JSON(worklogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, worklog)
+}
+
+func (t *Controller) GetWorklogs(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	filter, err := parseWorklogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.TaskIDs = []int{taskID}
+
+	worklogs, err := forRequest(c, worklogService).GetWorklogs(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, worklogs)
+}
+
+func (t *Controller) DeleteWorklog(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	worklogID, err := strconv.Atoi(c.Param("worklog_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worklog ID"})
+		return
+	}
+
+	if _, err := forRequest(c, worklogService).DeleteWorklog(taskID, worklogID, c.GetString("username"), isAdmin(c)); err != nil {
+		c.JSON(worklogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (t *Controller) StartTimer(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	worklog, err := forRequest(c, worklogService).StartTimer(taskID, c.GetString("username"))
+	if err != nil {
+		c.JSON(worklogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, worklog)
+}
+
+func (t *Controller) StopTimer(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	// The note is optional, and so is the body holding it.
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	worklog, err := forRequest(c, worklogService).StopTimer(taskID, payload.Note, c.GetString("username"))
+	if err != nil {
+		c.JSON(worklogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, worklog)
+}
+
+func (t *Controller) GetTimeTotals(c *gin.Context) {
+	filter, err := parseWorklogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if value := c.Query("task_id"); value != "" {
+		taskID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("task_id").Error()})
+			return
+		}
+		filter.TaskIDs = []int{taskID}
+	}
+	groupBy := splitQuery(c, "group_by")
+	if len(groupBy) == 0 {
+		groupBy = []string{Domain.GroupByTask}
+	}
+
+	totals, err := forRequest(c, worklogService).GetTotals(filter, groupBy)
+	if err != nil {
+		c.JSON(worklogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, totals)
+}
+
+func (t *Controller) GetTimeReport(c *gin.Context) {
+	filter, err := parseWorklogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	projectID := 0
+	if value := c.Query("project_id"); value != "" {
+		if projectID, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("project_id").Error()})
+			return
+		}
+	}
+
+	report, err := forRequest(c, worklogService).GetTimeReport(projectID, parseTaskFilter(c), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseWorklogFilter reads the user and date range of a worklog query. The
+// range bounds are RFC 3339 times or dates; a date given as the end of the
+// range includes that whole day.
+func parseWorklogFilter(c *gin.Context) (Domain.WorklogFilter, error) {
+	filter := Domain.WorklogFilter{User: c.Query("user")}
+
+	if value := c.Query("from"); value != "" {
+		from, err := parseRangeBound(value, false)
+		if err != nil {
+			return filter, errInvalidParam("from")
+		}
+		filter.From = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parseRangeBound(value, true)
+		if err != nil {
+			return filter, errInvalidParam("to")
+		}
+		filter.To = to
+	}
+	return filter, nil
+}
+
+func parseRangeBound(value string, end bool) (time.Time, error) {
+	if bound, err := time.Parse(time.RFC3339, value); err == nil {
+		return bound, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return day, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+func worklogErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrInvalidWorklog):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrTimerRunning):
+		return http.StatusConflict
+	case errors.Is(err, Usecases.ErrNotWorklogOwner):
+		return http.StatusForbidden
+	default:
+		return http.StatusNotFound
+	}
+}
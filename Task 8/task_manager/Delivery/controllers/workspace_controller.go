@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+var workspaceService Usecases.IWorkspaceService
+
+func (t *Controller) GetWorkspaces(c *gin.Context) {
+	workspaces, err := workspaceService.WithContext(requestContext(c)).GetWorkspaces(c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+func (t *Controller) CreateWorkspace(c *gin.Context) {
+	var workspace Domain.Workspace
+	if err := c.ShouldBindJSON(&workspace); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := workspaceService.WithContext(requestContext(c)).CreateWorkspace(workspace.Name, c.GetString("username"))
+	if err != nil {
+		c.JSON(workspaceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// SwitchWorkspace returns a token for another workspace the user is a member
+// of.
+func (t *Controller) SwitchWorkspace(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	member, err := workspaceService.WithContext(requestContext(c)).GetMembership(id, c.GetString("username"))
+	if err != nil {
+		c.JSON(workspaceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := Infrastructure.GenerateToken(member.Username, member.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "workspace_id": member.WorkspaceID})
+}
+
+func (t *Controller) GetWorkspaceMembers(c *gin.Context) {
+	id, ok := workspaceID(c)
+	if !ok {
+		return
+	}
+
+	members, err := workspaceService.In(id).WithContext(requestContext(c)).GetMembers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (t *Controller) SetWorkspaceMember(c *gin.Context) {
+	id, ok := workspaceID(c)
+	if !ok {
+		return
+	}
+
+	var member Domain.Membership
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := workspaceService.In(id).WithContext(requestContext(c)).SetMember(member)
+	if err != nil {
+		c.JSON(workspaceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (t *Controller) RemoveWorkspaceMember(c *gin.Context) {
+	id, ok := workspaceID(c)
+	if !ok {
+		return
+	}
+
+	if err := workspaceService.In(id).WithContext(requestContext(c)).RemoveMember(c.Param("username")); err != nil {
+		c.JSON(workspaceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// workspaceID reads the workspace of the URL, which must be the one the token
+// is for: the members of the other workspaces are not found.
+func workspaceID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return 0, false
+	}
+	if id != workspaceOf(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": Usecases.ErrWorkspaceNotFound.Error()})
+		return 0, false
+	}
+	return id, true
+}
+
+func workspaceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, Usecases.ErrInvalidWorkspace):
+		return http.StatusBadRequest
+	case errors.Is(err, Usecases.ErrLastAdmin):
+		return http.StatusConflict
+	case errors.Is(err, Usecases.ErrNotMember), errors.Is(err, Usecases.ErrWorkspaceNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,213 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+	"text/scanner"
+
+	"github.com/graph-gophers/graphql-go/types"
+)
+
+// complexity estimates how many fields the operation of a request resolves:
+// one per field, with the fields selected on the items of a list counted
+// once per item the list may return, its first or last argument, or
+// DefaultListSize for the lists without either. Requests that do not parse
+// or name no operation of theirs count 0; running them reports why.
+func (s *Schema) complexity(request Request) int {
+	doc, err := parseQuery(request.Query)
+	if err != nil {
+		return 0
+	}
+	var operation *types.OperationDefinition
+	if request.OperationName != "" {
+		operation = doc.Operations.Get(request.OperationName)
+	} else if len(doc.Operations) == 1 {
+		operation = doc.Operations[0]
+	}
+	if operation == nil {
+		return 0
+	}
+
+	variables := map[string]interface{}{}
+	for _, definition := range operation.Vars {
+		if definition.Default != nil {
+			variables[definition.Name.Name] = literalValue(definition.Default, nil)
+		}
+	}
+	for name, value := range request.Variables {
+		variables[name] = value
+	}
+
+	schema := s.schema.ASTSchema()
+	root := map[types.OperationType]string{operationQuery: "query", operationMutation: "mutation", operationSubscription: "subscription"}[operation.Type]
+	entryPoint, ok := schema.EntryPoints[root]
+	if !ok {
+		return 0
+	}
+	c := &costing{schema: schema, doc: doc, variables: variables, spreading: map[string]bool{}}
+	return c.selectionSet(entryPoint.TypeName(), operation.Selections)
+}
+
+type costing struct {
+	schema    *types.Schema
+	doc       *types.ExecutableDefinition
+	variables map[string]interface{}
+	// spreading are the fragments being counted, so that cycles, which
+	// validation refuses, do not loop.
+	spreading map[string]bool
+}
+
+func (c *costing) selectionSet(typeName string, selections types.SelectionSet) int {
+	total := 0
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *types.Field:
+			total = add(total, c.field(typeName, selection))
+		case *types.InlineFragment:
+			on := selection.On.Name
+			if on == "" {
+				on = typeName
+			}
+			total = add(total, c.selectionSet(on, selection.Selections))
+		case *types.FragmentSpread:
+			fragment := c.doc.Fragments.Get(selection.Name.Name)
+			if fragment == nil || c.spreading[fragment.Name.Name] {
+				continue
+			}
+			c.spreading[fragment.Name.Name] = true
+			total = add(total, c.selectionSet(fragment.On.Name, fragment.Selections))
+			delete(c.spreading, fragment.Name.Name)
+		}
+	}
+	return total
+}
+
+// field counts a field and the fields selected on its value. Fields the
+// schema does not define, such as __typename and the introspection ones,
+// count 1.
+func (c *costing) field(typeName string, field *types.Field) int {
+	definition := c.fieldDefinition(typeName, field.Name.Name)
+	if definition == nil {
+		return 1
+	}
+	named, list := namedType(definition.Type)
+	children := c.selectionSet(named, field.SelectionSet)
+	if list {
+		children = multiply(children, c.listSize(definition, field))
+	}
+	return add(1, children)
+}
+
+func (c *costing) fieldDefinition(typeName, name string) *types.FieldDefinition {
+	switch definition := c.schema.Types[typeName].(type) {
+	case *types.ObjectTypeDefinition:
+		return definition.Fields.Get(name)
+	case *types.InterfaceTypeDefinition:
+		return definition.Fields.Get(name)
+	}
+	return nil
+}
+
+// namedType returns the name of the type of a field's value, and whether
+// the value is a list.
+func namedType(t types.Type) (string, bool) {
+	list := false
+	for {
+		switch wrapped := t.(type) {
+		case *types.NonNull:
+			t = wrapped.OfType
+		case *types.List:
+			list = true
+			t = wrapped.OfType
+		case types.NamedType:
+			return wrapped.TypeName(), list
+		default:
+			return "", list
+		}
+	}
+}
+
+// listSize is how many items a list field may return.
+func (c *costing) listSize(definition *types.FieldDefinition, field *types.Field) int {
+	for _, name := range []string{"first", "last"} {
+		if argument, ok := field.Arguments.Get(name); ok {
+			if size, ok := toInt(literalValue(argument, c.variables)); ok {
+				return size
+			}
+		}
+		if argument := definition.Arguments.Get(name); argument != nil && argument.Default != nil {
+			if size, ok := toInt(literalValue(argument.Default, nil)); ok {
+				return size
+			}
+		}
+	}
+	return DefaultListSize
+}
+
+// literalValue reads a value of the query: a variable, or an integer, which
+// graph-gophers would refuse past 32 bits but is kept whole here. Other
+// values do not size lists and read as nil.
+func literalValue(value types.Value, variables map[string]interface{}) interface{} {
+	switch value := value.(type) {
+	case *types.Variable:
+		return variables[value.Name]
+	case *types.PrimitiveValue:
+		if value.Type != scanner.Int {
+			return nil
+		}
+		n, err := strconv.ParseInt(value.Text, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return n
+	}
+	return nil
+}
+
+// toInt reads an integer argument, given in the query or in the JSON
+// variables. Negative sizes, which resolvers refuse, count 0.
+func toInt(value interface{}) (int, bool) {
+	var size int64
+	switch value := value.(type) {
+	case int32:
+		size = int64(value)
+	case int64:
+		size = value
+	case float64:
+		size = int64(value)
+	case json.Number:
+		n, err := value.Int64()
+		if err != nil {
+			return 0, false
+		}
+		size = n
+	default:
+		return 0, false
+	}
+	if size < 0 {
+		return 0, true
+	}
+	if size > int64(MaxComplexity) {
+		return MaxComplexity + 1, true
+	}
+	return int(size), true
+}
+
+// add and multiply saturate past MaxComplexity, so that huge sizes do not
+// overflow.
+func add(a, b int) int {
+	if a+b > MaxComplexity {
+		return MaxComplexity + 1
+	}
+	return a + b
+}
+
+func multiply(a, b int) int {
+	if a != 0 && b > MaxComplexity/a {
+		return MaxComplexity + 1
+	}
+	if a*b > MaxComplexity {
+		return MaxComplexity + 1
+	}
+	return a * b
+}
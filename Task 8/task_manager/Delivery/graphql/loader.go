@@ -0,0 +1,166 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+)
+
+// loader loads values by key in batches, so that resolving a field of every
+// item of a list calls the services once rather than once per item. The keys
+// queued before a load are fetched along with it, in one call to fetch, and
+// every key is fetched at most once per loader.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	queued  []K
+	batches map[K]*batch[K, V]
+}
+
+// batch is a call to fetch, which the loads of its keys wait for.
+type batch[K comparable, V any] struct {
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, batches: map[K]*batch[K, V]{}}
+}
+
+// queue adds keys to the next batch, unless they are loaded already.
+func (l *loader[K, V]) queue(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := l.batches[key]; !ok {
+			l.batches[key] = nil
+			l.queued = append(l.queued, key)
+		}
+	}
+}
+
+// load returns the value of key, fetching it along with the keys queued if
+// it is not loaded yet. It reports false for keys fetch returned no value
+// for.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	b := l.batches[key]
+	if b == nil {
+		if _, queued := l.batches[key]; !queued {
+			l.queued = append(l.queued, key)
+		}
+		b = &batch[K, V]{done: make(chan struct{})}
+		keys := l.queued
+		for _, queued := range keys {
+			l.batches[queued] = b
+		}
+		l.queued = nil
+		l.mu.Unlock()
+
+		b.values, b.err = l.fetch(keys)
+		close(b.done)
+	} else {
+		l.mu.Unlock()
+	}
+
+	var value V
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+	if b.err != nil {
+		return value, false, b.err
+	}
+	value, ok := b.values[key]
+	return value, ok, nil
+}
+
+// loaders are the loaders of an operation, or of an event of a
+// subscription, so that values are not cached past it.
+type loaders struct {
+	ctx      context.Context
+	services Services
+	viewer   Viewer
+
+	users *loader[string, Domain.User]
+
+	mu sync.Mutex
+	// tasks are the IDs of the tasks resolved so far, which the comment
+	// loaders queue.
+	tasks    []int
+	comments map[int]*loader[int, []Domain.Comment]
+}
+
+type loadersKey struct{}
+
+func newLoaders(ctx context.Context, services Services) *loaders {
+	l := &loaders{ctx: ctx, services: services, viewer: viewerOf(ctx), comments: map[int]*loader[int, []Domain.Comment]{}}
+	l.users = newLoader(func(usernames []string) (map[string]Domain.User, error) {
+		users, err := services.Users.In(l.viewer.WorkspaceID).WithContext(ctx).GetUsersByUsernames(usernames)
+		if err != nil {
+			return nil, err
+		}
+		byUsername := map[string]Domain.User{}
+		for _, user := range users {
+			byUsername[user.Username] = user
+		}
+		return byUsername, nil
+	})
+	return l
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersOf(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// queueTasks queues the assignees and comments of tasks about to be
+// resolved, so that they are loaded together.
+func (l *loaders) queueTasks(tasks []Domain.Task) {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+		if task.Assignee != "" {
+			l.users.queue(task.Assignee)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tasks = append(l.tasks, ids...)
+	for _, comments := range l.comments {
+		comments.queue(ids...)
+	}
+}
+
+// latestComments returns the loader of the last comments of tasks, limit of
+// them per task.
+func (l *loaders) latestComments(limit int) *loader[int, []Domain.Comment] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if comments, ok := l.comments[limit]; ok {
+		return comments
+	}
+
+	comments := newLoader(func(taskIDs []int) (map[int][]Domain.Comment, error) {
+		latest, err := l.services.Comments.In(l.viewer.WorkspaceID).WithContext(l.ctx).GetLatestComments(taskIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, comments := range latest {
+			for _, comment := range comments {
+				l.users.queue(comment.Author)
+			}
+		}
+		return latest, nil
+	})
+	comments.queue(l.tasks...)
+	l.comments[limit] = comments
+	return comments
+}
@@ -0,0 +1,285 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"text/scanner"
+
+	"github.com/graph-gophers/graphql-go/types"
+)
+
+// Operation types, as graph-gophers names them.
+const (
+	operationQuery        types.OperationType = "QUERY"
+	operationMutation     types.OperationType = "MUTATION"
+	operationSubscription types.OperationType = "SUBSCRIPTION"
+)
+
+// parseQuery parses a query into graph-gophers' AST, which graph-gophers
+// only builds for itself when it runs the query. It reads the same grammar
+// with the same scanner, so that the complexity of an operation is
+// estimated on the document graph-gophers runs.
+func parseQuery(query string) (doc *types.ExecutableDefinition, err error) {
+	p := &queryParser{}
+	p.scanner.Init(strings.NewReader(query))
+	p.scanner.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings
+	p.scanner.Error = func(_ *scanner.Scanner, message string) { p.fail(message) }
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			syntax, ok := recovered.(querySyntaxError)
+			if !ok {
+				panic(recovered)
+			}
+			doc, err = nil, syntax
+		}
+	}()
+	return p.document(), nil
+}
+
+type querySyntaxError string
+
+func (e querySyntaxError) Error() string { return "syntax error: " + string(e) }
+
+type queryParser struct {
+	scanner scanner.Scanner
+	next    rune
+}
+
+func (p *queryParser) fail(message string) {
+	panic(querySyntaxError(message))
+}
+
+// skip moves to the next token, past commas and comments, which GraphQL
+// ignores.
+func (p *queryParser) skip() {
+	for {
+		p.next = p.scanner.Scan()
+		switch p.next {
+		case ',':
+			continue
+		case '#':
+			for next := p.scanner.Next(); next != '\r' && next != '\n' && next != scanner.EOF; next = p.scanner.Next() {
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (p *queryParser) consume(expected rune) {
+	if p.next != expected {
+		p.fail(fmt.Sprintf("unexpected %q, expecting %s", p.scanner.TokenText(), scanner.TokenString(expected)))
+	}
+	p.skip()
+}
+
+func (p *queryParser) ident() types.Ident {
+	name := p.scanner.TokenText()
+	p.consume(scanner.Ident)
+	return types.Ident{Name: name}
+}
+
+func (p *queryParser) document() *types.ExecutableDefinition {
+	doc := &types.ExecutableDefinition{}
+	p.skip()
+	for p.next != scanner.EOF {
+		if p.next == '{' {
+			doc.Operations = append(doc.Operations, &types.OperationDefinition{Type: operationQuery, Selections: p.selectionSet()})
+			continue
+		}
+		switch keyword := p.ident().Name; keyword {
+		case "query":
+			doc.Operations = append(doc.Operations, p.operation(operationQuery))
+		case "mutation":
+			doc.Operations = append(doc.Operations, p.operation(operationMutation))
+		case "subscription":
+			doc.Operations = append(doc.Operations, p.operation(operationSubscription))
+		case "fragment":
+			doc.Fragments = append(doc.Fragments, p.fragment())
+		default:
+			p.fail(fmt.Sprintf(`unexpected %q, expecting "fragment"`, keyword))
+		}
+	}
+	return doc
+}
+
+func (p *queryParser) operation(operationType types.OperationType) *types.OperationDefinition {
+	operation := &types.OperationDefinition{Type: operationType}
+	if p.next == scanner.Ident {
+		operation.Name = p.ident()
+	}
+	operation.Directives = p.directives()
+	if p.next == '(' {
+		p.consume('(')
+		for p.next != ')' {
+			p.consume('$')
+			variable := &types.InputValueDefinition{Name: p.ident()}
+			p.consume(':')
+			variable.Type = p.typeRef()
+			if p.next == '=' {
+				p.consume('=')
+				variable.Default = p.value(true)
+			}
+			variable.Directives = p.directives()
+			operation.Vars = append(operation.Vars, variable)
+		}
+		p.consume(')')
+	}
+	operation.Selections = p.selectionSet()
+	return operation
+}
+
+func (p *queryParser) fragment() *types.FragmentDefinition {
+	fragment := &types.FragmentDefinition{Name: p.ident()}
+	if on := p.ident(); on.Name != "on" {
+		p.fail(fmt.Sprintf(`unexpected %q, expecting "on"`, on.Name))
+	}
+	fragment.On = types.TypeName{Ident: p.ident()}
+	fragment.Directives = p.directives()
+	fragment.Selections = p.selectionSet()
+	return fragment
+}
+
+func (p *queryParser) selectionSet() types.SelectionSet {
+	var selections types.SelectionSet
+	p.consume('{')
+	for p.next != '}' {
+		if p.next == '.' {
+			selections = append(selections, p.spread())
+		} else {
+			selections = append(selections, p.field())
+		}
+	}
+	p.consume('}')
+	return selections
+}
+
+func (p *queryParser) field() *types.Field {
+	field := &types.Field{Alias: p.ident()}
+	field.Name = field.Alias
+	if p.next == ':' {
+		p.consume(':')
+		field.Name = p.ident()
+	}
+	if p.next == '(' {
+		field.Arguments = p.arguments()
+	}
+	field.Directives = p.directives()
+	if p.next == '{' {
+		field.SelectionSet = p.selectionSet()
+	}
+	return field
+}
+
+func (p *queryParser) spread() types.Selection {
+	p.consume('.')
+	p.consume('.')
+	p.consume('.')
+
+	fragment := &types.InlineFragment{}
+	if p.next == scanner.Ident {
+		name := p.ident()
+		if name.Name != "on" {
+			return &types.FragmentSpread{Name: name, Directives: p.directives()}
+		}
+		fragment.On = types.TypeName{Ident: p.ident()}
+	}
+	fragment.Directives = p.directives()
+	fragment.Selections = p.selectionSet()
+	return fragment
+}
+
+func (p *queryParser) directives() types.DirectiveList {
+	var directives types.DirectiveList
+	for p.next == '@' {
+		p.consume('@')
+		directive := &types.Directive{Name: p.ident()}
+		if p.next == '(' {
+			directive.Arguments = p.arguments()
+		}
+		directives = append(directives, directive)
+	}
+	return directives
+}
+
+func (p *queryParser) arguments() types.ArgumentList {
+	var arguments types.ArgumentList
+	p.consume('(')
+	for p.next != ')' {
+		argument := &types.Argument{Name: p.ident()}
+		p.consume(':')
+		argument.Value = p.value(false)
+		argument.Directives = p.directives()
+		arguments = append(arguments, argument)
+	}
+	p.consume(')')
+	return arguments
+}
+
+func (p *queryParser) typeRef() types.Type {
+	var t types.Type
+	if p.next == '[' {
+		p.consume('[')
+		t = &types.List{OfType: p.typeRef()}
+		p.consume(']')
+	} else {
+		t = &types.TypeName{Ident: p.ident()}
+	}
+	if p.next == '!' {
+		p.consume('!')
+		return &types.NonNull{OfType: t}
+	}
+	return t
+}
+
+// value parses a value; constant ones, such as the defaults of variables,
+// may not refer to variables.
+func (p *queryParser) value(constant bool) types.Value {
+	switch p.next {
+	case '$':
+		if constant {
+			p.fail("variable not allowed")
+		}
+		p.consume('$')
+		return &types.Variable{Name: p.ident().Name}
+	case scanner.Int, scanner.Float, scanner.String, scanner.Ident:
+		literal := p.literal()
+		if literal.Type == scanner.Ident && literal.Text == "null" {
+			return &types.NullValue{}
+		}
+		return literal
+	case '-':
+		p.consume('-')
+		literal := p.literal()
+		literal.Text = "-" + literal.Text
+		return literal
+	case '[':
+		p.consume('[')
+		list := &types.ListValue{}
+		for p.next != ']' {
+			list.Values = append(list.Values, p.value(constant))
+		}
+		p.consume(']')
+		return list
+	case '{':
+		p.consume('{')
+		object := &types.ObjectValue{}
+		for p.next != '}' {
+			name := p.ident()
+			p.consume(':')
+			object.Fields = append(object.Fields, &types.ObjectField{Name: name, Value: p.value(constant)})
+		}
+		p.consume('}')
+		return object
+	default:
+		p.fail("invalid value")
+		return nil
+	}
+}
+
+func (p *queryParser) literal() *types.PrimitiveValue {
+	literal := &types.PrimitiveValue{Type: p.next, Text: p.scanner.TokenText()}
+	p.skip()
+	return literal
+}
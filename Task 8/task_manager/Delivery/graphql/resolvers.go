@@ -0,0 +1,281 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+)
+
+// errAdminRequired is returned by the mutations that need the admin role of
+// the workspace.
+var errAdminRequired = errors.New("Requires the admin role in the workspace")
+
+// resolver resolves the fields of Query, Mutation and Subscription.
+type resolver struct {
+	services Services
+}
+
+func (r *resolver) tasks(ctx context.Context) Usecases.ITaskService {
+	return r.services.Tasks.In(viewerOf(ctx).WorkspaceID).WithContext(ctx)
+}
+
+func (r *resolver) users(ctx context.Context) Usecases.IUserService {
+	return r.services.Users.In(viewerOf(ctx).WorkspaceID).WithContext(ctx)
+}
+
+func (r *resolver) Task(ctx context.Context, args struct{ ID int32 }) *taskResolver {
+	task, err := r.tasks(ctx).GetTaskByID(int(args.ID))
+	if err != nil {
+		return nil
+	}
+	return newTaskResolvers(loadersOf(ctx), []Domain.Task{task})[0]
+}
+
+type taskFilterInput struct {
+	Labels     *[]string
+	AnyLabels  *[]string
+	Priorities *[]string
+	Statuses   *[]string
+	Assignees  *[]string
+	Overdue    *bool
+}
+
+func (f *taskFilterInput) filter() Domain.TaskFilter {
+	filter := Domain.TaskFilter{}
+	if f == nil {
+		return filter
+	}
+	values := func(list *[]string) []string {
+		if list == nil {
+			return nil
+		}
+		return *list
+	}
+	filter.Labels = values(f.Labels)
+	filter.AnyLabels = values(f.AnyLabels)
+	filter.Priorities = values(f.Priorities)
+	filter.Statuses = values(f.Statuses)
+	filter.Assignees = values(f.Assignees)
+	filter.Overdue = f.Overdue != nil && *f.Overdue
+	return filter
+}
+
+func (r *resolver) Tasks(ctx context.Context, args struct {
+	Filter *taskFilterInput
+	First  int32
+}) ([]*taskResolver, error) {
+	first := int(args.First)
+	if first < 0 {
+		return nil, errors.New("first must not be negative")
+	}
+	tasks := r.tasks(ctx).FindTasks(args.Filter.filter())
+	if first < len(tasks) {
+		tasks = tasks[:first]
+	}
+	return newTaskResolvers(loadersOf(ctx), tasks), nil
+}
+
+func (r *resolver) Users(ctx context.Context) []*userResolver {
+	resolvers := []*userResolver{}
+	for _, user := range r.users(ctx).GetUsers() {
+		resolvers = append(resolvers, &userResolver{user})
+	}
+	return resolvers
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ Username string }) (*userResolver, error) {
+	return loadersOf(ctx).user(ctx, args.Username)
+}
+
+func (r *resolver) Me(ctx context.Context) (*userResolver, error) {
+	return loadersOf(ctx).user(ctx, viewerOf(ctx).Username)
+}
+
+// taskInput holds the fields of a task a mutation sets; the ones left null
+// are not changed.
+type taskInput struct {
+	Title           *string
+	Description     *string
+	DueDate         *string
+	Status          *string
+	Priority        *string
+	Labels          *[]string
+	Assignee        *string
+	ParentID        *int32
+	ProjectID       *int32
+	StoryPoints     *float64
+	EstimateMinutes *int32
+	Recurrence      *string
+}
+
+// apply returns task with the fields of the input set.
+func (in taskInput) apply(task Domain.Task) Domain.Task {
+	setString := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	setInt := func(field *int, value *int32) {
+		if value != nil {
+			*field = int(*value)
+		}
+	}
+	setString(&task.Title, in.Title)
+	setString(&task.Description, in.Description)
+	setString(&task.DueDate, in.DueDate)
+	setString(&task.Status, in.Status)
+	setString(&task.Priority, in.Priority)
+	setString(&task.Assignee, in.Assignee)
+	setString(&task.Recurrence, in.Recurrence)
+	setInt(&task.ParentID, in.ParentID)
+	setInt(&task.ProjectID, in.ProjectID)
+	setInt(&task.Estimate, in.EstimateMinutes)
+	if in.Labels != nil {
+		task.Labels = *in.Labels
+	}
+	if in.StoryPoints != nil {
+		task.StoryPoints = *in.StoryPoints
+	}
+	return task
+}
+
+func (r *resolver) CreateTask(ctx context.Context, args struct{ Input taskInput }) (*taskResolver, error) {
+	viewer := viewerOf(ctx)
+	if !viewer.Admin {
+		return nil, errAdminRequired
+	}
+	task, err := r.tasks(ctx).CreateTask(args.Input.apply(Domain.Task{}), viewer.Username)
+	if err != nil {
+		return nil, err
+	}
+	r.services.Audit(ctx, Domain.ActionTaskCreated, "task", task.ID, nil, task)
+	return newTaskResolvers(loadersOf(ctx), []Domain.Task{task})[0], nil
+}
+
+func (r *resolver) UpdateTask(ctx context.Context, args struct {
+	ID    int32
+	Input taskInput
+}) (*taskResolver, error) {
+	viewer := viewerOf(ctx)
+	if !viewer.Admin {
+		return nil, errAdminRequired
+	}
+	id := int(args.ID)
+	before, err := r.tasks(ctx).GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.tasks(ctx).UpdateTask(id, args.Input.apply(before), viewer.Username); err != nil {
+		return nil, err
+	}
+	after, err := r.tasks(ctx).GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.services.Audit(ctx, Domain.ActionTaskUpdated, "task", id, before, after)
+	return newTaskResolvers(loadersOf(ctx), []Domain.Task{after})[0], nil
+}
+
+func (r *resolver) DeleteTask(ctx context.Context, args struct{ ID int32 }) (bool, error) {
+	viewer := viewerOf(ctx)
+	if !viewer.Admin {
+		return false, errAdminRequired
+	}
+	id := int(args.ID)
+	before, err := r.tasks(ctx).GetTaskByID(id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.tasks(ctx).DeleteTask(id, viewer.Username); err != nil {
+		return false, err
+	}
+	r.services.Audit(ctx, Domain.ActionTaskDeleted, "task", id, before, nil)
+	return true, nil
+}
+
+func (r *resolver) AddComment(ctx context.Context, args struct {
+	TaskID   int32
+	Body     string
+	ParentID *int32
+}) (*commentResolver, error) {
+	viewer := viewerOf(ctx)
+	comment := Domain.Comment{Body: args.Body}
+	if args.ParentID != nil {
+		comment.ParentID = int(*args.ParentID)
+	}
+	comment, err := r.services.Comments.In(viewer.WorkspaceID).WithContext(ctx).CreateComment(int(args.TaskID), comment, viewer.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &commentResolver{loadersOf(ctx), comment}, nil
+}
+
+func (r *resolver) PromoteUser(ctx context.Context, args struct{ ID int32 }) (*userResolver, error) {
+	if !viewerOf(ctx).Admin {
+		return nil, errAdminRequired
+	}
+	id := int(args.ID)
+	before, _ := r.users(ctx).GetUserByID(id)
+	if err := r.users(ctx).Promote(id); err != nil {
+		return nil, err
+	}
+	after, err := r.users(ctx).GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.services.Audit(ctx, Domain.ActionUserPromoted, "user", id, before, after)
+	after.Role = Domain.WorkspaceRoleAdmin
+	return &userResolver{after}, nil
+}
+
+// TaskChanged sends the events about the tasks the viewer can see, or about
+// the task of the given ID, until ctx is done or the viewer falls too far
+// behind.
+func (r *resolver) TaskChanged(ctx context.Context, args struct{ ID *int32 }) (<-chan *taskEventResolver, error) {
+	viewer := viewerOf(ctx)
+	service := r.services.Events.In(viewer.WorkspaceID).WithContext(ctx)
+	_, events, cancel := service.Subscribe(0)
+
+	changes := make(chan *taskEventResolver)
+	go func() {
+		defer close(changes)
+		defer cancel()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if args.ID != nil && event.TaskID != int(*args.ID) {
+					continue
+				}
+				task, ok := eventTask(event)
+				if !ok || !service.CanSee(event, viewer.Username, viewer.Admin) {
+					continue
+				}
+				// Each event loads the assignees and comments it is sent with
+				// anew.
+				change := &taskEventResolver{event, newTaskResolvers(newLoaders(ctx, r.services), []Domain.Task{task})[0]}
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// eventTask returns the task a task event carries.
+func eventTask(event Domain.Event) (Domain.Task, bool) {
+	data, ok := event.Data.(map[string]interface{})
+	if !ok {
+		return Domain.Task{}, false
+	}
+	task, ok := data["task"].(Domain.Task)
+	return task, ok
+}
@@ -0,0 +1,118 @@
+// Package graphql serves the tasks, users and comments of a workspace over
+// GraphQL, for clients that want nested data, such as a task with its
+// assignee and latest comments, in one request. The schema is in
+// schema.graphql; its resolvers work with the same services as the REST
+// controllers.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"task_manager/Usecases"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/trace/otel"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// MaxDepth is how deeply the fields of an operation may nest.
+var MaxDepth = 8
+
+// MaxComplexity is the most fields an operation may resolve, as estimated by
+// complexity before it runs.
+var MaxComplexity = 5000
+
+// DefaultListSize is how many items the complexity of an operation counts
+// for the lists that do not say how many they return.
+var DefaultListSize = 20
+
+// Services are what the resolvers work with. Audit records a mutation in the
+// audit log of the workspace, as the REST controllers do.
+type Services struct {
+	Tasks    Usecases.ITaskService
+	Users    Usecases.IUserService
+	Comments Usecases.ICommentService
+	Events   Usecases.IEventService
+	Audit    func(ctx context.Context, action, targetType string, targetID int, before, after interface{})
+}
+
+// Viewer is the user an operation runs for, in the workspace of their token.
+type Viewer struct {
+	Username    string
+	Admin       bool
+	WorkspaceID int
+}
+
+type viewerKey struct{}
+
+// WithViewer returns ctx for operations run for viewer.
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+func viewerOf(ctx context.Context) Viewer {
+	viewer, _ := ctx.Value(viewerKey{}).(Viewer)
+	return viewer
+}
+
+// Request is a GraphQL request, as posted in JSON.
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Schema runs operations against the schema of schema.graphql.
+type Schema struct {
+	services Services
+	schema   *graphqlgo.Schema
+}
+
+// NewSchema returns the schema, resolved with services. It reads MaxDepth
+// when called.
+func NewSchema(services Services) *Schema {
+	return &Schema{
+		services: services,
+		schema: graphqlgo.MustParseSchema(schemaSource, &resolver{services: services},
+			graphqlgo.UseStringDescriptions(),
+			graphqlgo.MaxDepth(MaxDepth),
+			graphqlgo.Tracer(otel.DefaultTracer()),
+		),
+	}
+}
+
+// Exec runs a query or a mutation for the viewer of ctx. Operations over
+// MaxComplexity are refused before they run.
+func (s *Schema) Exec(ctx context.Context, request Request) *graphqlgo.Response {
+	if err := s.checkComplexity(request); err != nil {
+		return &graphqlgo.Response{Errors: []*gqlerrors.QueryError{err}}
+	}
+	ctx = withLoaders(ctx, newLoaders(ctx, s.services))
+	return s.schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+}
+
+// Subscribe runs an operation for the viewer of ctx and returns the channel
+// its responses are sent to, which is closed once ctx is done. A
+// subscription sends a response per event; queries and mutations send their
+// only response.
+func (s *Schema) Subscribe(ctx context.Context, request Request) (<-chan interface{}, error) {
+	if err := s.checkComplexity(request); err != nil {
+		responses := make(chan interface{}, 1)
+		responses <- &graphqlgo.Response{Errors: []*gqlerrors.QueryError{err}}
+		close(responses)
+		return responses, nil
+	}
+	ctx = withLoaders(ctx, newLoaders(ctx, s.services))
+	return s.schema.Subscribe(ctx, request.Query, request.OperationName, request.Variables)
+}
+
+func (s *Schema) checkComplexity(request Request) *gqlerrors.QueryError {
+	complexity := s.complexity(request)
+	if complexity > MaxComplexity {
+		return gqlerrors.Errorf("operation has complexity %d, over the limit of %d", complexity, MaxComplexity)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+	"time"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+)
+
+func toTime(t *time.Time) *graphqlgo.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphqlgo.Time{Time: *t}
+}
+
+// optionalID is null for the IDs a task leaves at 0, such as the parent of
+// a task without one.
+func optionalID(id int) *int32 {
+	if id == 0 {
+		return nil
+	}
+	value := int32(id)
+	return &value
+}
+
+type taskResolver struct {
+	loaders *loaders
+	task    Domain.Task
+}
+
+// newTaskResolvers returns the resolvers of tasks, queueing their assignees
+// and comments to be loaded together.
+func newTaskResolvers(l *loaders, tasks []Domain.Task) []*taskResolver {
+	l.queueTasks(tasks)
+	resolvers := make([]*taskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		resolvers = append(resolvers, &taskResolver{l, task})
+	}
+	return resolvers
+}
+
+func (t *taskResolver) ID() int32                    { return int32(t.task.ID) }
+func (t *taskResolver) Title() string                { return t.task.Title }
+func (t *taskResolver) Description() string          { return t.task.Description }
+func (t *taskResolver) DueDate() string              { return t.task.DueDate }
+func (t *taskResolver) Status() string               { return t.task.Status }
+func (t *taskResolver) Priority() string             { return t.task.Priority }
+func (t *taskResolver) AssigneeName() string         { return t.task.Assignee }
+func (t *taskResolver) ParentID() *int32             { return optionalID(t.task.ParentID) }
+func (t *taskResolver) ProjectID() *int32            { return optionalID(t.task.ProjectID) }
+func (t *taskResolver) StoryPoints() float64         { return t.task.StoryPoints }
+func (t *taskResolver) EstimateMinutes() int32       { return int32(t.task.Estimate) }
+func (t *taskResolver) Recurrence() string           { return t.task.Recurrence }
+func (t *taskResolver) Overdue() bool                { return t.task.Overdue }
+func (t *taskResolver) CreatedAt() *graphqlgo.Time   { return toTime(t.task.CreatedAt) }
+func (t *taskResolver) StartedAt() *graphqlgo.Time   { return toTime(t.task.StartedAt) }
+func (t *taskResolver) CompletedAt() *graphqlgo.Time { return toTime(t.task.CompletedAt) }
+
+func (t *taskResolver) Labels() []string {
+	if t.task.Labels == nil {
+		return []string{}
+	}
+	return t.task.Labels
+}
+
+func (t *taskResolver) BlockedBy() []int32 {
+	ids := make([]int32, 0, len(t.task.BlockedBy))
+	for _, id := range t.task.BlockedBy {
+		ids = append(ids, int32(id))
+	}
+	return ids
+}
+
+// Assignee is null when the task is not assigned, or assigned to someone who
+// is not a member of the workspace.
+func (t *taskResolver) Assignee(ctx context.Context) (*userResolver, error) {
+	if t.task.Assignee == "" {
+		return nil, nil
+	}
+	return t.loaders.user(ctx, t.task.Assignee)
+}
+
+func (t *taskResolver) Comments(ctx context.Context, args struct{ Last int32 }) ([]*commentResolver, error) {
+	last := int(args.Last)
+	if last < 0 {
+		return nil, errors.New("last must not be negative")
+	}
+	resolvers := []*commentResolver{}
+	if last == 0 {
+		return resolvers, nil
+	}
+	comments, _, err := t.loaders.latestComments(last).load(ctx, t.task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		resolvers = append(resolvers, &commentResolver{t.loaders, comment})
+	}
+	return resolvers, nil
+}
+
+type userResolver struct {
+	user Domain.User
+}
+
+// user returns the resolver of the member of the given username, or nil if
+// there is no such member.
+func (l *loaders) user(ctx context.Context, username string) (*userResolver, error) {
+	user, ok, err := l.users.load(ctx, username)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+func (u *userResolver) ID() int32        { return int32(u.user.ID) }
+func (u *userResolver) Username() string { return u.user.Username }
+func (u *userResolver) Email() string    { return u.user.Email }
+func (u *userResolver) Role() string     { return u.user.Role }
+
+type commentResolver struct {
+	loaders *loaders
+	comment Domain.Comment
+}
+
+func (c *commentResolver) ID() int32          { return int32(c.comment.ID) }
+func (c *commentResolver) TaskID() int32      { return int32(c.comment.TaskID) }
+func (c *commentResolver) ParentID() *int32   { return optionalID(c.comment.ParentID) }
+func (c *commentResolver) AuthorName() string { return c.comment.Author }
+func (c *commentResolver) Body() string       { return c.comment.Body }
+func (c *commentResolver) Deleted() bool      { return c.comment.Deleted }
+func (c *commentResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: c.comment.CreatedAt}
+}
+func (c *commentResolver) UpdatedAt() *graphqlgo.Time { return toTime(c.comment.UpdatedAt) }
+
+func (c *commentResolver) Mentions() []string {
+	if c.comment.Mentions == nil {
+		return []string{}
+	}
+	return c.comment.Mentions
+}
+
+func (c *commentResolver) Author(ctx context.Context) (*userResolver, error) {
+	return c.loaders.user(ctx, c.comment.Author)
+}
+
+type taskEventResolver struct {
+	event Domain.Event
+	task  *taskResolver
+}
+
+func (e *taskEventResolver) ID() int32    { return int32(e.event.ID) }
+func (e *taskEventResolver) Type() string { return e.event.Type }
+func (e *taskEventResolver) Timestamp() graphqlgo.Time {
+	return graphqlgo.Time{Time: e.event.Timestamp}
+}
+func (e *taskEventResolver) Actor() string       { return e.event.Actor }
+func (e *taskEventResolver) Task() *taskResolver { return e.task }
@@ -1,10 +1,212 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"task_manager/Delivery/routers"
+	"task_manager/Infrastructure"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+	"time"
 )
 
 func main() {
-	r := routers.SetupRouter()
-	r.Run("localhost:8080")
+	config, printConfig, err := Infrastructure.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := Infrastructure.WriteConfig(os.Stdout, config.Redacted()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	slog.SetDefault(Infrastructure.NewLogger(os.Stdout, config.Log.SlogLevel()))
+	if config.Auth.JWTSecret == Infrastructure.DefaultJWTSecret {
+		slog.Warn("auth.jwt_secret is the built-in default; set JWT_SECRET so that tokens cannot be forged")
+	}
+
+	// The first SIGINT or SIGTERM shuts the service down gracefully; a second
+	// one kills it.
+	stop, stopped := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopped()
+
+	if err := Repositories.Connect(config.Mongo.URI); err != nil {
+		fatal("database client not created", err)
+	}
+	connectCtx, cancel := context.WithTimeout(stop, config.Mongo.ConnectTimeout.Duration)
+	err = Repositories.WaitForServer(connectCtx)
+	cancel()
+	if stop.Err() != nil {
+		return
+	}
+	if err != nil {
+		fatal("database not reachable", err, "waited", config.Mongo.ConnectTimeout.String())
+	}
+	dbName := config.Mongo.Database
+	Repositories.UseOperationObserver(Infrastructure.ObserveDBOperation)
+
+	flushTraces := func(context.Context) error { return nil }
+	if config.Tracing.Endpoint != "" {
+		flushTraces, err = Infrastructure.SetupTracing(stop, config.Tracing.Endpoint, config.Tracing.ServiceName, config.Tracing.SampleRatio)
+		if err != nil {
+			fatal("tracing not set up", err)
+		}
+	}
+
+	Infrastructure.UseJWTSecret(config.Auth.JWTSecret)
+	Usecases.PasswordCost = config.Auth.BcryptCost
+	Usecases.TrashRetention = config.Tasks.TrashRetention.Duration
+	Usecases.RecurrenceInterval = config.Tasks.RecurrenceInterval.Duration
+	Usecases.ReminderInterval = config.Reminders.Interval.Duration
+	Usecases.ReminderLeadTimes = []time.Duration{}
+	for _, lead := range config.Reminders.LeadTimes {
+		Usecases.ReminderLeadTimes = append(Usecases.ReminderLeadTimes, lead.Duration)
+	}
+	Usecases.AttachmentMaxSize = config.Attachments.MaxBytes
+	if config.Search.Backend == "memory" {
+		Usecases.UseMemorySearch()
+	}
+	if config.Reports.Backend == "memory" {
+		Usecases.UseMemoryReports()
+	}
+	Usecases.UseNotifier(newNotifier(config.Notifier))
+	Usecases.UseBlobStore(newBlobStore(config.Attachments))
+	if config.RateLimit.Enabled {
+		Infrastructure.UseRateLimits(config.RateLimit.Policies, newRateLimitStore(config.RateLimit.Store, dbName))
+	}
+
+	workspaces := Usecases.NewWorkspaceService(dbName)
+	if err := workspaces.Migrate(); err != nil {
+		slog.Error("workspaces not migrated", "error", err)
+	}
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workers := []<-chan struct{}{
+		Usecases.StartRecurrenceScheduler(workerCtx, Usecases.NewTaskService(dbName), workspaces),
+		Usecases.StartReminderScheduler(workerCtx, Usecases.NewReminderService(dbName), workspaces),
+		Usecases.StartWebhookWorker(workerCtx, Usecases.NewWebhookService(dbName), workspaces),
+	}
+
+	routers.Legacy = routers.LegacyRoutes{
+		Enabled:    config.API.LegacyRoutes,
+		Deprecated: config.API.LegacyDeprecated.Time,
+		Sunset:     config.API.LegacySunset.Time,
+	}
+	Infrastructure.UseAllowedOrigins(config.Server.AllowedOrigins)
+	router := routers.SetupRouter(dbName)
+	if err := router.SetTrustedProxies(config.Server.TrustedProxies); err != nil {
+		fatal("trusted proxies not set", err)
+	}
+	server := &http.Server{
+		Addr:         config.Server.Addr,
+		Handler:      router,
+		ReadTimeout:  config.Server.ReadTimeout.Duration,
+		WriteTimeout: config.Server.WriteTimeout.Duration,
+		IdleTimeout:  config.Server.IdleTimeout.Duration,
+	}
+	// Shutdown does not wait for the event streams, which never go idle:
+	// closing the bus ends them.
+	server.RegisterOnShutdown(Usecases.Events.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	slog.Info("listening", "addr", config.Server.Addr)
+
+	select {
+	case err = <-serveErr:
+		slog.Error("server stopped", "error", err)
+	case <-stop.Done():
+		stopped()
+		slog.Info("shutting down")
+	}
+	shutdown(server, stopWorkers, workers, flushTraces, config.Server.ShutdownTimeout.Duration)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// shutdown stops the service in order, within timeout: the server stops
+// accepting connections and lets the requests in progress finish, then the
+// background workers finish their current run, then the database client is
+// closed and the spans left are exported.
+func shutdown(server *http.Server, stopWorkers context.CancelFunc, workers []<-chan struct{}, flushTraces func(context.Context) error, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("requests left unfinished", "error", err)
+	}
+	stopWorkers()
+	for _, done := range workers {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+	if err := Repositories.Disconnect(ctx); err != nil {
+		slog.Error("database client not closed", "error", err)
+	}
+	if err := flushTraces(ctx); err != nil {
+		slog.Error("spans not exported", "error", err)
+	}
+}
+
+// fatal logs the error that keeps the service from starting, and exits.
+func fatal(msg string, err error, args ...interface{}) {
+	slog.Error(msg, append([]interface{}{"error", err}, args...)...)
+	os.Exit(1)
+}
+
+// newNotifier returns the notifier selected by notifier.type: log (the
+// default), webhook, smtp or mailbox.
+func newNotifier(config Infrastructure.NotifierConfig) Usecases.INotifier {
+	switch config.Type {
+	case "webhook":
+		return Infrastructure.NewWebhookNotifier(config.WebhookURL)
+	case "smtp":
+		return Infrastructure.NewSMTPNotifier(config.SMTP.Addr, config.SMTP.From, config.SMTP.Username, config.SMTP.Password)
+	case "mailbox":
+		return &Infrastructure.MailboxNotifier{Dir: config.MailboxDir, From: config.SMTP.From}
+	default:
+		return &Infrastructure.LogNotifier{}
+	}
+}
+
+// newBlobStore returns the store selected by attachments.store: local (the
+// default), which keeps attachments in attachments.dir, or s3.
+func newBlobStore(config Infrastructure.AttachmentConfig) Usecases.IBlobStore {
+	switch config.Store {
+	case "s3":
+		s3 := config.S3
+		store := Infrastructure.NewS3BlobStore(s3.Endpoint, s3.Bucket, s3.Region, s3.AccessKey, s3.SecretKey)
+		store.PathStyle = s3.PathStyle
+		return store
+	default:
+		return &Infrastructure.LocalBlobStore{Dir: config.Dir}
+	}
+}
+
+// newRateLimitStore returns the store selected by rate_limit.store: memory
+// (the default), or mongo, shared by the instances using the database.
+func newRateLimitStore(store, dbName string) Infrastructure.RateLimitStore {
+	switch store {
+	case "mongo":
+		return Repositories.NewRateLimitRepository(dbName)
+	default:
+		return Infrastructure.NewMemoryRateLimitStore()
+	}
 }
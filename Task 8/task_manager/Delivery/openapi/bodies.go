@@ -0,0 +1,89 @@
+package openapi
+
+import "task_manager/Domain"
+
+// The bodies below are the ones the handlers read and write without a
+// Domain type of their own, named here so that the document and the client
+// can refer to them.
+
+// ErrorResponse is the body of every failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type LoginRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	WorkspaceID int    `json:"workspace_id"`
+}
+
+type TokenResponse struct {
+	Message     string `json:"message,omitempty"`
+	Token       string `json:"token"`
+	WorkspaceID int    `json:"workspace_id"`
+}
+
+type BulkResponse struct {
+	Results []Domain.BulkResult `json:"results"`
+}
+
+type BulkFailure struct {
+	Error   string              `json:"error"`
+	Results []Domain.BulkResult `json:"results"`
+}
+
+type ImportFailure struct {
+	Error  string              `json:"error"`
+	Report Domain.ImportReport `json:"report"`
+}
+
+type SubtasksResponse struct {
+	Subtasks []Domain.Task   `json:"subtasks"`
+	Progress Domain.Progress `json:"progress"`
+}
+
+type DependenciesResponse struct {
+	BlockedBy []Domain.Task `json:"blocked_by"`
+	Blocks    []Domain.Task `json:"blocks"`
+}
+
+type DependencyRequest struct {
+	BlockedBy int `json:"blocked_by" binding:"required"`
+}
+
+type BoardMoveRequest struct {
+	TaskID   int    `json:"task_id" binding:"required"`
+	Status   string `json:"status" binding:"required"`
+	Position int    `json:"position"`
+}
+
+type TimerStopRequest struct {
+	Note string `json:"note"`
+}
+
+// GraphQLRequest is a GraphQL operation.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is the outcome of a GraphQL operation.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
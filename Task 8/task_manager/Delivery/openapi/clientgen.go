@@ -0,0 +1,354 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateClient generates the Go code of a client of the API described by
+// spec, in package pkg: a type per schema of the document and a method of
+// Client per operation. The methods rely on the Client, Error, do and send
+// of the package, which are written by hand.
+//
+// Operations responding with JSON return its decoded value, the ones
+// responding with files return the *http.Response for the caller to read and
+// close, and the ones answering with no 2xx status, such as WebSocket
+// upgrades, are left out.
+func GenerateClient(spec []byte, pkg string) ([]byte, error) {
+	var doc Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+	g := &generator{doc: &doc, imports: map[string]bool{}}
+
+	var body bytes.Buffer
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.writeType(&body, name, doc.Components.Schemas[name])
+	}
+	routes := doc.Routes()
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Operation.OperationID < routes[j].Operation.OperationID })
+	for _, route := range routes {
+		g.writeOperation(&body, route)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by go run ./gen from the OpenAPI document of the API. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	out.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+type generator struct {
+	doc     *Document
+	imports map[string]bool
+}
+
+func (g *generator) writeType(w *bytes.Buffer, name string, schema *Schema) {
+	if schema.Description != "" {
+		fmt.Fprintf(w, "// %s is %s\n", name, lowerFirst(schema.Description))
+	}
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	properties := make([]string, 0, len(schema.Properties))
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+	for _, property := range properties {
+		tag := property
+		if !contains(schema.Required, property) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(w, "\t%s %s `json:%q`\n", exportedName(property), g.fieldType(schema.Properties[property]), tag)
+	}
+	w.WriteString("}\n\n")
+}
+
+// fieldType is the Go type of the fields holding values of schema. Times
+// and structs are pointers, so that the ones not set are left out of request
+// bodies.
+func (g *generator) fieldType(schema *Schema) string {
+	if schema.Ref != "" || schema.Format == "date-time" {
+		return "*" + g.goType(schema)
+	}
+	return g.goType(schema)
+}
+
+func (g *generator) goType(schema *Schema) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if schema.Ref != "" {
+		return RefName(schema.Ref)
+	}
+	switch schemaType(schema) {
+	case "boolean":
+		return "bool"
+	case "integer":
+		if schema.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "string":
+		if schema.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "array":
+		return "[]" + g.goType(schema.Items)
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// schemaType is the type of the values of schema other than null.
+func schemaType(schema *Schema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, name := range t {
+			if name != "null" {
+				return name.(string)
+			}
+		}
+	}
+	return ""
+}
+
+func (g *generator) writeOperation(w *bytes.Buffer, route Route) {
+	operation := route.Operation
+	name := exportedName(operation.OperationID)
+
+	response := successResponse(operation)
+	if response == nil {
+		return
+	}
+	var result string
+	raw := false
+	if media, ok := response.Content["application/json"]; ok && media.Schema != nil && media.Schema.Format != "binary" {
+		result = g.goType(media.Schema)
+	} else if len(response.Content) > 0 {
+		raw = true
+		g.imports["net/http"] = true
+	}
+
+	args := []string{"ctx context.Context"}
+	g.imports["context"] = true
+	path := strconv.Quote(route.Path)
+	var queries []Parameter
+	for _, parameter := range operation.Parameters {
+		switch parameter.In {
+		case "path":
+			arg := unexportedName(parameter.Name)
+			value := arg
+			if schemaType(parameter.Schema) == "integer" {
+				args = append(args, arg+" int")
+				value = "strconv.Itoa(" + arg + ")"
+				g.imports["strconv"] = true
+			} else {
+				args = append(args, arg+" string")
+				value = "url.PathEscape(" + arg + ")"
+				g.imports["net/url"] = true
+			}
+			path = strings.Replace(path, ":"+parameter.Name, `"+`+value+`+"`, 1)
+		case "query":
+			queries = append(queries, parameter)
+		}
+	}
+	path = strings.TrimSuffix(path, `+""`)
+
+	query := "nil"
+	if len(queries) > 0 {
+		g.writeParams(w, name, queries)
+		args = append(args, "params *"+name+"Params")
+		query = "params.values()"
+		g.imports["net/url"] = true
+	}
+
+	in := "nil"
+	contentType := `""`
+	if body := operation.RequestBody; body != nil {
+		if media, ok := body.Content["application/json"]; ok {
+			bodyType := g.goType(media.Schema)
+			if !body.Required {
+				bodyType = "*" + bodyType
+			}
+			args = append(args, "body "+bodyType)
+			in = "body"
+		} else {
+			args = append(args, "body io.Reader", "contentType string")
+			in = "body"
+			contentType = "contentType"
+			g.imports["io"] = true
+		}
+	}
+
+	fmt.Fprintf(w, "// %s %s, with %s %s.\n", name, thirdPerson(operation.Summary), route.Method, openAPIPath(route.Path))
+	if operation.Description != "" {
+		fmt.Fprintf(w, "//\n// %s\n", operation.Description)
+	}
+	signature := fmt.Sprintf("func (c *Client) %s(%s)", name, strings.Join(args, ", "))
+	switch {
+	case raw:
+		fmt.Fprintf(w, "//\n// The caller closes the body of the response.\n")
+		fmt.Fprintf(w, "%s (*http.Response, error) {\n", signature)
+		fmt.Fprintf(w, "\treturn c.send(ctx, %q, %s, %s, %s, %s)\n}\n\n", route.Method, path, query, in, contentType)
+	case result != "":
+		fmt.Fprintf(w, "%s (%s, error) {\n\tvar out %s\n", signature, result, result)
+		fmt.Fprintf(w, "\terr := c.do(ctx, %q, %s, %s, %s, %s, &out)\n\treturn out, err\n}\n\n", route.Method, path, query, in, contentType)
+	default:
+		fmt.Fprintf(w, "%s error {\n", signature)
+		fmt.Fprintf(w, "\treturn c.do(ctx, %q, %s, %s, %s, %s, nil)\n}\n\n", route.Method, path, query, in, contentType)
+	}
+}
+
+// successResponse returns the first 2xx response of an operation.
+func successResponse(operation *Operation) *Response {
+	statuses := []int{}
+	for code := range operation.Responses {
+		if status, err := strconv.Atoi(code); err == nil && status >= 200 && status < 300 {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	sort.Ints(statuses)
+	return operation.Responses[strconv.Itoa(statuses[0])]
+}
+
+// writeParams writes the type of the query parameters of an operation, with
+// the method encoding them. Parameters left to their zero value are not
+// sent.
+func (g *generator) writeParams(w *bytes.Buffer, name string, parameters []Parameter) {
+	fmt.Fprintf(w, "// %sParams are the query parameters of %s.\ntype %sParams struct {\n", name, name, name)
+	for _, parameter := range parameters {
+		if parameter.Description != "" {
+			fmt.Fprintf(w, "\t// %s\n", parameter.Description)
+		}
+		fmt.Fprintf(w, "\t%s %s\n", exportedName(parameter.Name), g.goType(parameter.Schema))
+	}
+	w.WriteString("}\n\n")
+
+	fmt.Fprintf(w, "func (p *%sParams) values() url.Values {\n\tvalues := url.Values{}\n\tif p == nil {\n\t\treturn values\n\t}\n", name)
+	for _, parameter := range parameters {
+		field := "p." + exportedName(parameter.Name)
+		switch goType := g.goType(parameter.Schema); goType {
+		case "string":
+			fmt.Fprintf(w, "\tif %s != \"\" {\n\t\tvalues.Set(%q, %s)\n\t}\n", field, parameter.Name, field)
+		case "int":
+			g.imports["strconv"] = true
+			fmt.Fprintf(w, "\tif %s != 0 {\n\t\tvalues.Set(%q, strconv.Itoa(%s))\n\t}\n", field, parameter.Name, field)
+		case "bool":
+			fmt.Fprintf(w, "\tif %s {\n\t\tvalues.Set(%q, \"true\")\n\t}\n", field, parameter.Name)
+		case "time.Time":
+			fmt.Fprintf(w, "\tif !%s.IsZero() {\n\t\tvalues.Set(%q, %s.Format(time.RFC3339))\n\t}\n", field, parameter.Name, field)
+		case "[]string":
+			if parameter.Explode != nil && *parameter.Explode {
+				fmt.Fprintf(w, "\tfor _, value := range %s {\n\t\tvalues.Add(%q, value)\n\t}\n", field, parameter.Name)
+			} else {
+				g.imports["strings"] = true
+				fmt.Fprintf(w, "\tif len(%s) > 0 {\n\t\tvalues.Set(%q, strings.Join(%s, \",\"))\n\t}\n", field, parameter.Name, field)
+			}
+		}
+	}
+	w.WriteString("\treturn values\n}\n\n")
+}
+
+// initialisms are the words Go names write in capitals.
+var initialisms = map[string]string{"id": "ID", "ids": "IDs", "url": "URL", "ip": "IP", "sha256": "SHA256", "api": "API", "json": "JSON", "http": "HTTP", "uri": "URI"}
+
+// words splits snake_case and camelCase names into words.
+func words(name string) []string {
+	parts := []string{}
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		start := 0
+		for i, r := range part {
+			if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(part[i-1])) {
+				parts = append(parts, part[start:i])
+				start = i
+			}
+		}
+		parts = append(parts, part[start:])
+	}
+	return parts
+}
+
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(initialism)
+		} else {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+func unexportedName(name string) string {
+	parts := words(name)
+	first := strings.ToLower(parts[0])
+	return first + strings.TrimPrefix(exportedName(name), exportedName(parts[0]))
+}
+
+// thirdPerson turns a summary, such as "List tasks", into the end of a
+// sentence about the method calling the operation: "lists tasks".
+func thirdPerson(summary string) string {
+	verb, rest, _ := strings.Cut(summary, " ")
+	verb = strings.ToLower(verb)
+	switch {
+	case strings.HasSuffix(verb, "s"), strings.HasSuffix(verb, "sh"), strings.HasSuffix(verb, "ch"):
+		verb += "es"
+	default:
+		verb += "s"
+	}
+	if rest == "" {
+		return verb
+	}
+	return verb + " " + lowerFirst(rest)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
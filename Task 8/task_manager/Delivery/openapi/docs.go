@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docsPage renders the document in the browser. It is plain HTML and
+// JavaScript, so the docs work without fetching anything but the document.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Serve responds with the document describing the API.
+func Serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", JSON())
+}
+
+// Docs responds with the page browsing the document.
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema of the JSON encoding of values of type t. Named
+// structs become components of the document, referenced by their Go name;
+// their fields are described the way encoding/json encodes them.
+func (b *builder) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		b.component(t)
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	default:
+		// Interfaces hold any JSON value.
+		return &Schema{}
+	}
+}
+
+// component adds the schema of a named struct to the document, once.
+func (b *builder) component(t reflect.Type) {
+	name := t.Name()
+	if _, ok := b.doc.Components.Schemas[name]; ok {
+		return
+	}
+	// The placeholder ends the recursion of types referring to themselves.
+	b.doc.Components.Schemas[name] = &Schema{}
+	schema := b.structSchema(t)
+	schema.Description = b.descriptions[name]
+	b.doc.Components.Schemas[name] = schema
+}
+
+// structSchema describes the fields of a struct as encoding/json encodes
+// them: by their json names, skipping the ones tagged "-", with the fields of
+// embedded structs inlined. Fields tagged binding:"required" are required.
+func (b *builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := b.structSchema(field.Type)
+			for property, fieldSchema := range embedded.Properties {
+				schema.Properties[property] = fieldSchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.schemaOf(field.Type)
+		if strings.Contains(field.Tag.Get("binding"), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,594 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"task_manager/Domain"
+)
+
+// access is who may call an operation.
+type access int
+
+const (
+	public access = iota
+	// member operations take the token of a workspace member.
+	member
+	// admin operations take the token of a workspace admin.
+	admin
+	// stream operations take a member's token, in the Authorization header
+	// or, for browsers' EventSource and WebSocket, the access_token query
+	// parameter.
+	stream
+)
+
+type builder struct {
+	doc          *Document
+	descriptions map[string]string
+	// prefix is the path of the version of the API the operations added
+	// belong to, if any.
+	prefix string
+}
+
+// op is an operation being added to the document.
+type op struct {
+	b         *builder
+	operation *Operation
+}
+
+// add adds the operation of method on route, a route of the router such as
+// "/tasks/:id", whose path parameters are documented from their names. The
+// summary starts with a verb, which the comments of the client's methods
+// conjugate: "List tasks" documents ListTasks as "ListTasks lists tasks".
+func (b *builder) add(method, route, tag, id, summary string, who access) *op {
+	operation := &Operation{
+		OperationID: id,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Responses: map[string]*Response{
+			"429":     {Ref: "#/components/responses/TooManyRequests"},
+			"default": {Ref: "#/components/responses/Error"},
+		},
+	}
+	segments := strings.Split(route, "/")
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			operation.Parameters = append(operation.Parameters, pathParameter(segment[1:], segments[1]))
+		}
+	}
+	switch who {
+	case member, admin:
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+	case stream:
+		operation.Security = []map[string][]string{{"bearerAuth": {}}, {"accessToken": {}}}
+	}
+	if who != public {
+		operation.Responses["401"] = &Response{Ref: "#/components/responses/Unauthorized"}
+		operation.Responses["403"] = &Response{Ref: "#/components/responses/Forbidden"}
+	}
+	if who == admin {
+		operation.Description = "Requires the admin role in the workspace of the token."
+	}
+
+	path := openAPIPath(b.prefix + route)
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = PathItem{}
+	}
+	b.doc.Paths[path][strings.ToLower(method)] = operation
+	return &op{b: b, operation: operation}
+}
+
+// pathParameter documents a path parameter: the IDs of the resources
+// routed under collection and, for members, their username.
+func pathParameter(name, collection string) Parameter {
+	parameter := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "integer"}}
+	switch name {
+	case "id":
+		parameter.Description = "ID of the " + strings.TrimSuffix(collection, "s")
+	case "rev":
+		parameter.Description = "Revision of the task"
+	case "username":
+		parameter.Description = "Username of the member"
+		parameter.Schema = &Schema{Type: "string"}
+	default:
+		parameter.Description = "ID of the " + strings.TrimSuffix(strings.ReplaceAll(name, "_", " "), " id")
+	}
+	return parameter
+}
+
+func (o *op) describe(description string) *op {
+	if o.operation.Description != "" {
+		description += " " + o.operation.Description
+	}
+	o.operation.Description = description
+	return o
+}
+
+func (o *op) query(name string, schema *Schema, description string) *op {
+	parameter := Parameter{Name: name, In: "query", Description: description, Schema: schema}
+	if schema.Type == "array" {
+		// Lists are comma separated.
+		explode := false
+		parameter.Explode = &explode
+	}
+	o.operation.Parameters = append(o.operation.Parameters, parameter)
+	return o
+}
+
+// repeated adds a query parameter given once per value.
+func (o *op) repeated(name, description string) *op {
+	o.query(name, list(), description)
+	*o.operation.Parameters[len(o.operation.Parameters)-1].Explode = true
+	return o
+}
+
+// taskFilter adds the query parameters narrowing task listings.
+func (o *op) taskFilter() *op {
+	return o.query("labels", list(), "Labels the tasks all have").
+		query("any_labels", list(), "Labels the tasks have at least one of").
+		query("priority", list(Domain.Priorities...), "Priorities of the tasks").
+		query("status", list(), "Statuses of the tasks").
+		query("assignee", list(), "Assignees of the tasks").
+		query("overdue", boolean(), "Only the overdue tasks")
+}
+
+// json takes a JSON body of the type of v.
+func (o *op) json(v interface{}) *op {
+	return o.body("application/json", o.b.schemaOf(reflect.TypeOf(v)), true)
+}
+
+// optional makes the body of the operation optional.
+func (o *op) optional() *op {
+	o.operation.RequestBody.Required = false
+	return o
+}
+
+func (o *op) body(contentType string, schema *Schema, required bool) *op {
+	if o.operation.RequestBody == nil {
+		o.operation.RequestBody = &RequestBody{Required: required, Content: map[string]MediaType{}}
+	}
+	o.operation.RequestBody.Content[contentType] = MediaType{Schema: schema}
+	return o
+}
+
+// returns responds with status and a JSON body of the type of v, or no body
+// when v is nil.
+func (o *op) returns(status int, v interface{}) *op {
+	response := &Response{Description: http.StatusText(status)}
+	if v != nil {
+		response.Content = map[string]MediaType{"application/json": {Schema: o.b.schemaOf(reflect.TypeOf(v))}}
+	}
+	o.operation.Responses[strconv.Itoa(status)] = response
+	return o
+}
+
+// returnsFile responds with status and a body of one of the content types.
+func (o *op) returnsFile(status int, description string, contentTypes ...string) *op {
+	response := &Response{Description: description, Content: map[string]MediaType{}}
+	for _, contentType := range contentTypes {
+		response.Content[contentType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	o.operation.Responses[strconv.Itoa(status)] = response
+	return o
+}
+
+// nullable makes the JSON body of the status response possibly null.
+func (o *op) nullable(status int) *op {
+	schema := o.operation.Responses[strconv.Itoa(status)].Content["application/json"].Schema
+	schema.Type = []string{schema.Type.(string), "null"}
+	return o
+}
+
+func (o *op) header(status int, name, description string, schema *Schema) *op {
+	response := o.operation.Responses[strconv.Itoa(status)]
+	if response.Headers == nil {
+		response.Headers = map[string]Header{}
+	}
+	response.Headers[name] = Header{Description: description, Schema: schema}
+	return o
+}
+
+// fails lists the statuses the operation fails with, with an ErrorResponse.
+func (o *op) fails(statuses ...int) *op {
+	for _, status := range statuses {
+		o.operation.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     errorContent(),
+		}
+	}
+	return o
+}
+
+// failsWith responds with status and a JSON body of the type of v, which
+// tells more than an ErrorResponse.
+func (o *op) failsWith(status int, v interface{}) *op {
+	return o.returns(status, v)
+}
+
+func errorContent() map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}}}
+}
+
+func str() *Schema      { return &Schema{Type: "string"} }
+func integer() *Schema  { return &Schema{Type: "integer"} }
+func boolean() *Schema  { return &Schema{Type: "boolean"} }
+func dateTime() *Schema { return &Schema{Type: "string", Format: "date-time"} }
+
+func enum(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+func list(values ...string) *Schema {
+	return &Schema{Type: "array", Items: &Schema{Type: "string", Enum: values}}
+}
+
+func withDefault(schema *Schema, value interface{}) *Schema {
+	schema.Default = value
+	return schema
+}
+
+// dateRange documents the from and to parameters of reports, which take
+// RFC 3339 times or dates.
+func (o *op) dateRange() *op {
+	return o.query("from", str(), "Start of the range, as an RFC 3339 time or a date").
+		query("to", str(), "End of the range, as an RFC 3339 time or a date, which includes that day")
+}
+
+var (
+	documentOnce sync.Once
+	document     *Document
+	documentJSON []byte
+)
+
+// Spec returns the document describing the API.
+func Spec() *Document {
+	documentOnce.Do(func() {
+		document = build()
+		var err error
+		if documentJSON, err = json.MarshalIndent(document, "", "  "); err != nil {
+			panic(err)
+		}
+	})
+	return document
+}
+
+// JSON returns the document describing the API, encoded.
+func JSON() []byte {
+	Spec()
+	return documentJSON
+}
+
+func build() *Document {
+	b := &builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:   "Task Manager API",
+				Version: "1.0.0",
+				Description: "Tasks, projects and their workspaces. Requests are authenticated with the token " +
+					"POST /v1/login returns, which is bound to one workspace; POST /v1/workspaces/{id}/token switches it. " +
+					"Every request may be rate limited, and every failure has an ErrorResponse body. " +
+					"The operations of this version are served under /v1; the same paths without /v1 are deprecated aliases.",
+			},
+			Tags: []Tag{
+				{Name: "Health", Description: "Probes, metrics and documentation of the service"},
+				{Name: "GraphQL", Description: "Tasks, users and comments over GraphQL"},
+				{Name: "Users", Description: "Accounts and their tokens"},
+				{Name: "Workspaces", Description: "Workspaces and their members"},
+				{Name: "Tasks", Description: "Tasks, their history, trash, recurrence and transfers"},
+				{Name: "Subtasks", Description: "Subtasks and dependencies between tasks"},
+				{Name: "Comments", Description: "Comments and activity of tasks"},
+				{Name: "Attachments", Description: "Files attached to tasks"},
+				{Name: "Time", Description: "Worklogs, timers and time reports"},
+				{Name: "Reports", Description: "Reports on the tasks of a workspace"},
+				{Name: "Labels", Description: "Labels of the tasks"},
+				{Name: "Projects", Description: "Projects, their members and boards"},
+				{Name: "Search", Description: "Full text search"},
+				{Name: "Events", Description: "Live events of the workspace"},
+				{Name: "Audit", Description: "Audit log of the workspace"},
+				{Name: "Webhooks", Description: "Webhooks and their deliveries"},
+			},
+			Paths: map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				Responses: map[string]*Response{
+					"Error":        {Description: "The request failed", Content: errorContent()},
+					"Unauthorized": {Description: "The token is missing, invalid or expired", Content: errorContent()},
+					"Forbidden":    {Description: "The token's user is not a member of its workspace, or not an admin of it for admin operations", Content: errorContent()},
+					"TooManyRequests": {
+						Description: "The client is over the rate limit of the route",
+						Content:     errorContent(),
+						Headers: map[string]Header{
+							"Retry-After": {Description: "Seconds to wait before retrying", Schema: integer()},
+						},
+					},
+				},
+				SecuritySchemes: map[string]SecurityScheme{
+					"bearerAuth":  {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token returned by POST /v1/login"},
+					"accessToken": {Type: "apiKey", In: "query", Name: "access_token", Description: "Token returned by POST /v1/login, for clients that cannot set headers"},
+				},
+			},
+		},
+		descriptions: map[string]string{
+			"Task":            "A task. Its id, timestamps and revision are kept by the API.",
+			"User":            "An account. Its password is only ever written.",
+			"Workspace":       "A workspace, which holds its own tasks, projects and labels.",
+			"UserWorkspace":   "A workspace along with the role of the user in it.",
+			"Membership":      "The role of a user in a workspace: admin or member.",
+			"Project":         "A project, grouping tasks on a board of columns.",
+			"ProjectMember":   "The role of a user in a project: owner, maintainer, member or viewer.",
+			"BulkRequest":     "A list of operations on tasks, applied in order.",
+			"ImportReport":    "The outcome of an import, row by row.",
+			"Webhook":         "A URL events of the workspace are posted to.",
+			"WebhookDelivery": "The posting of an event to a webhook, with its attempts.",
+			"Event":           "A change in the workspace, as streamed to clients and webhooks.",
+			"ErrorResponse":   "The body of every failed request.",
+			"GraphQLResponse": "The data a GraphQL operation resolved, and its errors.",
+		},
+	}
+	b.schemaOf(reflect.TypeOf(ErrorResponse{}))
+
+	b.add("GET", "/healthz", "Health", "healthz", "Check the service is alive", public).
+		returns(200, HealthResponse{})
+	b.add("GET", "/readyz", "Health", "readyz", "Check the service can serve requests", public).
+		returns(200, HealthResponse{}).failsWith(503, HealthResponse{})
+	b.add("GET", "/metrics", "Health", "metrics", "Get the metrics of the service", public).
+		returnsFile(200, "Metrics in the Prometheus text format", "text/plain")
+	b.add("GET", "/openapi.json", "Health", "openAPI", "Get this document", public).
+		returnsFile(200, "The OpenAPI document", "application/json")
+	b.add("GET", "/docs", "Health", "docs", "Browse this document", public).
+		returnsFile(200, "A page browsing the document", "text/html")
+
+	b.add("POST", "/graphql", "GraphQL", "graphQL", "Run a GraphQL query or mutation", member).
+		describe("The schema is Delivery/graphql/schema.graphql; introspection is allowed. "+
+			"The failures of the operation, such as a mutation needing the admin role or an operation over the complexity limit, are in the errors of a 200 response.").
+		json(GraphQLRequest{}).returns(200, GraphQLResponse{}).fails(400)
+	b.add("GET", "/graphql/ws", "GraphQL", "graphQLWebSocket", "Run GraphQL subscriptions over a WebSocket", stream).
+		describe("Upgrades to a WebSocket speaking the graphql-transport-ws protocol, for subscriptions such as taskChanged.").
+		returns(101, nil)
+
+	b.prefix = "/v1"
+	b.add("POST", "/register", "Users", "register", "Create an account", public).
+		describe("Every account gets a workspace of its own, which it is the admin of.").
+		json(Domain.User{}).returns(201, MessageResponse{}).fails(400)
+	b.add("POST", "/login", "Users", "login", "Log in", public).
+		describe("Returns a token for the workspace asked for, or the default workspace of the user.").
+		json(LoginRequest{}).returns(200, TokenResponse{}).fails(400, 403)
+	b.add("GET", "/users", "Users", "listUsers", "List the users", admin).
+		returns(200, []Domain.User{})
+	b.add("POST", "/users/promote/:id", "Users", "promoteUser", "Promote a user to admin", admin).
+		returns(200, MessageResponse{}).fails(400)
+
+	b.add("GET", "/workspaces", "Workspaces", "listWorkspaces", "List the workspaces of the user", member).
+		returns(200, []Domain.UserWorkspace{})
+	b.add("POST", "/workspaces", "Workspaces", "createWorkspace", "Create a workspace", member).
+		describe("The user becomes its admin.").
+		json(Domain.Workspace{}).returns(201, Domain.Workspace{}).fails(400)
+	b.add("POST", "/workspaces/:id/token", "Workspaces", "switchWorkspace", "Get a token for another workspace", member).
+		returns(200, TokenResponse{}).fails(400, 403)
+	b.add("GET", "/workspaces/:id/members", "Workspaces", "listWorkspaceMembers", "List the members of a workspace", member).
+		returns(200, []Domain.Membership{}).fails(400, 403)
+	b.add("PUT", "/workspaces/:id/members", "Workspaces", "setWorkspaceMember", "Add a member or change their role", admin).
+		json(Domain.Membership{}).returns(200, Domain.Membership{}).fails(400, 404)
+	b.add("DELETE", "/workspaces/:id/members/:username", "Workspaces", "removeWorkspaceMember", "Remove a member", admin).
+		returns(204, nil).fails(400, 404)
+
+	b.add("GET", "/tasks", "Tasks", "listTasks", "List tasks", member).
+		taskFilter().returns(200, []Domain.Task{})
+	b.add("POST", "/tasks", "Tasks", "createTask", "Create a task", admin).
+		json(Domain.Task{}).returns(201, Domain.Task{}).fails(400)
+	b.add("GET", "/tasks/:id", "Tasks", "getTask", "Get a task", member).
+		returns(200, Domain.Task{}).fails(400, 404)
+	b.add("PUT", "/tasks/:id", "Tasks", "updateTask", "Update a task", admin).
+		describe("Returns null, or with scope=future the future occurrences of the recurring task, which the update applies to.").
+		query("scope", withDefault(enum("this", "future"), "this"), "Occurrences of a recurring task updated").
+		json(Domain.Task{}).returns(200, []Domain.Task{}).nullable(200).fails(400, 404, 409)
+	b.add("DELETE", "/tasks/:id", "Tasks", "deleteTask", "Move a task to the trash", admin).
+		returns(204, nil).fails(400, 404)
+	b.add("POST", "/tasks/bulk", "Tasks", "bulkTasks", "Run operations on several tasks at once", admin).
+		describe("Atomic requests apply all their operations or none.").
+		json(Domain.BulkRequest{}).returns(200, BulkResponse{}).fails(400, 501).failsWith(422, BulkFailure{})
+	b.add("GET", "/tasks/export", "Tasks", "exportTasks", "Export tasks", member).
+		query("format", withDefault(enum(Domain.FormatCSV, Domain.FormatJSONL, Domain.FormatICS), Domain.FormatCSV), "Format of the export").
+		taskFilter().
+		returnsFile(200, "The tasks as a file", "text/csv", "application/x-ndjson", "text/calendar").fails(400)
+	b.add("POST", "/tasks/import", "Tasks", "importTasks", "Import tasks", admin).
+		describe("Takes up to 10 MB of CSV or JSON lines; the format is guessed from the content type when not given.").
+		query("format", enum(Domain.FormatCSV, Domain.FormatJSON, Domain.FormatJSONL), "Format of the import").
+		query("dry_run", boolean(), "Check the import without applying it").
+		query("atomic", boolean(), "Import every row or none").
+		repeated("map", "Pairs of column:field mapping the columns of a CSV import to task fields").
+		body("text/csv", &Schema{Type: "string"}, true).
+		body("application/x-ndjson", &Schema{Type: "string"}, true).
+		returns(200, Domain.ImportReport{}).fails(400, 413, 501).failsWith(422, ImportFailure{})
+	b.add("GET", "/tasks/facets", "Tasks", "getTaskFacets", "Count tasks per label, status and priority", member).
+		taskFilter().returns(200, Domain.TaskFacets{})
+	b.add("GET", "/tasks/trash", "Tasks", "listTrash", "List the tasks in the trash", admin).
+		returns(200, []Domain.Task{})
+	b.add("POST", "/tasks/:id/restore", "Tasks", "restoreTask", "Restore a task from the trash", admin).
+		returns(200, Domain.Task{}).fails(400, 404)
+	b.add("GET", "/tasks/:id/history", "Tasks", "getTaskHistory", "List the revisions of a task", member).
+		returns(200, []Domain.TaskRevision{}).fails(400, 404)
+	b.add("POST", "/tasks/:id/revert/:rev", "Tasks", "revertTask", "Revert a task to a revision", admin).
+		returns(200, Domain.Task{}).fails(400, 404)
+	b.add("GET", "/tasks/:id/occurrences", "Tasks", "listOccurrences", "List the occurrences of a recurring task", member).
+		returns(200, []Domain.Task{}).fails(400, 404)
+	b.add("GET", "/tasks/:id/reminders", "Tasks", "listReminders", "List the reminders of a task", member).
+		returns(200, []Domain.Reminder{}).fails(400, 404)
+
+	b.add("GET", "/tasks/:id/subtasks", "Subtasks", "listSubtasks", "List the subtasks of a task", member).
+		returns(200, SubtasksResponse{}).fails(400, 404)
+	b.add("POST", "/tasks/:id/subtasks", "Subtasks", "createSubtask", "Create a subtask", admin).
+		json(Domain.Task{}).returns(201, Domain.Task{}).fails(400, 404)
+	b.add("GET", "/tasks/:id/dependencies", "Subtasks", "listDependencies", "List the tasks a task blocks and is blocked by", member).
+		returns(200, DependenciesResponse{}).fails(400, 404)
+	b.add("POST", "/tasks/:id/dependencies", "Subtasks", "addDependency", "Make a task blocked by another", admin).
+		json(DependencyRequest{}).returns(201, Domain.Task{}).fails(400, 404, 409)
+	b.add("DELETE", "/tasks/:id/dependencies/:blocker_id", "Subtasks", "removeDependency", "Unblock a task", admin).
+		returns(204, nil).fails(400, 404)
+
+	b.add("GET", "/tasks/:id/comments", "Comments", "listComments", "List the comments of a task", member).
+		query("skip", withDefault(integer(), 0), "Comments skipped").
+		query("limit", withDefault(integer(), 20), "Comments returned at most").
+		returns(200, []Domain.Comment{}).
+		header(200, "X-Total-Count", "Number of comments of the task", integer()).fails(400, 404)
+	b.add("POST", "/tasks/:id/comments", "Comments", "createComment", "Comment on a task", member).
+		json(Domain.Comment{}).returns(201, Domain.Comment{}).fails(400, 404)
+	b.add("PUT", "/tasks/:id/comments/:comment_id", "Comments", "updateComment", "Edit a comment", member).
+		describe("Only the body of a comment can be edited, by its author.").
+		json(Domain.Comment{}).returns(200, Domain.Comment{}).fails(400, 403, 404)
+	b.add("DELETE", "/tasks/:id/comments/:comment_id", "Comments", "deleteComment", "Delete a comment", member).
+		returns(204, nil).fails(400, 403, 404)
+	b.add("GET", "/tasks/:id/activity", "Comments", "listActivity", "List the comments and status changes of a task", member).
+		returns(200, []Domain.Activity{}).fails(400, 404)
+
+	b.add("GET", "/tasks/:id/attachments", "Attachments", "listAttachments", "List the attachments of a task", member).
+		returns(200, []Domain.Attachment{}).fails(400, 404)
+	b.add("POST", "/tasks/:id/attachments", "Attachments", "uploadAttachment", "Attach a file to a task", member).
+		body("multipart/form-data", &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"file":   {Type: "string", Format: "binary"},
+				"sha256": {Type: "string", Description: "Hex SHA-256 of the file, checked on upload"},
+			},
+			Required: []string{"file"},
+		}, true).
+		returns(201, Domain.Attachment{}).fails(400, 404, 413, 415, 502, 503)
+	b.add("GET", "/tasks/:id/attachments/:attachment_id", "Attachments", "downloadAttachment", "Download an attachment", member).
+		describe("Supports range requests; the ETag is the SHA-256 of the file.").
+		returnsFile(200, "The file", "application/octet-stream").
+		returnsFile(206, "Part of the file", "application/octet-stream").fails(400, 404)
+	b.add("DELETE", "/tasks/:id/attachments/:attachment_id", "Attachments", "deleteAttachment", "Delete an attachment", member).
+		returns(204, nil).fails(400, 403, 404)
+
+	b.add("GET", "/tasks/:id/worklogs", "Time", "listWorklogs", "List the time logged on a task", member).
+		query("user", str(), "User who logged the time").dateRange().
+		returns(200, []Domain.Worklog{}).fails(400, 404)
+	b.add("POST", "/tasks/:id/worklogs", "Time", "logTime", "Log time on a task", member).
+		json(Domain.Worklog{}).returns(201, Domain.Worklog{}).fails(400, 404)
+	b.add("DELETE", "/tasks/:id/worklogs/:worklog_id", "Time", "deleteWorklog", "Delete a worklog", member).
+		returns(204, nil).fails(400, 403, 404)
+	b.add("POST", "/tasks/:id/timer/start", "Time", "startTimer", "Start a timer on a task", member).
+		returns(201, Domain.Worklog{}).fails(400, 404, 409)
+	b.add("POST", "/tasks/:id/timer/stop", "Time", "stopTimer", "Stop the running timer of a task", member).
+		describe("The body, and the note it holds, are optional.").
+		json(TimerStopRequest{}).optional().returns(200, Domain.Worklog{}).fails(400, 404)
+	b.add("GET", "/worklogs/totals", "Time", "getTimeTotals", "Total the time logged", member).
+		query("user", str(), "User who logged the time").dateRange().
+		query("task_id", integer(), "Task the time was logged on").
+		query("group_by", list(Domain.GroupByTask, Domain.GroupByUser, Domain.GroupByDay), "Grouping of the totals, by task when not given").
+		returns(200, []Domain.TimeTotal{}).fails(400)
+
+	b.add("GET", "/reports/time", "Reports", "getTimeReport", "Compare the time logged on tasks to their estimates", admin).
+		query("project_id", integer(), "Project of the tasks").taskFilter().
+		query("user", str(), "User who logged the time").dateRange().
+		returns(200, Domain.TimeReport{}).fails(400)
+	for _, report := range []struct {
+		path, id, summary string
+		v                 interface{}
+	}{
+		{"/reports/throughput", "getThroughputReport", "Count the tasks created and completed per interval", []Domain.ThroughputBucket{}},
+		{"/reports/cycle-time", "getCycleTimeReport", "Measure how long tasks take to complete", Domain.CycleTimeReport{}},
+		{"/reports/overdue", "getOverdueReport", "List the overdue tasks", Domain.OverdueReport{}},
+		{"/reports/workload", "getWorkloadReport", "Count the open tasks of each assignee", []Domain.Workload{}},
+	} {
+		b.add("GET", report.path, "Reports", report.id, report.summary, admin).
+			query("interval", enum(Domain.IntervalDay, Domain.IntervalWeek, Domain.IntervalMonth), "Interval of the buckets").
+			query("project_id", integer(), "Project of the tasks").dateRange().
+			returns(200, report.v).fails(400)
+	}
+
+	b.add("GET", "/labels", "Labels", "listLabels", "List the labels", member).
+		returns(200, []Domain.Label{})
+	b.add("POST", "/labels", "Labels", "createLabel", "Create a label", admin).
+		json(Domain.Label{}).returns(201, Domain.Label{}).fails(400, 409)
+	b.add("PUT", "/labels/:id", "Labels", "updateLabel", "Update a label", admin).
+		json(Domain.Label{}).returns(200, Domain.Label{}).fails(400, 404, 409)
+	b.add("DELETE", "/labels/:id", "Labels", "deleteLabel", "Delete a label", admin).
+		returns(204, nil).fails(400, 404)
+
+	b.add("GET", "/projects", "Projects", "listProjects", "List the projects of the user", member).
+		returns(200, []Domain.Project{})
+	b.add("POST", "/projects", "Projects", "createProject", "Create a project", member).
+		describe("The user becomes its owner.").
+		json(Domain.Project{}).returns(201, Domain.Project{}).fails(400)
+	b.add("GET", "/projects/:id", "Projects", "getProject", "Get a project", member).
+		returns(200, Domain.Project{}).fails(400, 403, 404)
+	b.add("PUT", "/projects/:id", "Projects", "updateProject", "Update a project", member).
+		json(Domain.Project{}).returns(200, Domain.Project{}).fails(400, 403, 404)
+	b.add("DELETE", "/projects/:id", "Projects", "deleteProject", "Delete a project", member).
+		returns(204, nil).fails(400, 403, 404)
+	b.add("PUT", "/projects/:id/members", "Projects", "setProjectMember", "Add a member or change their role", member).
+		json(Domain.ProjectMember{}).returns(200, Domain.Project{}).fails(400, 403, 404)
+	b.add("DELETE", "/projects/:id/members/:username", "Projects", "removeProjectMember", "Remove a member", member).
+		returns(200, Domain.Project{}).fails(400, 403, 404)
+	b.add("GET", "/projects/:id/tasks", "Projects", "listProjectTasks", "List the tasks of a project", member).
+		returns(200, []Domain.Task{}).fails(400, 403, 404)
+	b.add("POST", "/projects/:id/tasks", "Projects", "createProjectTask", "Create a task in a project", member).
+		json(Domain.Task{}).returns(201, Domain.Task{}).fails(400, 403, 404)
+	b.add("GET", "/projects/:id/board", "Projects", "getBoard", "Get the board of a project", member).
+		returns(200, []Domain.BoardColumnTasks{}).fails(400, 403, 404)
+	b.add("POST", "/projects/:id/board/move", "Projects", "moveBoardTask", "Move a task on the board", member).
+		json(BoardMoveRequest{}).returns(200, Domain.Task{}).fails(400, 403, 404)
+
+	b.add("GET", "/search", "Search", "search", "Search tasks and comments", member).
+		describe("Words are matched in full text; status:, assignee:, label:, priority: and due: narrow the hits.").
+		query("q", str(), "Query").
+		query("limit", withDefault(integer(), 20), "Hits returned at most").
+		returns(200, []Domain.SearchHit{}).fails(400)
+
+	b.add("GET", "/events", "Events", "streamEvents", "Stream the events of the workspace", stream).
+		describe("A server-sent events stream of Event, resumed after the last_event_id parameter or the Last-Event-ID header.").
+		query("last_event_id", integer(), "Event the stream resumes after").
+		returnsFile(200, "Server-sent events", "text/event-stream")
+	b.add("GET", "/events/ws", "Events", "streamEventsWebSocket", "Stream the events of the workspace over a WebSocket", stream).
+		describe("Upgrades to a WebSocket sending each Event as a JSON message.").
+		query("last_event_id", integer(), "Event the stream resumes after").
+		returns(101, nil)
+
+	b.add("GET", "/audit", "Audit", "listAuditEntries", "List the audit log", admin).
+		query("actor", str(), "User who acted").
+		query("action", str(), "Action, such as task.updated").
+		query("target_type", str(), "Type of the target, such as task").
+		query("target_id", integer(), "ID of the target").
+		query("from", dateTime(), "Start of the range").
+		query("to", dateTime(), "End of the range").
+		query("skip", integer(), "Entries skipped").
+		query("limit", integer(), "Entries returned at most").
+		returns(200, []Domain.AuditEntry{}).fails(400)
+	b.add("GET", "/audit/export", "Audit", "exportAuditLog", "Export the audit log", admin).
+		query("actor", str(), "User who acted").
+		query("action", str(), "Action, such as task.updated").
+		query("target_type", str(), "Type of the target, such as task").
+		query("target_id", integer(), "ID of the target").
+		query("from", dateTime(), "Start of the range").
+		query("to", dateTime(), "End of the range").
+		returnsFile(200, "AuditEntry JSON lines", "application/x-ndjson").fails(400)
+
+	b.add("GET", "/webhooks", "Webhooks", "listWebhooks", "List the webhooks", admin).
+		returns(200, []Domain.Webhook{})
+	b.add("POST", "/webhooks", "Webhooks", "createWebhook", "Create a webhook", admin).
+		json(Domain.Webhook{}).returns(201, Domain.Webhook{}).fails(400)
+	b.add("GET", "/webhooks/:id", "Webhooks", "getWebhook", "Get a webhook", admin).
+		returns(200, Domain.Webhook{}).fails(400, 404)
+	b.add("PUT", "/webhooks/:id", "Webhooks", "updateWebhook", "Update a webhook", admin).
+		json(Domain.Webhook{}).returns(200, Domain.Webhook{}).fails(400, 404)
+	b.add("DELETE", "/webhooks/:id", "Webhooks", "deleteWebhook", "Delete a webhook", admin).
+		returns(204, nil).fails(400, 404)
+	b.add("GET", "/webhooks/:id/deliveries", "Webhooks", "listWebhookDeliveries", "List the deliveries of a webhook", admin).
+		query("status", enum(Domain.DeliveryPending, Domain.DeliverySucceeded, Domain.DeliveryDead), "Status of the deliveries").
+		returns(200, []Domain.WebhookDelivery{}).fails(400, 404)
+	b.add("GET", "/webhooks/:id/deliveries/:delivery_id", "Webhooks", "getWebhookDelivery", "Get a delivery", admin).
+		returns(200, Domain.WebhookDelivery{}).fails(400, 404)
+	b.add("POST", "/webhooks/:id/deliveries/:delivery_id/redeliver", "Webhooks", "redeliverWebhook", "Deliver an event again", admin).
+		returns(202, Domain.WebhookDelivery{}).fails(400, 404)
+
+	// Streams of events hold Event values, which no operation returns as
+	// JSON.
+	b.schemaOf(reflect.TypeOf(Domain.Event{}))
+	b.doc.Components.Schemas["User"].Properties["password"].WriteOnly = true
+	return b.doc
+}
@@ -1,27 +1,211 @@
 package routers
 
 import (
+	"net/http"
 	"task_manager/Delivery/controllers"
+	"task_manager/Delivery/openapi"
 	"task_manager/Infrastructure"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-var controller controllers.IController = controllers.NewController()
+// Version is a version of the API, served under /<Name>. A version serves
+// the routes of the version before it, as changed by its Routes, so that a
+// new version only holds the handlers it changes. When Deprecated is set,
+// the version's responses announce it is going away, at Sunset if set.
+type Version struct {
+	Name       string
+	Routes     func(routes *Routes, controller controllers.IController, dbName string)
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// Versions are the versions of the API served, oldest first.
+var Versions = []Version{
+	{Name: "v1", Routes: v1},
+}
+
+// LegacyRoutes sets how the unversioned routes, the ones of the API before
+// it had versions, are served. They serve the routes of the first version,
+// or of the version asked for in the Accept-Version header, and announce
+// they are deprecated.
+type LegacyRoutes struct {
+	Enabled    bool
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// Legacy is how the unversioned routes are served; main sets it from the
+// configuration.
+var Legacy = LegacyRoutes{Enabled: true}
+
+// SetupRouter returns the routes of the API, working with the instance
+// database dbName.
+func SetupRouter(dbName string) *gin.Engine {
+	controller := controllers.NewController(dbName)
+	Infrastructure.UseDatabase(dbName)
+	r := gin.New()
+	r.Use(Infrastructure.RequestID, Infrastructure.LogRequests, Infrastructure.Recovery, Infrastructure.Trace, Infrastructure.CountRequests, Infrastructure.RateLimit)
 
-func SetupRouter() *gin.Engine {
-	r := gin.Default()
+	// The operational routes are not part of the versions of the API.
+	r.GET("/healthz", controller.Healthz)
+	r.GET("/readyz", controller.Readyz)
+	r.GET("/metrics", Infrastructure.Metrics)
+	r.GET("/openapi.json", openapi.Serve)
+	r.GET("/docs", openapi.Docs)
 
-	r.GET("/tasks", Infrastructure.Logged, controller.GetTasks)
-	r.GET("/tasks/:id", Infrastructure.Logged, controller.GetTaskByID)
-	r.POST("/tasks", Infrastructure.Admin, controller.CreateTask)
-	r.PUT("/tasks/:id", Infrastructure.Admin, controller.UpdateTask)
-	r.DELETE("/tasks/:id", Infrastructure.Admin, controller.DeleteTask)
+	// GraphQL evolves its schema rather than taking versions.
+	r.POST("/graphql", Infrastructure.Logged, controller.GraphQL)
+	r.GET("/graphql/ws", Infrastructure.QueryToken, Infrastructure.Logged, controller.GraphQLWS)
 
-	r.POST("/register", controller.CreateUser)
-	r.POST("/login", Infrastructure.Login("task_manager"))
-	r.GET("/users", Infrastructure.Admin, controller.GetUsers)
-	r.POST("/users/promote/:id", Infrastructure.Admin, controller.Promote)
+	versions := map[string]*Routes{}
+	routes := &Routes{}
+	for _, version := range Versions {
+		routes = routes.clone()
+		version.Routes(routes, controller, dbName)
+		versions[version.Name] = routes
+
+		group := r.Group("/"+version.Name, Infrastructure.APIVersion(version.Name))
+		if !version.Deprecated.IsZero() {
+			group.Use(Infrastructure.Deprecated(version.Deprecated, version.Sunset, ""))
+		}
+		routes.register(group)
+	}
+
+	if Legacy.Enabled {
+		first := Versions[0].Name
+		legacy := r.Group("", Infrastructure.Deprecated(Legacy.Deprecated, Legacy.Sunset, "/"+first))
+		for _, key := range versions[first].order {
+			method, path := splitKey(key)
+			legacy.Handle(method, path, negotiate(key, first, versions))
+		}
+	}
 
 	return r
 }
+
+// v1 is the first version of the API.
+func v1(routes *Routes, controller controllers.IController, dbName string) {
+	routes.GET("/tasks", Infrastructure.Logged, controller.GetTasks)
+	routes.GET("/tasks/:id", Infrastructure.Logged, controller.GetTaskByID)
+	routes.POST("/tasks", Infrastructure.Admin, controller.CreateTask)
+	routes.POST("/tasks/bulk", Infrastructure.Admin, controller.BulkTasks)
+	routes.GET("/tasks/export", Infrastructure.Logged, controller.ExportTasks)
+	routes.POST("/tasks/import", Infrastructure.Admin, controller.ImportTasks)
+	routes.PUT("/tasks/:id", Infrastructure.Admin, controller.UpdateTask)
+	routes.DELETE("/tasks/:id", Infrastructure.Admin, controller.DeleteTask)
+	routes.GET("/tasks/:id/history", Infrastructure.Logged, controller.GetTaskHistory)
+	routes.POST("/tasks/:id/revert/:rev", Infrastructure.Admin, controller.RevertTask)
+	routes.GET("/tasks/trash", Infrastructure.Admin, controller.GetTrash)
+	routes.GET("/tasks/facets", Infrastructure.Logged, controller.GetTaskFacets)
+	routes.POST("/tasks/:id/restore", Infrastructure.Admin, controller.RestoreTask)
+	routes.GET("/tasks/:id/occurrences", Infrastructure.Logged, controller.GetOccurrences)
+	routes.GET("/tasks/:id/reminders", Infrastructure.Logged, controller.GetReminders)
+
+	routes.GET("/tasks/:id/subtasks", Infrastructure.Logged, controller.GetSubtasks)
+	routes.POST("/tasks/:id/subtasks", Infrastructure.Admin, controller.CreateSubtask)
+	routes.GET("/tasks/:id/dependencies", Infrastructure.Logged, controller.GetDependencies)
+	routes.POST("/tasks/:id/dependencies", Infrastructure.Admin, controller.AddDependency)
+	routes.DELETE("/tasks/:id/dependencies/:blocker_id", Infrastructure.Admin, controller.RemoveDependency)
+
+	routes.GET("/tasks/:id/comments", Infrastructure.Logged, controller.GetComments)
+	routes.POST("/tasks/:id/comments", Infrastructure.Logged, controller.CreateComment)
+	routes.PUT("/tasks/:id/comments/:comment_id", Infrastructure.Logged, controller.UpdateComment)
+	routes.DELETE("/tasks/:id/comments/:comment_id", Infrastructure.Logged, controller.DeleteComment)
+	routes.GET("/tasks/:id/activity", Infrastructure.Logged, controller.GetActivity)
+
+	routes.GET("/tasks/:id/attachments", Infrastructure.Logged, controller.GetAttachments)
+	routes.POST("/tasks/:id/attachments", Infrastructure.Logged, controller.UploadAttachment)
+	routes.GET("/tasks/:id/attachments/:attachment_id", Infrastructure.Logged, controller.DownloadAttachment)
+	routes.DELETE("/tasks/:id/attachments/:attachment_id", Infrastructure.Logged, controller.DeleteAttachment)
+
+	routes.GET("/tasks/:id/worklogs", Infrastructure.Logged, controller.GetWorklogs)
+	routes.POST("/tasks/:id/worklogs", Infrastructure.Logged, controller.LogTime)
+	routes.DELETE("/tasks/:id/worklogs/:worklog_id", Infrastructure.Logged, controller.DeleteWorklog)
+	routes.POST("/tasks/:id/timer/start", Infrastructure.Logged, controller.StartTimer)
+	routes.POST("/tasks/:id/timer/stop", Infrastructure.Logged, controller.StopTimer)
+	routes.GET("/worklogs/totals", Infrastructure.Logged, controller.GetTimeTotals)
+
+	routes.GET("/reports/time", Infrastructure.Admin, controller.GetTimeReport)
+	routes.GET("/reports/throughput", Infrastructure.Admin, controller.GetThroughputReport)
+	routes.GET("/reports/cycle-time", Infrastructure.Admin, controller.GetCycleTimeReport)
+	routes.GET("/reports/overdue", Infrastructure.Admin, controller.GetOverdueReport)
+	routes.GET("/reports/workload", Infrastructure.Admin, controller.GetWorkloadReport)
+
+	routes.GET("/events", Infrastructure.QueryToken, Infrastructure.Logged, controller.StreamEvents)
+	routes.GET("/events/ws", Infrastructure.QueryToken, Infrastructure.Logged, controller.StreamEventsWS)
+
+	routes.GET("/search", Infrastructure.Logged, controller.Search)
+
+	routes.GET("/labels", Infrastructure.Logged, controller.GetLabels)
+	routes.POST("/labels", Infrastructure.Admin, controller.CreateLabel)
+	routes.PUT("/labels/:id", Infrastructure.Admin, controller.UpdateLabel)
+	routes.DELETE("/labels/:id", Infrastructure.Admin, controller.DeleteLabel)
+
+	routes.GET("/projects", Infrastructure.Logged, controller.GetProjects)
+	routes.POST("/projects", Infrastructure.Logged, controller.CreateProject)
+	routes.GET("/projects/:id", Infrastructure.Logged, controller.GetProject)
+	routes.PUT("/projects/:id", Infrastructure.Logged, controller.UpdateProject)
+	routes.DELETE("/projects/:id", Infrastructure.Logged, controller.DeleteProject)
+	routes.PUT("/projects/:id/members", Infrastructure.Logged, controller.SetProjectMember)
+	routes.DELETE("/projects/:id/members/:username", Infrastructure.Logged, controller.RemoveProjectMember)
+	routes.GET("/projects/:id/tasks", Infrastructure.Logged, controller.GetProjectTasks)
+	routes.POST("/projects/:id/tasks", Infrastructure.Logged, controller.CreateProjectTask)
+	routes.GET("/projects/:id/board", Infrastructure.Logged, controller.GetBoard)
+	routes.POST("/projects/:id/board/move", Infrastructure.Logged, controller.MoveBoardTask)
+
+	routes.POST("/register", controller.CreateUser)
+	routes.POST("/login", Infrastructure.Login(dbName))
+	routes.GET("/users", Infrastructure.Admin, controller.GetUsers)
+	routes.POST("/users/promote/:id", Infrastructure.Admin, controller.Promote)
+
+	routes.GET("/workspaces", Infrastructure.Logged, controller.GetWorkspaces)
+	routes.POST("/workspaces", Infrastructure.Logged, controller.CreateWorkspace)
+	routes.POST("/workspaces/:id/token", Infrastructure.Logged, controller.SwitchWorkspace)
+	routes.GET("/workspaces/:id/members", Infrastructure.Logged, controller.GetWorkspaceMembers)
+	routes.PUT("/workspaces/:id/members", Infrastructure.Admin, controller.SetWorkspaceMember)
+	routes.DELETE("/workspaces/:id/members/:username", Infrastructure.Admin, controller.RemoveWorkspaceMember)
+
+	routes.GET("/audit", Infrastructure.Admin, controller.GetAuditLog)
+	routes.GET("/audit/export", Infrastructure.Admin, controller.ExportAuditLog)
+
+	routes.GET("/webhooks", Infrastructure.Admin, controller.GetWebhooks)
+	routes.POST("/webhooks", Infrastructure.Admin, controller.CreateWebhook)
+	routes.GET("/webhooks/:id", Infrastructure.Admin, controller.GetWebhook)
+	routes.PUT("/webhooks/:id", Infrastructure.Admin, controller.UpdateWebhook)
+	routes.DELETE("/webhooks/:id", Infrastructure.Admin, controller.DeleteWebhook)
+	routes.GET("/webhooks/:id/deliveries", Infrastructure.Admin, controller.GetWebhookDeliveries)
+	routes.GET("/webhooks/:id/deliveries/:delivery_id", Infrastructure.Admin, controller.GetWebhookDelivery)
+	routes.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", Infrastructure.Admin, controller.RedeliverWebhook)
+}
+
+// negotiate serves the route key with the handlers of the version asked
+// for in the Accept-Version header, or of fallback. It goes last, after
+// which the handlers of the version run in turn, until one aborts.
+func negotiate(key, fallback string, versions map[string]*Routes) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader(Infrastructure.VersionHeader)
+		if version == "" {
+			version = fallback
+		}
+		routes, ok := versions[version]
+		if !ok {
+			c.JSON(http.StatusNotAcceptable, gin.H{"error": "Unknown API version " + version})
+			return
+		}
+		handlers, ok := routes.handlers[key]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not in API version " + version})
+			return
+		}
+
+		c.Header(Infrastructure.APIVersionHeader, version)
+		for _, handler := range handlers {
+			handler(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package routers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes are the routes of a version of the API, with their handlers, in
+// the order they were added.
+type Routes struct {
+	order    []string
+	handlers map[string][]gin.HandlerFunc
+}
+
+// Handle serves method on path with handlers, in place of the handlers the
+// route had.
+func (r *Routes) Handle(method, path string, handlers ...gin.HandlerFunc) {
+	if r.handlers == nil {
+		r.handlers = map[string][]gin.HandlerFunc{}
+	}
+	key := method + " " + path
+	if _, ok := r.handlers[key]; !ok {
+		r.order = append(r.order, key)
+	}
+	r.handlers[key] = handlers
+}
+
+// Remove stops serving method on path.
+func (r *Routes) Remove(method, path string) {
+	key := method + " " + path
+	delete(r.handlers, key)
+	for i, k := range r.order {
+		if k == key {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *Routes) GET(path string, handlers ...gin.HandlerFunc) {
+	r.Handle("GET", path, handlers...)
+}
+
+func (r *Routes) POST(path string, handlers ...gin.HandlerFunc) {
+	r.Handle("POST", path, handlers...)
+}
+
+func (r *Routes) PUT(path string, handlers ...gin.HandlerFunc) {
+	r.Handle("PUT", path, handlers...)
+}
+
+func (r *Routes) DELETE(path string, handlers ...gin.HandlerFunc) {
+	r.Handle("DELETE", path, handlers...)
+}
+
+func (r *Routes) clone() *Routes {
+	clone := &Routes{order: append([]string(nil), r.order...), handlers: map[string][]gin.HandlerFunc{}}
+	for key, handlers := range r.handlers {
+		clone.handlers[key] = handlers
+	}
+	return clone
+}
+
+func (r *Routes) register(group *gin.RouterGroup) {
+	for _, key := range r.order {
+		method, path := splitKey(key)
+		group.Handle(method, path, r.handlers[key]...)
+	}
+}
+
+func splitKey(key string) (string, string) {
+	method, path, _ := strings.Cut(key, " ")
+	return method, path
+}
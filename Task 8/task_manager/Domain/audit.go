@@ -0,0 +1,48 @@
+package Domain
+
+import "time"
+
+// Actions recorded in the audit log.
+const (
+	ActionTaskCreated       = "task.created"
+	ActionTaskUpdated       = "task.updated"
+	ActionTaskDeleted       = "task.deleted"
+	ActionTaskReverted      = "task.reverted"
+	ActionTaskRestored      = "task.restored"
+	ActionUserRegistered    = "user.registered"
+	ActionUserPromoted      = "user.promoted"
+	ActionUserLogin         = "user.login"
+	ActionUserLoginFailed   = "user.login_failed"
+	ActionAttachmentAdded   = "attachment.added"
+	ActionAttachmentDeleted = "attachment.deleted"
+)
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AuditEntry struct {
+	ID         int                    `json:"id"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Actor      string                 `json:"actor"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   int                    `json:"target_id"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+	IP         string                 `json:"ip"`
+	UserAgent  string                 `json:"user_agent"`
+	RequestID  string                 `json:"request_id"`
+}
+
+// AuditFilter narrows an audit log query. Zero values are ignored.
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   int
+	From       time.Time
+	To         time.Time
+	Skip       int
+	Limit      int
+}
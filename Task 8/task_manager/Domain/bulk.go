@@ -0,0 +1,56 @@
+package Domain
+
+// Bulk operations.
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// Outcomes of a bulk operation on a task.
+const (
+	BulkSucceeded = "succeeded"
+	BulkFailed    = "failed"
+	// BulkSkipped marks the tasks left unchanged because another operation
+	// of an atomic request failed.
+	BulkSkipped = "skipped"
+	// BulkValid marks the operations that would succeed, in a dry run.
+	BulkValid = "valid"
+)
+
+// BulkRequest is a batch of task operations applied in one request.
+type BulkRequest struct {
+	// Atomic applies every operation or none of them.
+	Atomic bool `json:"atomic"`
+	// DryRun checks the operations without applying them.
+	DryRun     bool            `json:"dry_run"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation creates a task, updates a task or every task matching a
+// filter, or deletes a task.
+type BulkOperation struct {
+	Op string `json:"op"`
+	// ID is the task updated or deleted.
+	ID int `json:"id,omitempty"`
+	// Filter selects the tasks updated instead of ID.
+	Filter *TaskFilter `json:"filter,omitempty"`
+	// Task is the task created.
+	Task *Task `json:"task,omitempty"`
+	// Set holds the fields updated, by their JSON names.
+	Set map[string]interface{} `json:"set,omitempty"`
+}
+
+// BulkResult is the outcome of an operation on one task. An update by filter
+// has a result for every task it matched.
+type BulkResult struct {
+	// Index is the position of the operation in the request.
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Task   *Task  `json:"task,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Before is the task as it was before an update or delete.
+	Before *Task `json:"-"`
+}
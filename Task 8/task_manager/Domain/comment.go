@@ -0,0 +1,33 @@
+package Domain
+
+import "time"
+
+// Comment is a message left on a task. Replies point at their parent through
+// ParentID; top-level comments have a ParentID of 0.
+type Comment struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	ParentID  int        `json:"parent_id"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	Mentions  []string   `json:"mentions"`
+	Deleted   bool       `json:"deleted"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Kinds of entries in a task's activity feed.
+const (
+	ActivityComment      = "comment"
+	ActivityStatusChange = "status_change"
+)
+
+// Activity is one entry of a task's activity feed: either a comment or a
+// revision that changed the task's status.
+type Activity struct {
+	Type      string        `json:"type"`
+	Timestamp time.Time     `json:"timestamp"`
+	Actor     string        `json:"actor"`
+	Comment   *Comment      `json:"comment,omitempty"`
+	Revision  *TaskRevision `json:"revision,omitempty"`
+}
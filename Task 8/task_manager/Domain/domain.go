@@ -1,16 +1,96 @@
 package Domain
 
+import (
+	"strings"
+	"time"
+)
+
+// StatusCompleted is the status of a finished task.
+const StatusCompleted = "completed"
+
+// StatusPending is the status of a task that has not been started.
+const StatusPending = "pending"
+
 type Task struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	DueDate     string `json:"due_date"`
-	Status      string `json:"status"`
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	DueDate     string          `json:"due_date"`
+	Status      string          `json:"status"`
+	Assignee    string          `json:"assignee"`
+	ParentID    int             `json:"parent_id"`
+	Checklist   []ChecklistItem `json:"checklist"`
+	BlockedBy   []int           `json:"blocked_by"`
+	ProjectID   int             `json:"project_id"`
+	Rank        float64         `json:"rank"`
+	Labels      []string        `json:"labels"`
+	Priority    string          `json:"priority"`
+	StoryPoints float64         `json:"story_points"`
+	Estimate    int             `json:"estimate_minutes"`
+	Recurrence  string          `json:"recurrence"`
+	SeriesID    int             `json:"series_id"`
+	Occurrence  int             `json:"occurrence"`
+	Overdue     bool            `json:"overdue"`
+	// ExternalID identifies a task in the system it was imported from.
+	ExternalID string `json:"external_id,omitempty"`
+	// CreatedAt, StartedAt and CompletedAt record when the task was created,
+	// first left the pending status for one other than completed, and was
+	// last completed. They are kept by the API and cannot be set.
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Progress summarises how far along a task is, based on its subtasks and
+// checklist.
+type Progress struct {
+	TotalSubtasks     int     `json:"total_subtasks"`
+	CompletedSubtasks int     `json:"completed_subtasks"`
+	ChecklistTotal    int     `json:"checklist_total"`
+	ChecklistDone     int     `json:"checklist_done"`
+	Percent           float64 `json:"percent"`
+}
+
+// IsCompleted reports whether the task has the completed status.
+func (t Task) IsCompleted() bool {
+	return strings.EqualFold(t.Status, StatusCompleted)
+}
+
+// IsPending reports whether the task has not been started. A task without a
+// status is pending.
+func (t Task) IsPending() bool {
+	return t.Status == "" || strings.EqualFold(t.Status, StatusPending)
+}
+
+// Due returns the time a task is due. A due date without a time of day is
+// due at the end of that day, UTC. It returns false when the task has no due
+// date it can make sense of.
+func (t Task) Due() (time.Time, bool) {
+	if due, err := time.Parse(time.RFC3339, t.DueDate); err == nil {
+		return due, true
+	}
+	if day, err := time.Parse("2006-01-02", t.DueDate); err == nil {
+		return day.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// IsOverdueAt reports whether the task is still open past its due time.
+func (t Task) IsOverdueAt(now time.Time) bool {
+	due, ok := t.Due()
+	return ok && !t.IsCompleted() && !now.Before(due)
 }
 
 type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
+	Email    string `json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
 }
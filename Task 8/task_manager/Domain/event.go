@@ -0,0 +1,47 @@
+package Domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Events published in addition to the ones named after audit actions.
+const (
+	EventTaskStatusChanged = "task.status_changed"
+	EventCommentCreated    = "comment.created"
+)
+
+// EventTypes lists the types of the events published by the service. Task
+// and user events are named after the matching audit actions.
+var EventTypes = []string{
+	ActionTaskCreated,
+	ActionTaskUpdated,
+	ActionTaskDeleted,
+	ActionTaskReverted,
+	ActionTaskRestored,
+	EventTaskStatusChanged,
+	ActionUserRegistered,
+	ActionUserPromoted,
+	EventCommentCreated,
+}
+
+// Event describes a change, as sent to webhook subscribers and event
+// streams. IDs increase with every event published by the process.
+type Event struct {
+	ID        int         `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Actor     string      `json:"actor"`
+	Data      interface{} `json:"data"`
+
+	// WorkspaceID, TaskID and ProjectID tell what an event is about, so that
+	// streams only pass it on to the users who can see it.
+	WorkspaceID int `json:"-"`
+	TaskID      int `json:"-"`
+	ProjectID   int `json:"-"`
+}
+
+// IsTaskEvent reports whether an event is about a change to a task.
+func (e Event) IsTaskEvent() bool {
+	return strings.HasPrefix(e.Type, "task.")
+}
@@ -0,0 +1,23 @@
+package Domain
+
+import "time"
+
+// Actions recorded in a task's revision history.
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionReverted = "reverted"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+)
+
+// TaskRevision is a snapshot of a task taken after each change.
+type TaskRevision struct {
+	TaskID    int                    `json:"task_id"`
+	Revision  int                    `json:"revision"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor"`
+	Timestamp time.Time              `json:"timestamp"`
+	Task      Task                   `json:"task"`
+	Changes   map[string]FieldChange `json:"changes,omitempty"`
+}
@@ -0,0 +1,39 @@
+package Domain
+
+// Task priorities, from most to least urgent. A task may also have no
+// priority.
+var Priorities = []string{"P0", "P1", "P2", "P3"}
+
+type Label struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TaskFilter narrows a task listing. Empty fields are ignored.
+type TaskFilter struct {
+	// Labels lists labels a task must all have.
+	Labels []string `json:"labels"`
+	// AnyLabels lists labels a task must have at least one of.
+	AnyLabels  []string `json:"any_labels"`
+	Priorities []string `json:"priority"`
+	Statuses   []string `json:"status"`
+	Assignees  []string `json:"assignee"`
+	// Overdue keeps only the tasks marked overdue.
+	Overdue bool `json:"overdue"`
+}
+
+// IsEmpty reports whether a filter matches every task.
+func (f TaskFilter) IsEmpty() bool {
+	return len(f.Labels) == 0 && len(f.AnyLabels) == 0 && len(f.Priorities) == 0 &&
+		len(f.Statuses) == 0 && len(f.Assignees) == 0 && !f.Overdue
+}
+
+// TaskFacets counts the tasks matching a filter per label, status and
+// priority.
+type TaskFacets struct {
+	Total    int            `json:"total"`
+	Labels   map[string]int `json:"labels"`
+	Status   map[string]int `json:"status"`
+	Priority map[string]int `json:"priority"`
+}
@@ -0,0 +1,56 @@
+package Domain
+
+import "time"
+
+// Roles a user can have within a project, from most to least privileged.
+const (
+	ProjectRoleOwner      = "owner"
+	ProjectRoleMaintainer = "maintainer"
+	ProjectRoleMember     = "member"
+	ProjectRoleViewer     = "viewer"
+)
+
+type Project struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Members     []ProjectMember `json:"members"`
+	Columns     []BoardColumn   `json:"columns"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type ProjectMember struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// BoardColumn is a column of a project's board. Each column holds the tasks
+// of the project that have its status.
+type BoardColumn struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// BoardColumnTasks is a board column along with its tasks, in board order.
+type BoardColumnTasks struct {
+	BoardColumn
+	Tasks []Task `json:"tasks"`
+}
+
+// DefaultColumns are the columns of a new project's board.
+var DefaultColumns = []BoardColumn{
+	{Name: "To Do", Status: "pending"},
+	{Name: "In Progress", Status: "in_progress"},
+	{Name: "Done", Status: StatusCompleted},
+}
+
+// RoleOf returns the role of the user in the project, or an empty string if
+// the user is not a member.
+func (p Project) RoleOf(username string) string {
+	for _, member := range p.Members {
+		if member.Username == username {
+			return member.Role
+		}
+	}
+	return ""
+}
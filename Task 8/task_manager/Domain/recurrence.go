@@ -0,0 +1,185 @@
+package Domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// WeekdayCodes are the RRULE codes of the days of the week, indexed by
+// time.Weekday.
+var WeekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence is a recurrence rule, the subset of an RFC 5545 RRULE made of
+// FREQ, INTERVAL, BYDAY, UNTIL and COUNT. Occurrences fall on dates; the time
+// of day of a due date is kept as it is.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	ByDay    []WeekdayNum
+	Until    time.Time
+	Count    int
+}
+
+// WeekdayNum is a day of the week in a BYDAY list. In monthly rules a
+// non-zero Ordinal picks the nth such day of the month, counting from the end
+// of the month when negative.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+func (d WeekdayNum) String() string {
+	if d.Ordinal != 0 {
+		return strconv.Itoa(d.Ordinal) + WeekdayCodes[d.Weekday]
+	}
+	return WeekdayCodes[d.Weekday]
+}
+
+// String formats the rule as the value of an RRULE property.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the date of the occurrence following the nth one, which falls
+// on the given date. It returns false when the series ends before.
+func (r Recurrence) Next(date time.Time, n int) (time.Time, bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = r.nextDaily(date)
+	case FreqWeekly:
+		next = r.nextWeekly(date)
+	case FreqMonthly:
+		next = r.nextMonthly(date)
+	}
+	if next.IsZero() || (!r.Until.IsZero() && next.After(r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r Recurrence) interval() int {
+	return max(r.Interval, 1)
+}
+
+func (r Recurrence) nextDaily(date time.Time) time.Time {
+	// The days of the week repeat every 7 days, so a day matching BYDAY
+	// comes within 7 steps if at all.
+	for step := 1; step <= 7; step++ {
+		next := date.AddDate(0, 0, step*r.interval())
+		if len(r.ByDay) == 0 || r.onWeekday(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (r Recurrence) nextWeekly(date time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return date.AddDate(0, 0, 7*r.interval())
+	}
+
+	// Weeks start on Monday.
+	monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	for next := date.AddDate(0, 0, 1); next.Before(monday.AddDate(0, 0, 7)); next = next.AddDate(0, 0, 1) {
+		if r.onWeekday(next) {
+			return next
+		}
+	}
+	week := monday.AddDate(0, 0, 7*r.interval())
+	for day := 0; day < 7; day++ {
+		if next := week.AddDate(0, 0, day); r.onWeekday(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (r Recurrence) nextMonthly(date time.Time) time.Time {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+
+	if len(r.ByDay) == 0 {
+		// Months too short for the day are skipped, as in RFC 5545.
+		for step := 1; step <= 48; step++ {
+			month := first.AddDate(0, step*r.interval(), 0)
+			if date.Day() <= daysIn(month) {
+				return month.AddDate(0, 0, date.Day()-1)
+			}
+		}
+		return time.Time{}
+	}
+
+	for next := date.AddDate(0, 0, 1); next.Month() == date.Month(); next = next.AddDate(0, 0, 1) {
+		if r.onMonthDay(next) {
+			return next
+		}
+	}
+	// A fifth weekday of the month does not occur in every month.
+	for step := 1; step <= 48; step++ {
+		month := first.AddDate(0, step*r.interval(), 0)
+		for day := 0; day < daysIn(month); day++ {
+			if next := month.AddDate(0, 0, day); r.onMonthDay(next) {
+				return next
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (r Recurrence) onWeekday(date time.Time) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Recurrence) onMonthDay(date time.Time) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday != date.Weekday() {
+			continue
+		}
+		switch {
+		case day.Ordinal == 0,
+			day.Ordinal > 0 && (date.Day()-1)/7+1 == day.Ordinal,
+			day.Ordinal < 0 && (daysIn(date)-date.Day())/7+1 == -day.Ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn returns the number of days in the month of the given date.
+func daysIn(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+}
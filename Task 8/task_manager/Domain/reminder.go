@@ -0,0 +1,31 @@
+package Domain
+
+import "time"
+
+// Reminder kinds.
+const (
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+)
+
+// Reminder records a reminder sent about a task. There is at most one per
+// task, kind, lead time and due date, so that each is sent once.
+type Reminder struct {
+	ID          int       `json:"id"`
+	TaskID      int       `json:"task_id"`
+	Kind        string    `json:"kind"`
+	LeadMinutes int       `json:"lead_minutes"`
+	DueDate     string    `json:"due_date"`
+	Recipient   string    `json:"recipient"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// Notification is a message for a user, handed over to a notifier.
+type Notification struct {
+	Kind      string `json:"kind"`
+	Recipient string `json:"recipient"`
+	Email     string `json:"email,omitempty"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	Task      Task   `json:"task"`
+}
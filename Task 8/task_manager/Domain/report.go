@@ -0,0 +1,72 @@
+package Domain
+
+import "time"
+
+// Time buckets of throughput reports.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// ReportFilter narrows a report. Zero values are ignored.
+type ReportFilter struct {
+	ProjectID int
+	// From and To bound the time tasks were created or completed at, To
+	// excluded.
+	From time.Time
+	To   time.Time
+	// Interval is the size of the time buckets of a throughput report.
+	Interval string
+}
+
+// ThroughputBucket counts the tasks created and completed in a period: a UTC
+// day (2024-08-20), ISO week (2024-W34) or month (2024-08).
+type ThroughputBucket struct {
+	Period    string `json:"period"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// TaskDurations are the lead and cycle times of completed tasks, in hours.
+// Tasks completed without ever being started have no cycle time.
+type TaskDurations struct {
+	LeadTimes  []float64
+	CycleTimes []float64
+}
+
+// Percentiles summarises a set of durations, in hours.
+type Percentiles struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	Max   float64 `json:"max"`
+}
+
+// CycleTimeReport summarises how long completed tasks took: from creation to
+// completion (lead time) and from start to completion (cycle time).
+type CycleTimeReport struct {
+	LeadTime  Percentiles `json:"lead_time_hours"`
+	CycleTime Percentiles `json:"cycle_time_hours"`
+}
+
+// OverdueReport counts the tasks marked overdue.
+type OverdueReport struct {
+	Total      int            `json:"total"`
+	ByAssignee map[string]int `json:"by_assignee"`
+	ByPriority map[string]int `json:"by_priority"`
+}
+
+// Workload is the open work assigned to a user. Unassigned tasks have an
+// empty assignee.
+type Workload struct {
+	Assignee        string  `json:"assignee"`
+	Open            int     `json:"open"`
+	InProgress      int     `json:"in_progress"`
+	Overdue         int     `json:"overdue"`
+	StoryPoints     float64 `json:"story_points"`
+	EstimateMinutes int     `json:"estimate_minutes"`
+}
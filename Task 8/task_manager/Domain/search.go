@@ -0,0 +1,117 @@
+package Domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kinds of documents returned by a search.
+const (
+	SearchTask    = "task"
+	SearchComment = "comment"
+)
+
+// SearchQuery is a parsed search query. Words are matched anywhere in the
+// indexed text; a document matches if it contains at least one word or
+// prefix and every phrase. Qualifiers restrict the results to tasks, or
+// comments on tasks, with the given attributes; alone, they match every task
+// with those attributes.
+type SearchQuery struct {
+	Terms    []string
+	Phrases  []string
+	Prefixes []string
+
+	Status   string
+	Assignee string
+	Label    string
+	Priority string
+	Due      []DueCondition
+}
+
+// DueCondition compares the due date of a task, as YYYY-MM-DD, with Date.
+// Op is one of <, <=, >, >= or =.
+type DueCondition struct {
+	Op   string
+	Date string
+}
+
+type SearchHit struct {
+	Type       string            `json:"type"`
+	TaskID     int               `json:"task_id"`
+	CommentID  int               `json:"comment_id,omitempty"`
+	Title      string            `json:"title"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+
+	// Fields holds the indexed text of the document, by field name, for
+	// highlighting.
+	Fields map[string]string `json:"-"`
+}
+
+// HasText reports whether the query has words, phrases or prefixes to search
+// for. A query without them only filters tasks by their attributes.
+func (q SearchQuery) HasText() bool {
+	return len(q.Terms) > 0 || len(q.Phrases) > 0 || len(q.Prefixes) > 0
+}
+
+// HasQualifiers reports whether the query restricts the results by task
+// attributes.
+func (q SearchQuery) HasQualifiers() bool {
+	return q.Status != "" || q.Assignee != "" || q.Label != "" || q.Priority != "" || len(q.Due) > 0
+}
+
+// MatchesTask reports whether a task satisfies the qualifiers of the query.
+func (q SearchQuery) MatchesTask(task Task) bool {
+	if q.Status != "" && !strings.EqualFold(task.Status, q.Status) {
+		return false
+	}
+	if q.Assignee != "" && task.Assignee != q.Assignee {
+		return false
+	}
+	if q.Priority != "" && !strings.EqualFold(task.Priority, q.Priority) {
+		return false
+	}
+	if q.Label != "" {
+		found := false
+		for _, label := range task.Labels {
+			if label == q.Label {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, due := range q.Due {
+		if task.DueDate == "" || !due.Matches(task.DueDate) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether a due date satisfies the condition.
+func (d DueCondition) Matches(dueDate string) bool {
+	if len(dueDate) > len(d.Date) {
+		dueDate = dueDate[:len(d.Date)]
+	}
+	switch d.Op {
+	case "<":
+		return dueDate < d.Date
+	case "<=":
+		return dueDate <= d.Date
+	case ">":
+		return dueDate > d.Date
+	case ">=":
+		return dueDate >= d.Date
+	default:
+		return dueDate == d.Date
+	}
+}
+
+// Tokenize splits text into lowercase words made of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
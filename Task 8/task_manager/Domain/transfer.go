@@ -0,0 +1,46 @@
+package Domain
+
+// Formats tasks are exported and imported in.
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatICS   = "ics"
+)
+
+// ImportOptions tell how to read and apply an import.
+type ImportOptions struct {
+	Format string
+	// Mapping maps the columns or keys of the import to task fields, by
+	// their JSON names. Columns named after a task field need no mapping.
+	Mapping map[string]string
+	DryRun  bool
+	Atomic  bool
+}
+
+// ImportReport tells what an import did, or would do in a dry run, row by
+// row.
+type ImportReport struct {
+	DryRun  bool `json:"dry_run"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Failed  int  `json:"failed"`
+	// Ignored lists the columns that do not map to a task field that can
+	// be imported.
+	Ignored []string    `json:"ignored,omitempty"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow is the outcome of a row of an import. Rows are numbered from 1,
+// not counting the CSV header.
+type ImportRow struct {
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Op         string `json:"op,omitempty"`
+	TaskID     int    `json:"task_id,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	// Before and Task are the task before and after the row was applied.
+	Before *Task `json:"-"`
+	Task   *Task `json:"-"`
+}
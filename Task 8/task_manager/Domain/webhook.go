@@ -0,0 +1,55 @@
+package Domain
+
+import "time"
+
+// Delivery statuses. A pending delivery is waiting for its next attempt; a
+// dead one has failed too many times and is no longer tried.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type.
+const WebhookAllEvents = "*"
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribes reports whether the webhook is to be sent events of the given
+// type.
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == eventType || event == WebhookAllEvents {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an event queued for a webhook, along with the attempts
+// made to deliver it.
+type WebhookDelivery struct {
+	ID            int               `json:"id"`
+	WebhookID     int               `json:"webhook_id"`
+	Event         string            `json:"event"`
+	Payload       string            `json:"payload"`
+	Status        string            `json:"status"`
+	Attempts      []DeliveryAttempt `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	RedeliveryOf  int               `json:"redelivery_of,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type DeliveryAttempt struct {
+	Timestamp  time.Time `json:"timestamp"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
@@ -0,0 +1,64 @@
+package Domain
+
+import "time"
+
+// Worklog is time a user spent on a task, logged by hand or with a timer. A
+// running timer is a worklog that has not ended yet.
+type Worklog struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	User      string     `json:"user"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Minutes   int        `json:"minutes"`
+	Note      string     `json:"note"`
+	Running   bool       `json:"running"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Keys worklog totals can be grouped by.
+const (
+	GroupByTask = "task"
+	GroupByUser = "user"
+	GroupByDay  = "day"
+)
+
+// WorklogFilter narrows a worklog query. Zero values are ignored.
+type WorklogFilter struct {
+	TaskIDs []int
+	User    string
+	// From and To bound the time worklogs started at, To excluded.
+	From time.Time
+	To   time.Time
+}
+
+// TimeTotal is the time logged in one group of worklogs. Only the keys the
+// worklogs were grouped by are set; Day is a UTC date.
+type TimeTotal struct {
+	TaskID  int    `json:"task_id,omitempty"`
+	User    string `json:"user,omitempty"`
+	Day     string `json:"day,omitempty"`
+	Minutes int    `json:"minutes"`
+	Entries int    `json:"entries"`
+}
+
+// TimeReport compares the time logged on tasks with their estimates.
+type TimeReport struct {
+	EstimateMinutes int             `json:"estimate_minutes"`
+	LoggedMinutes   int             `json:"logged_minutes"`
+	Tasks           []TaskTimeUsage `json:"tasks"`
+}
+
+// TaskTimeUsage is the time logged on a task against its estimate.
+// Variance is the time logged beyond the estimate, negative while under it.
+type TaskTimeUsage struct {
+	TaskID          int            `json:"task_id"`
+	Title           string         `json:"title"`
+	Status          string         `json:"status"`
+	Assignee        string         `json:"assignee"`
+	EstimateMinutes int            `json:"estimate_minutes"`
+	LoggedMinutes   int            `json:"logged_minutes"`
+	VarianceMinutes int            `json:"variance_minutes"`
+	OverEstimate    bool           `json:"over_estimate"`
+	Users           map[string]int `json:"users"`
+}
@@ -0,0 +1,37 @@
+package Domain
+
+import "time"
+
+// Roles a user can have within a workspace.
+const (
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// DefaultWorkspaceID is the workspace of the data created before there were
+// workspaces, and of the first user to register.
+const DefaultWorkspaceID = 1
+
+// Workspace is an organization with its own tasks, projects, labels,
+// webhooks and audit log. Users see the data of the workspace their token is
+// for, and nothing of the others.
+type Workspace struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership makes a user part of a workspace, with a role there.
+type Membership struct {
+	WorkspaceID int       `json:"workspace_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// UserWorkspace is a workspace along with the role a user has in it.
+type UserWorkspace struct {
+	Workspace
+	Role string `json:"role"`
+}
@@ -0,0 +1,21 @@
+package Infrastructure
+
+import (
+	"task_manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewAuditEntry fills in the request metadata of an audit entry: the
+// authenticated user, the client address, the user agent and the request id.
+func NewAuditEntry(c *gin.Context, action, targetType string, targetID int) Domain.AuditEntry {
+	return Domain.AuditEntry{
+		Actor:      c.GetString("username"),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		RequestID:  c.GetString("request_id"),
+	}
+}
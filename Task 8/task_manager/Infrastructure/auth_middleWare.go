@@ -1,7 +1,7 @@
 package Infrastructure
 
 import (
-	"fmt"
+	"log/slog"
 	"strings"
 	"task_manager/Domain"
 	"task_manager/Usecases"
@@ -11,44 +11,111 @@ import (
 )
 
 var userservice Usecases.IUserService
+var auditservice Usecases.IAuditService
+var workspaceservice Usecases.IWorkspaceService
+
+// UseDatabase sets the instance database the middleware looks the
+// memberships up in.
+func UseDatabase(dbName string) {
+	workspaceservice = Usecases.NewWorkspaceService(dbName)
+}
+
+// loginRequest is the body of a login. WorkspaceID picks the workspace the
+// token is for; without it, the token is for the user's oldest workspace.
+type loginRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	WorkspaceID int    `json:"workspace_id"`
+}
 
 func Login(dbName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var user Domain.User
+		var user loginRequest
 		userservice = Usecases.NewUserService(dbName)
+		auditservice = Usecases.NewAuditService(dbName)
 
 		if err := c.ShouldBindJSON(&user); err != nil {
 			c.JSON(400, gin.H{"error": "Invalid payload request"})
 			return
 		}
 
-		existingUser, err := userservice.GetUserbyUsername(user.Username)
+		existingUser, err := userservice.WithContext(c.Request.Context()).GetUserbyUsername(user.Username)
 		if err != nil {
+			recordLogin(c, Domain.ActionUserLoginFailed, user.Username, 0, Domain.DefaultWorkspaceID)
+			countAuthFailure("unknown_user")
 			c.JSON(400, gin.H{"error": err.Error()})
 			return
 		}
 
-		fmt.Println(existingUser.Username, existingUser.Role, existingUser.Password)
+		// Failed logins go to the audit log of the user's default workspace.
+		member, memberErr := workspaceservice.WithContext(c.Request.Context()).GetDefaultMembership(user.Username)
+		if memberErr != nil {
+			member.WorkspaceID = Domain.DefaultWorkspaceID
+		}
 
 		if err := ComparePasswords(existingUser.Password, user.Password); err != nil {
+			recordLogin(c, Domain.ActionUserLoginFailed, user.Username, existingUser.ID, member.WorkspaceID)
+			countAuthFailure("wrong_password")
 			c.JSON(400, gin.H{"error": "Wrong Password"})
 			return
 		}
 
-		signedToken, err := GenerateToken(user.Username, existingUser.Role)
+		if user.WorkspaceID != 0 {
+			member, memberErr = workspaceservice.WithContext(c.Request.Context()).GetMembership(user.WorkspaceID, user.Username)
+		}
+		if memberErr != nil {
+			countAuthFailure("not_member")
+			c.JSON(403, gin.H{"error": memberErr.Error()})
+			return
+		}
+
+		signedToken, err := GenerateToken(user.Username, member.WorkspaceID)
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Failed to generate token"})
 			return
 		}
 
-		c.JSON(200, gin.H{"message": "Successfully logged in", "token": signedToken})
+		recordLogin(c, Domain.ActionUserLogin, user.Username, existingUser.ID, member.WorkspaceID)
+		c.JSON(200, gin.H{"message": "Successfully logged in", "token": signedToken, "workspace_id": member.WorkspaceID})
+	}
+}
+
+// recordLogin records a login in the audit log of the workspace it is for.
+func recordLogin(c *gin.Context, action, username string, userID, workspaceID int) {
+	entry := NewAuditEntry(c, action, "user", userID)
+	entry.Actor = username
+	if err := auditservice.In(workspaceID).WithContext(c.Request.Context()).Record(entry); err != nil {
+		slog.ErrorContext(c.Request.Context(), "audit entry not recorded", "action", action, "target_type", "user", "target_id", userID, "error", err)
+	}
+}
+
+// setClaims exposes the token claims to the handlers further down the chain,
+// along with the role the user has in the token's workspace. Tokens from
+// before workspaces are for the default workspace. It fails when the user is
+// not a member of the workspace, or no longer is.
+func setClaims(c *gin.Context, token *jwt.Token) error {
+	claims := token.Claims.(jwt.MapClaims)
+	username, _ := claims["username"].(string)
+	workspaceID := Domain.DefaultWorkspaceID
+	if id, ok := claims["workspace"].(float64); ok {
+		workspaceID = int(id)
+	}
+
+	member, err := workspaceservice.WithContext(c.Request.Context()).GetMembership(workspaceID, username)
+	if err != nil {
+		return err
 	}
+	c.Set("username", username)
+	c.Set("workspace", workspaceID)
+	c.Set("role", member.Role)
+	return nil
 }
 
 func Logged(c *gin.Context) {
 
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
+		countAuthFailure("missing_token")
 		c.JSON(401, gin.H{"error": "Authorization header is required"})
 		c.Abort()
 		return
@@ -56,6 +123,7 @@ func Logged(c *gin.Context) {
 
 	authParts := strings.Split(authHeader, " ")
 	if len(authParts) != 2 || authParts[0] != "Bearer" {
+		countAuthFailure("invalid_token")
 		c.JSON(401, gin.H{"error": "Invalid authorization header"})
 		c.Abort()
 		return
@@ -63,17 +131,25 @@ func Logged(c *gin.Context) {
 
 	token, err := ValidateToken(authParts[1])
 	if err != nil || !token.Valid {
+		countAuthFailure("invalid_token")
 		c.JSON(401, gin.H{"error": "Invalid token"})
 		c.Abort()
 		return
 	}
 
+	if err := setClaims(c, token); err != nil {
+		countAuthFailure("not_member")
+		c.JSON(403, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
 	c.Next()
 }
 
 func Admin(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
+		countAuthFailure("missing_token")
 		c.JSON(401, gin.H{"error": "Authorization header is required"})
 		c.Abort()
 		return
@@ -81,6 +157,7 @@ func Admin(c *gin.Context) {
 
 	authParts := strings.Split(authHeader, " ")
 	if len(authParts) != 2 || authParts[0] != "Bearer" {
+		countAuthFailure("invalid_token")
 		c.JSON(401, gin.H{"error": "Invalid authorization header"})
 		c.Abort()
 		return
@@ -88,12 +165,21 @@ func Admin(c *gin.Context) {
 
 	token, err := ValidateToken(authParts[1])
 	if err != nil || !token.Valid {
+		countAuthFailure("invalid_token")
 		c.JSON(401, gin.H{"error": "Invalid token"})
 		c.Abort()
 		return
 	}
 
-	if token.Claims.(jwt.MapClaims)["role"] != "admin" {
+	if err := setClaims(c, token); err != nil {
+		countAuthFailure("not_member")
+		c.JSON(403, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	if c.GetString("role") != Domain.WorkspaceRoleAdmin {
+		countAuthFailure("not_admin")
 		c.JSON(403, gin.H{"error": "Unauthorized"})
 		c.Abort()
 		return
@@ -101,3 +187,14 @@ func Admin(c *gin.Context) {
 
 	c.Next()
 }
+
+// QueryToken takes the token from the access_token query parameter when the
+// request has no Authorization header, for the clients that cannot set one,
+// such as browser EventSource and WebSocket connections. It goes before
+// Logged or Admin.
+func QueryToken(c *gin.Context) {
+	if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	c.Next()
+}
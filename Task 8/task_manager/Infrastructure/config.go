@@ -0,0 +1,675 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"task_manager/Usecases"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the service. It is read from a YAML or TOML
+// file, then from environment variables, then from command line flags, each
+// overriding the previous ones; see LoadConfig.
+type Config struct {
+	Mongo       MongoConfig      `yaml:"mongo" toml:"mongo"`
+	Server      ServerConfig     `yaml:"server" toml:"server"`
+	Auth        AuthConfig       `yaml:"auth" toml:"auth"`
+	Tasks       TaskConfig       `yaml:"tasks" toml:"tasks"`
+	Search      BackendConfig    `yaml:"search" toml:"search"`
+	Reports     BackendConfig    `yaml:"reports" toml:"reports"`
+	Reminders   ReminderConfig   `yaml:"reminders" toml:"reminders"`
+	Notifier    NotifierConfig   `yaml:"notifier" toml:"notifier"`
+	Attachments AttachmentConfig `yaml:"attachments" toml:"attachments"`
+	Log         LogConfig        `yaml:"log" toml:"log"`
+	Tracing     TracingConfig    `yaml:"tracing" toml:"tracing"`
+	RateLimit   RateLimitConfig  `yaml:"rate_limit" toml:"rate_limit"`
+	API         APIConfig        `yaml:"api" toml:"api"`
+}
+
+type MongoConfig struct {
+	URI            string   `yaml:"uri" toml:"uri"`
+	Database       string   `yaml:"database" toml:"database"`
+	ConnectTimeout Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+}
+
+// ServerConfig sets the address the server listens on and its timeouts. A
+// read, write or idle timeout of 0 means none; event streams are exempt
+// from the write timeout. The client address of requests is taken from
+// their X-Forwarded-For header only when they come from a trusted proxy, an
+// IP address or a CIDR range. Browsers may open WebSockets from the
+// service's own origin and from AllowedOrigins, such as
+// https://app.example.com.
+type ServerConfig struct {
+	Addr            string   `yaml:"addr" toml:"addr"`
+	TrustedProxies  []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	AllowedOrigins  []string `yaml:"allowed_origins" toml:"allowed_origins"`
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout     Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+type AuthConfig struct {
+	JWTSecret  string `yaml:"jwt_secret" toml:"jwt_secret"`
+	BcryptCost int    `yaml:"bcrypt_cost" toml:"bcrypt_cost"`
+}
+
+type TaskConfig struct {
+	TrashRetention     Duration `yaml:"trash_retention" toml:"trash_retention"`
+	RecurrenceInterval Duration `yaml:"recurrence_interval" toml:"recurrence_interval"`
+}
+
+// BackendConfig picks where searches or reports are computed: mongo or
+// memory.
+type BackendConfig struct {
+	Backend string `yaml:"backend" toml:"backend"`
+}
+
+type ReminderConfig struct {
+	Interval  Duration   `yaml:"interval" toml:"interval"`
+	LeadTimes []Duration `yaml:"lead_times" toml:"lead_times"`
+}
+
+// NotifierConfig picks how reminders are sent: log, webhook, smtp or mailbox.
+type NotifierConfig struct {
+	Type       string     `yaml:"type" toml:"type"`
+	WebhookURL string     `yaml:"webhook_url" toml:"webhook_url"`
+	MailboxDir string     `yaml:"mailbox_dir" toml:"mailbox_dir"`
+	SMTP       SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Addr     string `yaml:"addr" toml:"addr"`
+	From     string `yaml:"from" toml:"from"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// AttachmentConfig picks where attachments are kept: local or s3.
+type AttachmentConfig struct {
+	MaxBytes int64    `yaml:"max_bytes" toml:"max_bytes"`
+	Store    string   `yaml:"store" toml:"store"`
+	Dir      string   `yaml:"dir" toml:"dir"`
+	S3       S3Config `yaml:"s3" toml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" toml:"endpoint"`
+	Bucket    string `yaml:"bucket" toml:"bucket"`
+	Region    string `yaml:"region" toml:"region"`
+	AccessKey string `yaml:"access_key" toml:"access_key"`
+	SecretKey string `yaml:"secret_key" toml:"secret_key"`
+	PathStyle bool   `yaml:"path_style" toml:"path_style"`
+}
+
+// LogConfig sets the lowest level logged: debug, info, warn or error.
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level"`
+}
+
+// SlogLevel returns the level logged, info when it is not valid.
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.Level))
+	return level
+}
+
+// TracingConfig sets where the spans are exported to over OTLP/HTTP, and
+// which share of the traces is recorded. Without an endpoint, tracing is
+// off.
+type TracingConfig struct {
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// RateLimitConfig sets the rate limit policies and where their token
+// buckets are kept: memory, for a single instance, or mongo, shared by all
+// instances.
+type RateLimitConfig struct {
+	Enabled  bool              `yaml:"enabled" toml:"enabled"`
+	Store    string            `yaml:"store" toml:"store"`
+	Policies []RateLimitPolicy `yaml:"policies" toml:"policies"`
+}
+
+// APIConfig sets how the unversioned routes, aliases of the /v1 ones kept
+// for the clients written before the API had versions, are served: whether
+// they are, since when they are deprecated and, if decided, when they stop
+// being served.
+type APIConfig struct {
+	LegacyRoutes     bool `yaml:"legacy_routes" toml:"legacy_routes"`
+	LegacyDeprecated Date `yaml:"legacy_deprecated" toml:"legacy_deprecated"`
+	LegacySunset     Date `yaml:"legacy_sunset" toml:"legacy_sunset"`
+}
+
+// Duration is a time.Duration written as a Go duration, such as "15m", in
+// configuration files.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", text)
+	}
+	d.Duration = duration
+	return nil
+}
+
+// Date is a day, written as 2006-01-02 in configuration files, or left
+// empty.
+type Date struct {
+	time.Time
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		d.Time = time.Time{}
+		return nil
+	}
+	date, err := time.Parse(time.DateOnly, string(text))
+	if err != nil {
+		return fmt.Errorf("invalid date %q, use YYYY-MM-DD", text)
+	}
+	d.Time = date
+	return nil
+}
+
+// DefaultJWTSecret is the key tokens are signed with when none is configured.
+// It is public, so every deployment should set its own.
+const DefaultJWTSecret = "shhhh... it's a secret"
+
+// DefaultConfig returns the configuration used for what is not configured.
+func DefaultConfig() Config {
+	leadTimes := []Duration{}
+	for _, lead := range Usecases.ReminderLeadTimes {
+		leadTimes = append(leadTimes, Duration{lead})
+	}
+
+	return Config{
+		Mongo: MongoConfig{
+			URI:            "mongodb://localhost:27017/",
+			Database:       "task_manager",
+			ConnectTimeout: Duration{time.Minute},
+		},
+		Server: ServerConfig{
+			Addr:            "localhost:8080",
+			ReadTimeout:     Duration{time.Minute},
+			WriteTimeout:    Duration{2 * time.Minute},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Auth: AuthConfig{JWTSecret: DefaultJWTSecret, BcryptCost: bcrypt.DefaultCost},
+		Tasks: TaskConfig{
+			TrashRetention:     Duration{Usecases.TrashRetention},
+			RecurrenceInterval: Duration{Usecases.RecurrenceInterval},
+		},
+		Search:    BackendConfig{Backend: "mongo"},
+		Reports:   BackendConfig{Backend: "mongo"},
+		Reminders: ReminderConfig{Interval: Duration{Usecases.ReminderInterval}, LeadTimes: leadTimes},
+		Notifier: NotifierConfig{
+			Type:       "log",
+			MailboxDir: "mailbox",
+			SMTP:       SMTPConfig{From: "task-manager@localhost"},
+		},
+		Attachments: AttachmentConfig{
+			MaxBytes: Usecases.AttachmentMaxSize,
+			Store:    "local",
+			Dir:      "attachments",
+			S3:       S3Config{Region: "us-east-1", PathStyle: true},
+		},
+		Log:     LogConfig{Level: "info"},
+		Tracing: TracingConfig{ServiceName: "task_manager", SampleRatio: 1},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "memory",
+			Policies: []RateLimitPolicy{
+				{Name: "auth", Routes: []string{"POST /login", "POST /register"}, Key: "ip", Limit: 10, Period: Duration{time.Minute}},
+				{Name: "default", Routes: []string{"*"}, Key: "user", Limit: 300, Period: Duration{time.Minute}},
+			},
+		},
+		API: APIConfig{
+			LegacyRoutes:     true,
+			LegacyDeprecated: Date{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+// setting is a configuration value that can be set from an environment
+// variable and from the command line flag named after its key.
+type setting struct {
+	key    string
+	env    string
+	secret bool
+	value  flag.Value
+}
+
+// flagName is the command line flag of a setting: --mongo-uri for
+// mongo.uri.
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "mongo.uri", env: "MONGO_URI", secret: true, value: (*stringValue)(&c.Mongo.URI)},
+		{key: "mongo.database", env: "MONGO_DATABASE", value: (*stringValue)(&c.Mongo.Database)},
+		{key: "mongo.connect_timeout", env: "MONGO_CONNECT_TIMEOUT", value: &c.Mongo.ConnectTimeout},
+		{key: "server.addr", env: "LISTEN_ADDR", value: (*stringValue)(&c.Server.Addr)},
+		{key: "server.trusted_proxies", env: "TRUSTED_PROXIES", value: (*stringsValue)(&c.Server.TrustedProxies)},
+		{key: "server.allowed_origins", env: "ALLOWED_ORIGINS", value: (*stringsValue)(&c.Server.AllowedOrigins)},
+		{key: "server.read_timeout", env: "HTTP_READ_TIMEOUT", value: &c.Server.ReadTimeout},
+		{key: "server.write_timeout", env: "HTTP_WRITE_TIMEOUT", value: &c.Server.WriteTimeout},
+		{key: "server.idle_timeout", env: "HTTP_IDLE_TIMEOUT", value: &c.Server.IdleTimeout},
+		{key: "server.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", value: &c.Server.ShutdownTimeout},
+		{key: "auth.jwt_secret", env: "JWT_SECRET", secret: true, value: (*stringValue)(&c.Auth.JWTSecret)},
+		{key: "auth.bcrypt_cost", env: "BCRYPT_COST", value: (*intValue)(&c.Auth.BcryptCost)},
+		{key: "tasks.trash_retention", env: "TRASH_RETENTION", value: &c.Tasks.TrashRetention},
+		{key: "tasks.recurrence_interval", env: "RECURRENCE_INTERVAL", value: &c.Tasks.RecurrenceInterval},
+		{key: "search.backend", env: "SEARCH_BACKEND", value: (*stringValue)(&c.Search.Backend)},
+		{key: "reports.backend", env: "REPORT_BACKEND", value: (*stringValue)(&c.Reports.Backend)},
+		{key: "reminders.interval", env: "REMINDER_INTERVAL", value: &c.Reminders.Interval},
+		{key: "reminders.lead_times", env: "REMINDER_LEAD_TIMES", value: (*durationsValue)(&c.Reminders.LeadTimes)},
+		{key: "notifier.type", env: "NOTIFIER", value: (*stringValue)(&c.Notifier.Type)},
+		{key: "notifier.webhook_url", env: "NOTIFIER_WEBHOOK_URL", value: (*stringValue)(&c.Notifier.WebhookURL)},
+		{key: "notifier.mailbox_dir", env: "MAILBOX_DIR", value: (*stringValue)(&c.Notifier.MailboxDir)},
+		{key: "notifier.smtp.addr", env: "SMTP_ADDR", value: (*stringValue)(&c.Notifier.SMTP.Addr)},
+		{key: "notifier.smtp.from", env: "SMTP_FROM", value: (*stringValue)(&c.Notifier.SMTP.From)},
+		{key: "notifier.smtp.username", env: "SMTP_USERNAME", value: (*stringValue)(&c.Notifier.SMTP.Username)},
+		{key: "notifier.smtp.password", env: "SMTP_PASSWORD", secret: true, value: (*stringValue)(&c.Notifier.SMTP.Password)},
+		{key: "attachments.max_bytes", env: "ATTACHMENT_MAX_BYTES", value: (*int64Value)(&c.Attachments.MaxBytes)},
+		{key: "attachments.store", env: "BLOB_STORE", value: (*stringValue)(&c.Attachments.Store)},
+		{key: "attachments.dir", env: "BLOB_DIR", value: (*stringValue)(&c.Attachments.Dir)},
+		{key: "attachments.s3.endpoint", env: "S3_ENDPOINT", value: (*stringValue)(&c.Attachments.S3.Endpoint)},
+		{key: "attachments.s3.bucket", env: "S3_BUCKET", value: (*stringValue)(&c.Attachments.S3.Bucket)},
+		{key: "attachments.s3.region", env: "S3_REGION", value: (*stringValue)(&c.Attachments.S3.Region)},
+		{key: "attachments.s3.access_key", env: "S3_ACCESS_KEY", value: (*stringValue)(&c.Attachments.S3.AccessKey)},
+		{key: "attachments.s3.secret_key", env: "S3_SECRET_KEY", secret: true, value: (*stringValue)(&c.Attachments.S3.SecretKey)},
+		{key: "attachments.s3.path_style", env: "S3_PATH_STYLE", value: (*boolValue)(&c.Attachments.S3.PathStyle)},
+		{key: "log.level", env: "LOG_LEVEL", value: (*stringValue)(&c.Log.Level)},
+		{key: "tracing.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", value: (*stringValue)(&c.Tracing.Endpoint)},
+		{key: "tracing.service_name", env: "OTEL_SERVICE_NAME", value: (*stringValue)(&c.Tracing.ServiceName)},
+		{key: "tracing.sample_ratio", env: "TRACE_SAMPLE_RATIO", value: (*float64Value)(&c.Tracing.SampleRatio)},
+		{key: "rate_limit.enabled", env: "RATE_LIMIT_ENABLED", value: (*boolValue)(&c.RateLimit.Enabled)},
+		{key: "rate_limit.store", env: "RATE_LIMIT_STORE", value: (*stringValue)(&c.RateLimit.Store)},
+		{key: "api.legacy_routes", env: "API_LEGACY_ROUTES", value: (*boolValue)(&c.API.LegacyRoutes)},
+		{key: "api.legacy_deprecated", env: "API_LEGACY_DEPRECATED", value: &c.API.LegacyDeprecated},
+		{key: "api.legacy_sunset", env: "API_LEGACY_SUNSET", value: &c.API.LegacySunset},
+	}
+}
+
+// LoadConfig builds the configuration from the defaults, then the file given
+// with --config or in CONFIG_FILE, then the environment variables, then the
+// command line flags, and validates it. It also reports whether
+// --print-config was given.
+func LoadConfig(args []string) (Config, bool, error) {
+	config := DefaultConfig()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("task_manager", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "read the configuration from this YAML or TOML `file`")
+	print := flags.Bool("print-config", false, "print the configuration, with secrets redacted, and exit")
+	given := map[string]string{}
+	for _, s := range settings {
+		name := s.flagName()
+		flags.Func(name, fmt.Sprintf("set %s (environment variable %s)", s.key, s.env), func(value string) error {
+			given[name] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return config, false, err
+	}
+
+	if *file != "" {
+		if err := config.readFile(*file); err != nil {
+			return config, *print, err
+		}
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.value.Set(value); err != nil {
+				return config, *print, fmt.Errorf("environment variable %s: %v", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := given[s.flagName()]; ok {
+			if err := s.value.Set(value); err != nil {
+				return config, *print, fmt.Errorf("flag --%s: %v", s.flagName(), err)
+			}
+		}
+	}
+
+	return config, *print, config.Validate()
+}
+
+// readFile reads a configuration file over the configuration, as YAML or
+// TOML depending on its extension. Unknown keys are refused, so that typos
+// do not go unnoticed.
+func (c *Config) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(c)
+		if err == io.EOF {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	default:
+		return fmt.Errorf("config file %s: unknown format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate checks the configuration, reporting every problem found at once.
+func (c Config) Validate() error {
+	problems := []string{}
+	check := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	uri, err := url.Parse(c.Mongo.URI)
+	check(err == nil && (uri.Scheme == "mongodb" || uri.Scheme == "mongodb+srv"), "mongo.uri", "must be a mongodb:// or mongodb+srv:// URI")
+	check(c.Mongo.Database != "" && !strings.ContainsAny(c.Mongo.Database, "/\\. \"$"), "mongo.database", "must be a database name, without /\\. \"$")
+	check(c.Mongo.ConnectTimeout.Duration > 0, "mongo.connect_timeout", "must be positive")
+	_, _, err = net.SplitHostPort(c.Server.Addr)
+	check(err == nil, "server.addr", "must be a host:port address, such as localhost:8080")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "server.trusted_proxies", "must be IP addresses or CIDR ranges, not %q", proxy)
+	}
+	for _, origin := range c.Server.AllowedOrigins {
+		parsed, err := url.Parse(origin)
+		check(err == nil && parsed.Scheme != "" && parsed.Host != "" && parsed.Path == "", "server.allowed_origins", "must be origins such as https://app.example.com, not %q", origin)
+	}
+	check(c.Server.ReadTimeout.Duration >= 0, "server.read_timeout", "cannot be negative")
+	check(c.Server.WriteTimeout.Duration >= 0, "server.write_timeout", "cannot be negative")
+	check(c.Server.IdleTimeout.Duration >= 0, "server.idle_timeout", "cannot be negative")
+	check(c.Server.ShutdownTimeout.Duration > 0, "server.shutdown_timeout", "must be positive")
+	check(len(c.Auth.JWTSecret) >= 16, "auth.jwt_secret", "must be at least 16 characters long")
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost, "auth.bcrypt_cost", "must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+
+	check(c.Tasks.TrashRetention.Duration >= 0, "tasks.trash_retention", "cannot be negative")
+	check(c.Tasks.RecurrenceInterval.Duration > 0, "tasks.recurrence_interval", "must be positive")
+	check(c.Search.Backend == "mongo" || c.Search.Backend == "memory", "search.backend", "must be mongo or memory")
+	check(c.Reports.Backend == "mongo" || c.Reports.Backend == "memory", "reports.backend", "must be mongo or memory")
+	check(c.Reminders.Interval.Duration > 0, "reminders.interval", "must be positive")
+	for _, lead := range c.Reminders.LeadTimes {
+		check(lead.Duration > 0, "reminders.lead_times", "must be positive, not %s", lead)
+	}
+
+	switch c.Notifier.Type {
+	case "log":
+	case "webhook":
+		check(c.Notifier.WebhookURL != "", "notifier.webhook_url", "is required by the webhook notifier")
+	case "smtp":
+		check(c.Notifier.SMTP.Addr != "", "notifier.smtp.addr", "is required by the smtp notifier")
+	case "mailbox":
+		check(c.Notifier.MailboxDir != "", "notifier.mailbox_dir", "is required by the mailbox notifier")
+	default:
+		check(false, "notifier.type", "must be log, webhook, smtp or mailbox, not %q", c.Notifier.Type)
+	}
+
+	check(c.Attachments.MaxBytes > 0, "attachments.max_bytes", "must be positive")
+	switch c.Attachments.Store {
+	case "local":
+		check(c.Attachments.Dir != "", "attachments.dir", "is required by the local blob store")
+	case "s3":
+		check(c.Attachments.S3.Endpoint != "", "attachments.s3.endpoint", "is required by the s3 blob store")
+		check(c.Attachments.S3.Bucket != "", "attachments.s3.bucket", "is required by the s3 blob store")
+	default:
+		check(false, "attachments.store", "must be local or s3, not %q", c.Attachments.Store)
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "must be debug, info, warn or error, not %q", c.Log.Level)
+	if c.Tracing.Endpoint != "" {
+		endpoint, err := url.Parse(c.Tracing.Endpoint)
+		check(err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "", "tracing.endpoint", "must be an http:// or https:// URL")
+		check(c.Tracing.ServiceName != "", "tracing.service_name", "is required by tracing")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "mongo", "rate_limit.store", "must be memory or mongo")
+	names, routes := map[string]bool{}, map[string]bool{}
+	for _, policy := range c.RateLimit.Policies {
+		key := "rate_limit.policies." + policy.Name
+		check(policy.Name != "" && !names[policy.Name], "rate_limit.policies", "every policy needs a name of its own, not %q", policy.Name)
+		names[policy.Name] = true
+		check(len(policy.Routes) > 0, key, "needs routes")
+		for _, route := range policy.Routes {
+			method, path, _ := strings.Cut(route, " ")
+			check(route == "*" || (method != "" && method == strings.ToUpper(method) && strings.HasPrefix(path, "/")), key, "routes must be written as \"GET /tasks\" or \"*\", not %q", route)
+			check(!routes[route], key, "route %q is in another policy", route)
+			routes[route] = true
+		}
+		validKey := false
+		for _, k := range RateLimitKeys {
+			validKey = validKey || policy.Key == k
+		}
+		check(validKey, key, "key must be %s, not %q", strings.Join(RateLimitKeys, ", "), policy.Key)
+		check(policy.Limit > 0, key, "limit must be positive")
+		check(policy.Period.Duration > 0, key, "period must be positive")
+		check(policy.Burst >= 0, key, "burst cannot be negative")
+	}
+
+	if !c.API.LegacySunset.IsZero() {
+		check(c.API.LegacySunset.After(c.API.LegacyDeprecated.Time), "api.legacy_sunset", "must come after api.legacy_deprecated")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Redacted returns the configuration with its secrets, including the
+// password of the MongoDB URI, hidden.
+func (c Config) Redacted() Config {
+	redacted := c
+	redacted.Reminders.LeadTimes = append([]Duration(nil), c.Reminders.LeadTimes...)
+	for _, s := range redacted.settings() {
+		if !s.secret || s.value.String() == "" {
+			continue
+		}
+		if s.key == "mongo.uri" {
+			redacted.Mongo.URI = redactURI(c.Mongo.URI)
+			continue
+		}
+		s.value.Set("REDACTED")
+	}
+	return redacted
+}
+
+// redactURI hides the password of a URI, which is all there is to hide in
+// it.
+func redactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "REDACTED"
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), "REDACTED")
+	}
+	return parsed.String()
+}
+
+// WriteConfig writes the configuration as YAML, in the format of the
+// configuration files.
+func WriteConfig(w io.Writer, config Config) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// The flag.Value types below set the configuration from environment
+// variables and flags.
+
+type stringValue string
+
+func (v *stringValue) Set(value string) error {
+	*v = stringValue(value)
+	return nil
+}
+
+func (v *stringValue) String() string {
+	return string(*v)
+}
+
+type intValue int
+
+func (v *intValue) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+func (v *intValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+type int64Value int64
+
+func (v *int64Value) Set(value string) error {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*v = int64Value(n)
+	return nil
+}
+
+func (v *int64Value) String() string {
+	return strconv.FormatInt(int64(*v), 10)
+}
+
+type float64Value float64
+
+func (v *float64Value) Set(value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*v = float64Value(f)
+	return nil
+}
+
+func (v *float64Value) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+}
+
+type boolValue bool
+
+func (v *boolValue) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string {
+	return strconv.FormatBool(bool(*v))
+}
+
+func (d *Duration) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+func (d *Date) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+// stringsValue is a comma separated list, such as "10.0.0.0/8,192.168.1.2".
+type stringsValue []string
+
+func (v *stringsValue) Set(value string) error {
+	fields := stringsValue{}
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	*v = fields
+	return nil
+}
+
+func (v *stringsValue) String() string {
+	return strings.Join(*v, ",")
+}
+
+// durationsValue is a comma separated list of durations, such as "24h,1h".
+type durationsValue []Duration
+
+func (v *durationsValue) Set(value string) error {
+	durations := durationsValue{}
+	for _, field := range strings.Split(value, ",") {
+		var duration Duration
+		if err := duration.Set(strings.TrimSpace(field)); err != nil {
+			return err
+		}
+		durations = append(durations, duration)
+	}
+	*v = durations
+	return nil
+}
+
+func (v *durationsValue) String() string {
+	fields := []string{}
+	for _, duration := range *v {
+		fields = append(fields, duration.String())
+	}
+	return strings.Join(fields, ",")
+}
@@ -6,12 +6,20 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-var jwtSecret = []byte("shhhh... it's a secret")
+var jwtSecret = []byte(DefaultJWTSecret)
 
-func GenerateToken(username, role string) (string, error) {
+// UseJWTSecret sets the key tokens are signed with.
+func UseJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
+// GenerateToken returns a token for a user working in a workspace. The role
+// is not part of it: it is looked up on every request, so that a change of
+// role takes effect right away.
+func GenerateToken(username string, workspaceID int) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": username,
-		"role":     role,
+		"username":  username,
+		"workspace": workspaceID,
 	})
 
 	signedToken, err := token.SignedString(jwtSecret)
@@ -0,0 +1,133 @@
+package Infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, from the
+// client or a proxy, and back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the request it serves.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the ID of the request ctx serves, if any.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID gives every request an ID: the one of its X-Request-ID header
+// when it has a usable one, else a new random one. The ID is sent back in
+// the response and carried by the request's context, so that every line
+// logged while serving the request can be told apart.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	c.Set("request_id", id)
+	c.Header(RequestIDHeader, id)
+	c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+	c.Next()
+}
+
+// validRequestID accepts IDs of up to 128 printable ASCII characters, which
+// cannot forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// NewLogger returns a logger writing JSON lines to w, from the given level
+// up. Records logged with a context get the ID of its request and the IDs
+// of its trace and span.
+func NewLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// contextHandler adds the request and trace IDs of the context to the
+// records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFrom(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// probeRoutes are polled by orchestrators and Prometheus; their requests are
+// logged at debug level only.
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// LogRequests logs every request once served, at error level when it
+// failed on the server's side.
+func LogRequests(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case probeRoutes[c.FullPath()]:
+		level = slog.LevelDebug
+	}
+	slog.Log(c.Request.Context(), level, "request",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"route", c.FullPath(),
+		"status", status,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"bytes", c.Writer.Size(),
+		"client_ip", c.ClientIP(),
+		"username", c.GetString("username"))
+}
+
+// Recovery answers 500 to the requests whose handler panics, and logs the
+// panic with its stack.
+var Recovery = gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err interface{}) {
+	slog.ErrorContext(c.Request.Context(), "panic", "error", err, "stack", string(debug.Stack()))
+	c.AbortWithStatus(500)
+})
@@ -0,0 +1,186 @@
+package Infrastructure
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the latency
+// histograms.
+var LatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	httpRequests = newMetric("http_requests_total", "counter",
+		"HTTP requests by method, route and status.", nil, "method", "route", "status")
+	httpRequestDuration = newMetric("http_request_duration_seconds", "histogram",
+		"Duration of the HTTP requests by method, route and status.", LatencyBuckets, "method", "route", "status")
+	dbOperationDuration = newMetric("db_operation_duration_seconds", "histogram",
+		"Duration of the database operations by repository and method.", LatencyBuckets, "repository", "method")
+	authFailures = newMetric("auth_failures_total", "counter",
+		"Failed logins and refused tokens by reason.", nil, "reason")
+	rateLimited = newMetric("rate_limited_total", "counter",
+		"Requests refused for going over a rate limit, by policy.", nil, "policy")
+)
+
+// metrics are the metrics exposed, in order.
+var metrics = []*metric{httpRequests, httpRequestDuration, dbOperationDuration, authFailures, rateLimited}
+
+// metric is a counter or a histogram, with one series per combination of
+// label values.
+type metric struct {
+	name    string
+	kind    string
+	help    string
+	buckets []float64
+	labels  []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is a counter's value, or a histogram's count, sum and cumulative
+// bucket counts.
+type series struct {
+	values  []string
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+func newMetric(name, kind, help string, buckets []float64, labels ...string) *metric {
+	return &metric{name: name, kind: kind, help: help, buckets: buckets, labels: labels, series: map[string]*series{}}
+}
+
+// observe counts one more event, of the given value for histograms, in the
+// series of the label values.
+func (m *metric) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: labelValues, buckets: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	s.count++
+	s.sum += value
+	for i, bound := range m.buckets {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+}
+
+// write writes the metric in the Prometheus text exposition format, its
+// series sorted by label values.
+func (m *metric) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+	for _, key := range keys {
+		s := m.series[key]
+		labels := m.labelPairs(s.values)
+		if m.kind == "counter" {
+			fmt.Fprintf(&b, "%s%s %d\n", m.name, braces(labels), s.count)
+			continue
+		}
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", m.name, braces(labels, `le="`+formatFloat(bound)+`"`), s.buckets[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket%s %d\n", m.name, braces(labels, `le="+Inf"`), s.count)
+		fmt.Fprintf(&b, "%s_sum%s %s\n", m.name, braces(labels), formatFloat(s.sum))
+		fmt.Fprintf(&b, "%s_count%s %d\n", m.name, braces(labels), s.count)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *metric) labelPairs(values []string) []string {
+	pairs := make([]string, len(m.labels))
+	for i, label := range m.labels {
+		pairs[i] = label + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return pairs
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// braces writes label pairs as they follow a metric name, none at all when
+// there are none.
+func braces(pairs []string, extra ...string) string {
+	pairs = append(pairs[:len(pairs):len(pairs)], extra...)
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// WriteMetrics writes every metric in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer) error {
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Metrics serves the metrics to Prometheus.
+func Metrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	WriteMetrics(c.Writer)
+}
+
+// CountRequests counts the requests and their durations by route, the
+// pattern rather than the path so that IDs do not make a series each.
+// Requests matching no route are counted under "unmatched".
+func CountRequests(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(c.Writer.Status())
+	httpRequests.observe(1, c.Request.Method, route, status)
+	httpRequestDuration.observe(time.Since(start).Seconds(), c.Request.Method, route, status)
+}
+
+// ObserveDBOperation records the duration of a repository method; see
+// Repositories.UseOperationObserver.
+func ObserveDBOperation(repository, method string, duration time.Duration) {
+	dbOperationDuration.observe(duration.Seconds(), repository, method)
+}
+
+// countAuthFailure counts a failed login or a refused token.
+func countAuthFailure(reason string) {
+	authFailures.observe(1, reason)
+}
+
+// countRateLimited counts a request refused by a rate limit policy.
+func countRateLimited(policy string) {
+	rateLimited.observe(1, policy)
+}
@@ -0,0 +1,114 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+// LogNotifier writes notifications to the log instead of delivering them.
+type LogNotifier struct{}
+
+func (n *LogNotifier) Notify(notification Domain.Notification) error {
+	slog.Info("notification", "recipient", notification.Recipient, "subject", notification.Subject)
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to a URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(notification Domain.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier emails notifications to the users that have an email address.
+type SMTPNotifier struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// NewSMTPNotifier returns a notifier sending mail through the SMTP server at
+// addr, in host:port form. The username may be empty for servers that take
+// mail without authentication.
+func NewSMTPNotifier(addr, from, username, password string) *SMTPNotifier {
+	notifier := &SMTPNotifier{Addr: addr, From: from}
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		notifier.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return notifier
+}
+
+func (n *SMTPNotifier) Notify(notification Domain.Notification) error {
+	if notification.Email == "" {
+		slog.Warn("notification dropped, no email address", "recipient", notification.Recipient)
+		return nil
+	}
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{notification.Email}, FormatEmail(n.From, notification))
+}
+
+// MailboxNotifier stands in for an SMTP server during development: it writes
+// each email it would send to a file in a directory, where it can be opened
+// with a mail client.
+type MailboxNotifier struct {
+	Dir  string
+	From string
+}
+
+func (n *MailboxNotifier) Notify(notification Domain.Notification) error {
+	if err := os.MkdirAll(n.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s-%d.eml", time.Now().UnixNano(), notification.Kind, notification.Task.ID)
+	return os.WriteFile(filepath.Join(n.Dir, name), FormatEmail(n.From, notification), 0o644)
+}
+
+// FormatEmail writes a notification as a plain text email message.
+func FormatEmail(from string, notification Domain.Notification) []byte {
+	to := notification.Email
+	if to == "" {
+		to = notification.Recipient
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(notification.Body)
+	message.WriteString("\r\n")
+	return message.Bytes()
+}
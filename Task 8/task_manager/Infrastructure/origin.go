@@ -0,0 +1,35 @@
+package Infrastructure
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var allowedOrigins = map[string]bool{}
+
+// UseAllowedOrigins lets browsers open WebSockets from origins, such as
+// https://app.example.com, besides the service's own.
+func UseAllowedOrigins(origins []string) {
+	allowedOrigins = map[string]bool{}
+	for _, origin := range origins {
+		allowedOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+}
+
+// OriginAllowed reports whether a WebSocket may be opened by the request,
+// which browsers send with the origin of the page opening it. The page must
+// be served by the service itself or from an allowed origin, so that other
+// sites cannot act for the users visiting them. Clients outside browsers
+// send no origin and are not checked.
+func OriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, r.Host) || allowedOrigins[strings.ToLower(origin)]
+}
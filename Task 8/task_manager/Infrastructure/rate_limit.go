@@ -0,0 +1,225 @@
+package Infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+// RateLimitPolicy limits the requests to some routes to Limit per Period,
+// counted by client, with bursts of up to Burst requests. Clients are told
+// apart by Key: their ip, their user or their api_key. Routes are written
+// as "POST /login", with the route patterns of the router without their
+// version, so that they cover every version; "*" stands for the routes of
+// no other policy.
+type RateLimitPolicy struct {
+	Name   string   `yaml:"name" toml:"name"`
+	Routes []string `yaml:"routes" toml:"routes"`
+	Key    string   `yaml:"key" toml:"key"`
+	Limit  int      `yaml:"limit" toml:"limit"`
+	Period Duration `yaml:"period" toml:"period"`
+	Burst  int      `yaml:"burst" toml:"burst"`
+}
+
+// burst is the size of the policy's token buckets: Burst, or Limit when it
+// is not set.
+func (p RateLimitPolicy) burst() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+// rate is the number of tokens the policy's buckets gain per second.
+func (p RateLimitPolicy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// RateLimitKeys are the ways clients can be told apart.
+var RateLimitKeys = []string{"ip", "user", "api_key"}
+
+// APIKeyHeader is the header the api_key policies tell clients apart by.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyStore knows the API keys issued to clients.
+type APIKeyStore interface {
+	// Issued reports whether key was issued and is not revoked.
+	Issued(key string) bool
+}
+
+var apiKeys APIKeyStore
+
+// UseAPIKeys has the api_key policies tell clients apart by the keys of
+// store. Without it, no key is trusted.
+func UseAPIKeys(store APIKeyStore) {
+	apiKeys = store
+}
+
+// RateLimitStore keeps the token buckets of the clients.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of key, which holds up to burst
+	// tokens and gains rate tokens per second, as of now. It returns the
+	// tokens left and whether there was one to take.
+	Take(key string, burst, rate float64, now time.Time) (float64, bool, error)
+}
+
+// MemoryRateLimitStore keeps the token buckets in memory. Each instance of
+// the service then limits the requests it serves on its own; deployments of
+// several instances share a store such as Repositories.RateLimitRepository
+// instead.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, burst, rate float64, now time.Time) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Buckets full again are as good as new, so they are dropped every
+	// minute rather than kept for clients that may never come back.
+	if now.Sub(s.swept) > time.Minute {
+		for key, bucket := range s.buckets {
+			if !bucket.full.After(now) {
+				delete(s.buckets, key)
+			}
+		}
+		s.swept = now
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, updated: now}
+		s.buckets[key] = bucket
+	}
+	if elapsed := now.Sub(bucket.updated).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed*rate)
+		bucket.updated = now
+	}
+	taken := bucket.tokens >= 1
+	if taken {
+		bucket.tokens--
+	}
+	bucket.full = now.Add(secondsToDuration((burst - bucket.tokens) / rate))
+	return bucket.tokens, taken, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+var rateLimits struct {
+	store    RateLimitStore
+	byRoute  map[string]RateLimitPolicy
+	fallback *RateLimitPolicy
+}
+
+// UseRateLimits limits the requests with the policies, keeping the token
+// buckets in store. Without it, requests are not limited.
+func UseRateLimits(policies []RateLimitPolicy, store RateLimitStore) {
+	rateLimits.store = store
+	rateLimits.byRoute = map[string]RateLimitPolicy{}
+	rateLimits.fallback = nil
+	for _, policy := range policies {
+		for _, route := range policy.Routes {
+			if route == "*" {
+				fallback := policy
+				rateLimits.fallback = &fallback
+				continue
+			}
+			rateLimits.byRoute[route] = policy
+		}
+	}
+}
+
+// RateLimit refuses the requests of the clients over the limit of the
+// route's policy with 429 Too Many Requests, telling them when to retry.
+// Every limited response has the RateLimit-Policy, RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers. Requests are let through
+// when the store fails, so that its outage is not the service's.
+func RateLimit(c *gin.Context) {
+	if rateLimits.store == nil {
+		c.Next()
+		return
+	}
+	policy, ok := rateLimits.byRoute[c.Request.Method+" "+UnversionedRoute(c.FullPath())]
+	if !ok {
+		if rateLimits.fallback == nil {
+			c.Next()
+			return
+		}
+		policy = *rateLimits.fallback
+	}
+
+	burst, rate := policy.burst(), policy.rate()
+	key := policy.Name + ":" + policy.Key + ":" + clientKey(c, policy.Key)
+	tokens, taken, err := rateLimits.store.Take(key, burst, rate, time.Now())
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "rate limit not checked", "policy", policy.Name, "error", err)
+		c.Next()
+		return
+	}
+
+	c.Header("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(int(policy.Period.Seconds()))+";burst="+strconv.Itoa(int(burst)))
+	c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(int(tokens)))
+	c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil((burst-tokens)/rate))))
+	if !taken {
+		countRateLimited(policy.Name)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil((1-tokens)/rate))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, retry later"})
+		return
+	}
+	c.Next()
+}
+
+// clientKey tells the client of a request apart by the policy's key. The
+// user is the one of the request's token, checked here since the limit
+// comes before authentication. API keys are only trusted once the key store
+// knows them, or a client could get a new bucket with each made up key, and
+// are hashed so that the store does not hold them. Requests without an
+// issued API key are told apart by their user, and requests without a valid
+// token by their ip.
+func clientKey(c *gin.Context, key string) string {
+	switch key {
+	case "user":
+		token := c.Query("access_token")
+		if header := c.GetHeader("Authorization"); header != "" {
+			token = strings.TrimPrefix(header, "Bearer ")
+		}
+		if parsed, err := ValidateToken(token); err == nil && parsed.Valid {
+			if claims, ok := parsed.Claims.(jwt.MapClaims); ok {
+				if username, ok := claims["username"].(string); ok && username != "" {
+					return "user:" + username
+				}
+			}
+		}
+	case "api_key":
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" && apiKeys != nil && apiKeys.Issued(apiKey) {
+			sum := sha256.Sum256([]byte(apiKey))
+			return "key:" + hex.EncodeToString(sum[:16])
+		}
+		return clientKey(c, "user")
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,56 @@
+package Infrastructure
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionHeader is the request header picking the version of the API the
+// unversioned routes serve, such as "v2".
+const VersionHeader = "Accept-Version"
+
+// APIVersionHeader is the response header telling the version of the API
+// that served the request.
+const APIVersionHeader = "API-Version"
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// UnversionedRoute returns a route without its version prefix: /tasks/:id
+// for /v1/tasks/:id.
+func UnversionedRoute(route string) string {
+	if prefix := versionPrefix.FindString(route); prefix != "" {
+		return "/" + route[len(prefix):]
+	}
+	return route
+}
+
+// APIVersion tells clients the version of the API serving their requests.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(APIVersionHeader, version)
+		c.Next()
+	}
+}
+
+// Deprecated announces that routes are going away: since when they are
+// deprecated in the Deprecation header (RFC 9745), when they stop being
+// served in the Sunset header (RFC 8594), if known, and where their
+// successor is, under successorPrefix, in a Link header.
+func Deprecated(deprecated, sunset time.Time, successorPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !deprecated.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecated.Unix(), 10))
+		}
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if successorPrefix != "" {
+			c.Header("Link", "<"+successorPrefix+UnversionedRoute(c.Request.URL.Path)+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var attachment_ctx = GetContext()
+
+type IAttachmentRepository interface {
+	WithContext(ctx context.Context) IAttachmentRepository
+	CreateAttachment(attachment Domain.Attachment) error
+	GetAttachments(taskID int) ([]Domain.Attachment, error)
+	GetAttachmentsByTasks(taskIDs []int) ([]Domain.Attachment, error)
+	GetAttachmentByID(id int) (Domain.Attachment, error)
+	DeleteAttachment(id int) error
+	GetNextAttachmentID() int
+}
+
+type AttachmentRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewAttachmentRepository(dbName string) IAttachmentRepository {
+	collection := client.Database(dbName).Collection("attachments")
+	return &AttachmentRepository{collection: collection, ctx: attachment_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (a *AttachmentRepository) WithContext(ctx context.Context) IAttachmentRepository {
+	bound := *a
+	bound.ctx = ctx
+	return &bound
+}
+
+func (a *AttachmentRepository) CreateAttachment(attachment Domain.Attachment) error {
+	defer observe(a.ctx, "AttachmentRepository", "CreateAttachment")()
+	if _, err := a.collection.InsertOne(a.ctx, attachment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAttachments returns the attachments of a task, oldest first.
+func (a *AttachmentRepository) GetAttachments(taskID int) ([]Domain.Attachment, error) {
+	defer observe(a.ctx, "AttachmentRepository", "GetAttachments")()
+	return a.findAttachments(bson.M{"taskid": taskID})
+}
+
+func (a *AttachmentRepository) GetAttachmentsByTasks(taskIDs []int) ([]Domain.Attachment, error) {
+	defer observe(a.ctx, "AttachmentRepository", "GetAttachmentsByTasks")()
+	return a.findAttachments(bson.M{"taskid": bson.M{"$in": taskIDs}})
+}
+
+func (a *AttachmentRepository) GetAttachmentByID(id int) (Domain.Attachment, error) {
+	defer observe(a.ctx, "AttachmentRepository", "GetAttachmentByID")()
+	var attachment Domain.Attachment
+	if err := a.collection.FindOne(a.ctx, bson.M{"id": id}).Decode(&attachment); err != nil {
+		return attachment, err
+	}
+	return attachment, nil
+}
+
+func (a *AttachmentRepository) DeleteAttachment(id int) error {
+	defer observe(a.ctx, "AttachmentRepository", "DeleteAttachment")()
+	result, err := a.collection.DeleteOne(a.ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("attachment not found")
+	}
+	return nil
+}
+
+func (a *AttachmentRepository) GetNextAttachmentID() int {
+	defer observe(a.ctx, "AttachmentRepository", "GetNextAttachmentID")()
+	var attachment Domain.Attachment
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := a.collection.FindOne(a.ctx, bson.D{}, findOptions).Decode(&attachment)
+	if err != nil {
+
+		return 1
+	}
+	return attachment.ID + 1
+}
+
+func (a *AttachmentRepository) findAttachments(filter bson.M) ([]Domain.Attachment, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := a.collection.Find(a.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []Domain.Attachment{}
+	if err := cursor.All(a.ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
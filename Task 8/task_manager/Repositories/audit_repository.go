@@ -0,0 +1,105 @@
+package Repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var audit_ctx = GetContext()
+
+// IAuditRepository is append-only: entries can be added and queried but
+// never updated or removed.
+type IAuditRepository interface {
+	WithContext(ctx context.Context) IAuditRepository
+	Append(entry Domain.AuditEntry) error
+	Find(filter Domain.AuditFilter) ([]Domain.AuditEntry, error)
+	GetNextAuditID() int
+}
+
+type AuditRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewAuditRepository(dbName string) IAuditRepository {
+	collection := client.Database(dbName).Collection("audit_log")
+	return &AuditRepository{collection: collection, ctx: audit_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (a *AuditRepository) WithContext(ctx context.Context) IAuditRepository {
+	bound := *a
+	bound.ctx = ctx
+	return &bound
+}
+
+func (a *AuditRepository) Append(entry Domain.AuditEntry) error {
+	defer observe(a.ctx, "AuditRepository", "Append")()
+	if _, err := a.collection.InsertOne(a.ctx, entry); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *AuditRepository) Find(filter Domain.AuditFilter) ([]Domain.AuditEntry, error) {
+	defer observe(a.ctx, "AuditRepository", "Find")()
+	query := bson.M{}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.TargetType != "" {
+		query["targettype"] = filter.TargetType
+	}
+	if filter.TargetID != 0 {
+		query["targetid"] = filter.TargetID
+	}
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timestamp["$lte"] = filter.To
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	if filter.Skip > 0 {
+		findOptions.SetSkip(int64(filter.Skip))
+	}
+	if filter.Limit > 0 {
+		findOptions.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := a.collection.Find(a.ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Domain.AuditEntry{}
+	if err := cursor.All(a.ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (a *AuditRepository) GetNextAuditID() int {
+	defer observe(a.ctx, "AuditRepository", "GetNextAuditID")()
+	var entry Domain.AuditEntry
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := a.collection.FindOne(a.ctx, bson.D{}, findOptions).Decode(&entry)
+	if err != nil {
+
+		return 1
+	}
+	return entry.ID + 1
+}
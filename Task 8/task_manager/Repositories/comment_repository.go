@@ -0,0 +1,163 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var comment_ctx = GetContext()
+
+type ICommentRepository interface {
+	WithContext(ctx context.Context) ICommentRepository
+	CreateComment(comment Domain.Comment) error
+	GetComments(taskID, skip, limit int) ([]Domain.Comment, error)
+	CountComments(taskID int) (int, error)
+	GetLatestComments(taskIDs []int, limit int) (map[int][]Domain.Comment, error)
+	GetCommentByID(id int) (Domain.Comment, error)
+	UpdateComment(comment Domain.Comment) error
+	GetNextCommentID() int
+}
+
+type CommentRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewCommentRepository(dbName string) ICommentRepository {
+	collection := client.Database(dbName).Collection("comments")
+	return &CommentRepository{collection: collection, ctx: comment_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (r *CommentRepository) WithContext(ctx context.Context) ICommentRepository {
+	bound := *r
+	bound.ctx = ctx
+	return &bound
+}
+
+func (r *CommentRepository) CreateComment(comment Domain.Comment) error {
+	defer observe(r.ctx, "CommentRepository", "CreateComment")()
+	if _, err := r.collection.InsertOne(r.ctx, comment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetComments returns the comments of a task in the order they were posted.
+// A limit of 0 returns every comment after skip.
+func (r *CommentRepository) GetComments(taskID, skip, limit int) ([]Domain.Comment, error) {
+	defer observe(r.ctx, "CommentRepository", "GetComments")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	if skip > 0 {
+		findOptions.SetSkip(int64(skip))
+	}
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.collection.Find(r.ctx, bson.M{"taskid": taskID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []Domain.Comment{}
+	if err := cursor.All(r.ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *CommentRepository) CountComments(taskID int) (int, error) {
+	defer observe(r.ctx, "CommentRepository", "CountComments")()
+	count, err := r.collection.CountDocuments(r.ctx, bson.M{"taskid": taskID})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// GetLatestComments returns the last limit comments of each of the given
+// tasks, in the order they were posted, in one query. Tasks without comments
+// are left out.
+func (r *CommentRepository) GetLatestComments(taskIDs []int, limit int) (map[int][]Domain.Comment, error) {
+	defer observe(r.ctx, "CommentRepository", "GetLatestComments")()
+	latest := map[int][]Domain.Comment{}
+	if len(taskIDs) == 0 || limit <= 0 {
+		return latest, nil
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"taskid": bson.M{"$in": taskIDs}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$taskid", "comments": bson.M{"$push": "$$ROOT"}}}},
+		{{Key: "$project", Value: bson.M{"comments": bson.M{"$slice": bson.A{"$comments", limit}}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(r.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		TaskID   int              `bson:"_id"`
+		Comments []Domain.Comment `bson:"comments"`
+	}
+	if err := cursor.All(r.ctx, &results); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		comments := result.Comments
+		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+			comments[i], comments[j] = comments[j], comments[i]
+		}
+		latest[result.TaskID] = comments
+	}
+	return latest, nil
+}
+
+func (r *CommentRepository) GetCommentByID(id int) (Domain.Comment, error) {
+	defer observe(r.ctx, "CommentRepository", "GetCommentByID")()
+	var comment Domain.Comment
+	if err := r.collection.FindOne(r.ctx, bson.M{"id": id}).Decode(&comment); err != nil {
+		return comment, err
+	}
+	return comment, nil
+}
+
+func (r *CommentRepository) UpdateComment(comment Domain.Comment) error {
+	defer observe(r.ctx, "CommentRepository", "UpdateComment")()
+	filter := bson.M{"id": comment.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"body":      comment.Body,
+			"mentions":  comment.Mentions,
+			"deleted":   comment.Deleted,
+			"updatedat": comment.UpdatedAt,
+		},
+	}
+	result, err := r.collection.UpdateOne(r.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("comment not found")
+	}
+	return nil
+}
+
+func (r *CommentRepository) GetNextCommentID() int {
+	defer observe(r.ctx, "CommentRepository", "GetNextCommentID")()
+	var comment Domain.Comment
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := r.collection.FindOne(r.ctx, bson.D{}, findOptions).Decode(&comment)
+	if err != nil {
+
+		return 1
+	}
+	return comment.ID + 1
+}
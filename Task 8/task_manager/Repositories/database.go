@@ -2,10 +2,14 @@ package Repositories
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -13,18 +17,87 @@ var (
 	client *mongo.Client
 )
 
-func init() {
-	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017/")
+// ConnectRetryMin and ConnectRetryMax bound the wait between the attempts of
+// WaitForServer, which doubles after each failed one.
+var (
+	ConnectRetryMin = 500 * time.Millisecond
+	ConnectRetryMax = 15 * time.Second
+)
+
+// Connect sets up the client of the MongoDB server at uri. It must be called
+// before any repository is created. The client connects lazily, on its first
+// operation, and reconnects by itself, so only a malformed uri fails here;
+// WaitForServer waits for the server to answer.
+func Connect(uri string) error {
+	clientOptions := options.Client().ApplyURI(uri)
 	var err error
 	client, err = mongo.Connect(ctx, clientOptions)
-	if err != nil {
-		log.Fatal(err)
+	return err
+}
+
+// WaitForServer pings the server until it answers, backing off between the
+// attempts, and gives up with the last error when waitCtx is done.
+func WaitForServer(waitCtx context.Context) error {
+	wait := ConnectRetryMin
+	for {
+		err := client.Ping(waitCtx, nil)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("database not reachable", "retry_in", wait.String(), "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-waitCtx.Done():
+			timer.Stop()
+			return err
+		}
+		wait *= 2
+		if wait > ConnectRetryMax {
+			wait = ConnectRetryMax
+		}
 	}
-	err = client.Ping(ctx, nil)
-	if err != nil {
-		log.Fatal(err)
+}
+
+// Ping checks that the server answers.
+func Ping(pingCtx context.Context) error {
+	return client.Ping(pingCtx, nil)
+}
+
+// Disconnect closes the connections of the client, waiting for the
+// operations in progress until closeCtx is done.
+func Disconnect(closeCtx context.Context) error {
+	if client == nil {
+		return nil
 	}
+	return client.Disconnect(closeCtx)
+}
+
+// observeOperation is told how long each repository method took.
+var observeOperation = func(repository, method string, duration time.Duration) {}
 
+// UseOperationObserver sets the function told how long each repository
+// method takes, such as the one recording the database latency metrics.
+func UseOperationObserver(observer func(repository, method string, duration time.Duration)) {
+	observeOperation = observer
+}
+
+var tracer = otel.Tracer("task_manager/Repositories")
+
+// observe times a repository method and traces it as a span of the request
+// in opCtx, when deferred at its start:
+//
+//	defer observe(t.ctx, "TaskRepository", "GetTasks")()
+func observe(opCtx context.Context, repository, method string) func() {
+	start := time.Now()
+	_, span := tracer.Start(opCtx, repository+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "mongodb")))
+	return func() {
+		span.End()
+		observeOperation(repository, method, time.Since(start))
+	}
 }
 
 func GetContext() context.Context {
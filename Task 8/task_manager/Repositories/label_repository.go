@@ -0,0 +1,121 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var label_ctx = GetContext()
+
+type ILabelRepository interface {
+	WithContext(ctx context.Context) ILabelRepository
+	GetLabels() ([]Domain.Label, error)
+	GetLabelByID(id int) (Domain.Label, error)
+	GetLabelByName(name string) (Domain.Label, error)
+	CreateLabel(label Domain.Label) error
+	UpdateLabel(label Domain.Label) error
+	DeleteLabel(id int) error
+	GetNextLabelID() int
+}
+
+type LabelRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewLabelRepository(dbName string) ILabelRepository {
+	collection := client.Database(dbName).Collection("labels")
+	return &LabelRepository{collection: collection, ctx: label_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (l *LabelRepository) WithContext(ctx context.Context) ILabelRepository {
+	bound := *l
+	bound.ctx = ctx
+	return &bound
+}
+
+func (l *LabelRepository) GetLabels() ([]Domain.Label, error) {
+	defer observe(l.ctx, "LabelRepository", "GetLabels")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := l.collection.Find(l.ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []Domain.Label{}
+	if err := cursor.All(l.ctx, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (l *LabelRepository) GetLabelByID(id int) (Domain.Label, error) {
+	defer observe(l.ctx, "LabelRepository", "GetLabelByID")()
+	var label Domain.Label
+	if err := l.collection.FindOne(l.ctx, bson.M{"id": id}).Decode(&label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+func (l *LabelRepository) GetLabelByName(name string) (Domain.Label, error) {
+	defer observe(l.ctx, "LabelRepository", "GetLabelByName")()
+	var label Domain.Label
+	if err := l.collection.FindOne(l.ctx, bson.M{"name": name}).Decode(&label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+func (l *LabelRepository) CreateLabel(label Domain.Label) error {
+	defer observe(l.ctx, "LabelRepository", "CreateLabel")()
+	if _, err := l.collection.InsertOne(l.ctx, label); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *LabelRepository) UpdateLabel(label Domain.Label) error {
+	defer observe(l.ctx, "LabelRepository", "UpdateLabel")()
+	filter := bson.M{"id": label.ID}
+	update := bson.M{"$set": bson.M{"name": label.Name, "color": label.Color}}
+	result, err := l.collection.UpdateOne(l.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("label not found")
+	}
+	return nil
+}
+
+func (l *LabelRepository) DeleteLabel(id int) error {
+	defer observe(l.ctx, "LabelRepository", "DeleteLabel")()
+	result, err := l.collection.DeleteOne(l.ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("label not found")
+	}
+	return nil
+}
+
+func (l *LabelRepository) GetNextLabelID() int {
+	defer observe(l.ctx, "LabelRepository", "GetNextLabelID")()
+	var label Domain.Label
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := l.collection.FindOne(l.ctx, bson.D{}, findOptions).Decode(&label)
+	if err != nil {
+
+		return 1
+	}
+	return label.ID + 1
+}
@@ -0,0 +1,129 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"task_manager/Domain"
+	"time"
+)
+
+// MemoryReportRepository computes the same reports as ReportRepository over
+// tasks held in memory, for stores without aggregation pipelines and for
+// tests.
+type MemoryReportRepository struct {
+	tasks func() []Domain.Task
+}
+
+// NewMemoryReportRepository returns a repository reporting on the tasks
+// returned by the given function, called for every report.
+func NewMemoryReportRepository(tasks func() []Domain.Task) IReportRepository {
+	return &MemoryReportRepository{tasks: tasks}
+}
+
+// WithContext returns the repository itself: it works in memory.
+func (m *MemoryReportRepository) WithContext(ctx context.Context) IReportRepository {
+	return m
+}
+
+func (m *MemoryReportRepository) Throughput(filter Domain.ReportFilter) ([]Domain.ThroughputBucket, error) {
+	created, completed := map[string]int{}, map[string]int{}
+	for _, task := range m.find(filter) {
+		if inReportRange(task.CreatedAt, filter) {
+			created[periodOf(*task.CreatedAt, filter.Interval)]++
+		}
+		if inReportRange(task.CompletedAt, filter) {
+			completed[periodOf(*task.CompletedAt, filter.Interval)]++
+		}
+	}
+	return throughputBuckets(created, completed), nil
+}
+
+func (m *MemoryReportRepository) Durations(filter Domain.ReportFilter) (Domain.TaskDurations, error) {
+	durations := Domain.TaskDurations{LeadTimes: []float64{}, CycleTimes: []float64{}}
+	for _, task := range m.find(filter) {
+		if !inReportRange(task.CompletedAt, filter) || task.CreatedAt == nil {
+			continue
+		}
+		durations.LeadTimes = append(durations.LeadTimes, task.CompletedAt.Sub(*task.CreatedAt).Hours())
+		if task.StartedAt != nil {
+			durations.CycleTimes = append(durations.CycleTimes, task.CompletedAt.Sub(*task.StartedAt).Hours())
+		}
+	}
+	sort.Float64s(durations.LeadTimes)
+	sort.Float64s(durations.CycleTimes)
+	return durations, nil
+}
+
+func (m *MemoryReportRepository) Overdue(filter Domain.ReportFilter) (Domain.OverdueReport, error) {
+	report := Domain.OverdueReport{ByAssignee: map[string]int{}, ByPriority: map[string]int{}}
+	for _, task := range m.find(filter) {
+		if task.Overdue {
+			report.Total++
+			report.ByAssignee[task.Assignee]++
+			report.ByPriority[task.Priority]++
+		}
+	}
+	return report, nil
+}
+
+func (m *MemoryReportRepository) Workload(filter Domain.ReportFilter) ([]Domain.Workload, error) {
+	byAssignee := map[string]*Domain.Workload{}
+	for _, task := range m.find(filter) {
+		if task.IsCompleted() {
+			continue
+		}
+		workload, ok := byAssignee[task.Assignee]
+		if !ok {
+			workload = &Domain.Workload{Assignee: task.Assignee}
+			byAssignee[task.Assignee] = workload
+		}
+		workload.Open++
+		if !task.IsPending() {
+			workload.InProgress++
+		}
+		if task.Overdue {
+			workload.Overdue++
+		}
+		workload.StoryPoints += task.StoryPoints
+		workload.EstimateMinutes += task.Estimate
+	}
+
+	workloads := []Domain.Workload{}
+	for _, workload := range byAssignee {
+		workloads = append(workloads, *workload)
+	}
+	sort.Slice(workloads, func(i, j int) bool { return workloads[i].Assignee < workloads[j].Assignee })
+	return workloads, nil
+}
+
+// find returns the tasks a report is about.
+func (m *MemoryReportRepository) find(filter Domain.ReportFilter) []Domain.Task {
+	tasks := []Domain.Task{}
+	for _, task := range m.tasks() {
+		if task.DeletedAt == nil && (filter.ProjectID == 0 || task.ProjectID == filter.ProjectID) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+func inReportRange(at *time.Time, filter Domain.ReportFilter) bool {
+	return at != nil &&
+		(filter.From.IsZero() || !at.Before(filter.From)) &&
+		(filter.To.IsZero() || at.Before(filter.To))
+}
+
+// periodOf names the period a time falls in, as periodFormats does.
+func periodOf(at time.Time, interval string) string {
+	at = at.UTC()
+	switch interval {
+	case Domain.IntervalWeek:
+		year, week := at.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case Domain.IntervalMonth:
+		return at.Format("2006-01")
+	default:
+		return at.Format("2006-01-02")
+	}
+}
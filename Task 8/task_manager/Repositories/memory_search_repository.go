@@ -0,0 +1,240 @@
+package Repositories
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"task_manager/Domain"
+)
+
+// Weights of the indexed fields when scoring a match.
+var fieldWeights = map[string]float64{"title": 3, "description": 1, "body": 1}
+
+type memoryDocument struct {
+	key     string
+	hit     Domain.SearchHit
+	weights map[string]float64
+	// text holds the words of each field joined by single spaces, to match
+	// phrases against.
+	text []string
+}
+
+// MemorySearchRepository is an in-process inverted index over tasks and
+// comments. It only knows about the documents it has been given through
+// IndexTask and IndexComment.
+type MemorySearchRepository struct {
+	mu        sync.RWMutex
+	documents map[string]*memoryDocument
+	postings  map[string]map[string]bool
+	tasks     map[int]Domain.Task
+}
+
+func NewMemorySearchRepository() ISearchRepository {
+	return &MemorySearchRepository{
+		documents: map[string]*memoryDocument{},
+		postings:  map[string]map[string]bool{},
+		tasks:     map[int]Domain.Task{},
+	}
+}
+
+// WithContext returns the repository itself: it works in memory.
+func (m *MemorySearchRepository) WithContext(ctx context.Context) ISearchRepository {
+	return m
+}
+
+// IndexTask adds or replaces a task in the index. Tasks in the trash are
+// removed instead.
+func (m *MemorySearchRepository) IndexTask(task Domain.Task) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := documentKey(Domain.SearchTask, task.ID)
+	m.remove(key)
+	delete(m.tasks, task.ID)
+	if task.DeletedAt != nil {
+		return
+	}
+
+	m.tasks[task.ID] = task
+	m.add(key, Domain.SearchHit{
+		Type:   Domain.SearchTask,
+		TaskID: task.ID,
+		Title:  task.Title,
+		Fields: map[string]string{"title": task.Title, "description": task.Description},
+	})
+}
+
+// IndexComment adds or replaces a comment in the index. Deleted comments are
+// removed instead.
+func (m *MemorySearchRepository) IndexComment(comment Domain.Comment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := documentKey(Domain.SearchComment, comment.ID)
+	m.remove(key)
+	if comment.Deleted {
+		return
+	}
+
+	m.add(key, Domain.SearchHit{
+		Type:      Domain.SearchComment,
+		TaskID:    comment.TaskID,
+		CommentID: comment.ID,
+		Fields:    map[string]string{"body": comment.Body},
+	})
+}
+
+func (m *MemorySearchRepository) RemoveTask(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(documentKey(Domain.SearchTask, id))
+	delete(m.tasks, id)
+}
+
+func (m *MemorySearchRepository) RemoveComment(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(documentKey(Domain.SearchComment, id))
+}
+
+// Search scores documents with TF-IDF: every query word found in a document
+// adds its weighted frequency times the inverse of how many documents contain
+// it, and every phrase adds the score of its words.
+func (m *MemorySearchRepository) Search(query Domain.SearchQuery, limit int) ([]Domain.SearchHit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	words := append([]string{}, query.Terms...)
+	for _, prefix := range query.Prefixes {
+		for word := range m.postings {
+			if strings.HasPrefix(word, prefix) {
+				words = append(words, word)
+			}
+		}
+	}
+	phrases := [][]string{}
+	for _, phrase := range query.Phrases {
+		if tokens := Domain.Tokenize(phrase); len(tokens) > 0 {
+			phrases = append(phrases, tokens)
+		}
+	}
+
+	candidates := map[string]bool{}
+	switch {
+	case !query.HasText():
+		// A query made only of qualifiers matches the tasks satisfying them.
+		for id := range m.tasks {
+			candidates[documentKey(Domain.SearchTask, id)] = true
+		}
+	case len(phrases) > 0:
+		for key := range m.postings[phrases[0][0]] {
+			candidates[key] = true
+		}
+	default:
+		for _, word := range words {
+			for key := range m.postings[word] {
+				candidates[key] = true
+			}
+		}
+	}
+
+	total := float64(len(m.documents))
+	idf := func(word string) float64 {
+		return math.Log(1 + total/float64(len(m.postings[word])+1))
+	}
+
+	hits := []Domain.SearchHit{}
+	for key := range candidates {
+		document := m.documents[key]
+		if !document.containsPhrases(phrases) {
+			continue
+		}
+
+		task, live := m.tasks[document.hit.TaskID]
+		if !live || !query.MatchesTask(task) {
+			continue
+		}
+
+		score := 0.0
+		for _, word := range words {
+			score += document.weights[word] * idf(word)
+		}
+		for _, phrase := range phrases {
+			for _, word := range phrase {
+				score += document.weights[word] * idf(word)
+			}
+		}
+
+		hit := document.hit
+		hit.Title = task.Title
+		hit.Score = math.Round(score*1000) / 1000
+		hits = append(hits, hit)
+	}
+
+	SortSearchHits(hits)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (m *MemorySearchRepository) add(key string, hit Domain.SearchHit) {
+	document := &memoryDocument{key: key, hit: hit, weights: map[string]float64{}}
+	for field, text := range hit.Fields {
+		tokens := Domain.Tokenize(text)
+		for _, token := range tokens {
+			document.weights[token] += fieldWeights[field]
+		}
+		document.text = append(document.text, " "+strings.Join(tokens, " ")+" ")
+	}
+
+	m.documents[key] = document
+	for word := range document.weights {
+		if m.postings[word] == nil {
+			m.postings[word] = map[string]bool{}
+		}
+		m.postings[word][key] = true
+	}
+}
+
+func (m *MemorySearchRepository) remove(key string) {
+	document, ok := m.documents[key]
+	if !ok {
+		return
+	}
+
+	for word := range document.weights {
+		delete(m.postings[word], key)
+		if len(m.postings[word]) == 0 {
+			delete(m.postings, word)
+		}
+	}
+	delete(m.documents, key)
+}
+
+// containsPhrases reports whether every phrase appears, word for word, in one
+// of the fields of the document.
+func (d *memoryDocument) containsPhrases(phrases [][]string) bool {
+	for _, phrase := range phrases {
+		needle := " " + strings.Join(phrase, " ") + " "
+		found := false
+		for _, text := range d.text {
+			if strings.Contains(text, needle) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func documentKey(kind string, id int) string {
+	return kind + ":" + strconv.Itoa(id)
+}
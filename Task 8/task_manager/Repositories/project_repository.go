@@ -0,0 +1,128 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var project_ctx = GetContext()
+
+type IProjectRepository interface {
+	WithContext(ctx context.Context) IProjectRepository
+	GetProjects() ([]Domain.Project, error)
+	GetProjectsByMember(username string) ([]Domain.Project, error)
+	GetProjectByID(id int) (Domain.Project, error)
+	CreateProject(project Domain.Project) error
+	UpdateProject(project Domain.Project) error
+	DeleteProject(id int) error
+	GetNextProjectID() int
+}
+
+type ProjectRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewProjectRepository(dbName string) IProjectRepository {
+	collection := client.Database(dbName).Collection("projects")
+	return &ProjectRepository{collection: collection, ctx: project_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (p *ProjectRepository) WithContext(ctx context.Context) IProjectRepository {
+	bound := *p
+	bound.ctx = ctx
+	return &bound
+}
+
+func (p *ProjectRepository) GetProjects() ([]Domain.Project, error) {
+	defer observe(p.ctx, "ProjectRepository", "GetProjects")()
+	return p.findProjects(bson.M{})
+}
+
+func (p *ProjectRepository) GetProjectsByMember(username string) ([]Domain.Project, error) {
+	defer observe(p.ctx, "ProjectRepository", "GetProjectsByMember")()
+	return p.findProjects(bson.M{"members.username": username})
+}
+
+func (p *ProjectRepository) GetProjectByID(id int) (Domain.Project, error) {
+	defer observe(p.ctx, "ProjectRepository", "GetProjectByID")()
+	var project Domain.Project
+	if err := p.collection.FindOne(p.ctx, bson.M{"id": id}).Decode(&project); err != nil {
+		return project, err
+	}
+	return project, nil
+}
+
+func (p *ProjectRepository) CreateProject(project Domain.Project) error {
+	defer observe(p.ctx, "ProjectRepository", "CreateProject")()
+	if _, err := p.collection.InsertOne(p.ctx, project); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *ProjectRepository) UpdateProject(project Domain.Project) error {
+	defer observe(p.ctx, "ProjectRepository", "UpdateProject")()
+	filter := bson.M{"id": project.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        project.Name,
+			"description": project.Description,
+			"members":     project.Members,
+			"columns":     project.Columns,
+		},
+	}
+	result, err := p.collection.UpdateOne(p.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+func (p *ProjectRepository) DeleteProject(id int) error {
+	defer observe(p.ctx, "ProjectRepository", "DeleteProject")()
+	result, err := p.collection.DeleteOne(p.ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("project not found")
+	}
+	return nil
+}
+
+func (p *ProjectRepository) GetNextProjectID() int {
+	defer observe(p.ctx, "ProjectRepository", "GetNextProjectID")()
+	var project Domain.Project
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := p.collection.FindOne(p.ctx, bson.D{}, findOptions).Decode(&project)
+	if err != nil {
+
+		return 1
+	}
+	return project.ID + 1
+}
+
+func (p *ProjectRepository) findProjects(filter bson.M) ([]Domain.Project, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := p.collection.Find(p.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []Domain.Project{}
+	if err := cursor.All(p.ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
@@ -0,0 +1,95 @@
+package Repositories
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var rate_limit_ctx = GetContext()
+
+// IRateLimitRepository keeps the token buckets of the rate limits in the
+// instance database, where every instance of the service shares them.
+type IRateLimitRepository interface {
+	WithContext(ctx context.Context) IRateLimitRepository
+	Take(key string, burst, rate float64, now time.Time) (float64, bool, error)
+}
+
+type RateLimitRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewRateLimitRepository(dbName string) IRateLimitRepository {
+	collection := client.Database(dbName).Collection("rate_limits")
+
+	// Buckets are dropped once full again, when they are as good as new.
+	expiryIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires", Value: 1}},
+		Options: options.Index().SetName("rate_limit_expiry").SetExpireAfterSeconds(0),
+	}
+	if _, err := collection.Indexes().CreateOne(rate_limit_ctx, expiryIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &RateLimitRepository{collection: collection, ctx: rate_limit_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (r *RateLimitRepository) WithContext(ctx context.Context) IRateLimitRepository {
+	bound := *r
+	bound.ctx = ctx
+	return &bound
+}
+
+// Take takes a token from the bucket of key, which holds up to burst tokens
+// and gains rate tokens per second, as of now. It returns the tokens left
+// and whether there was one to take. The bucket is refilled and taken from
+// in a single update, so that concurrent requests, from any instance, never
+// take the same token.
+func (r *RateLimitRepository) Take(key string, burst, rate float64, now time.Time) (float64, bool, error) {
+	defer observe(r.ctx, "RateLimitRepository", "Take")()
+
+	elapsed := bson.M{"$max": bson.A{0, bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated", now}}}}, 1000,
+	}}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{burst, bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$tokens", burst}},
+				bson.M{"$multiply": bson.A{elapsed, rate}},
+			}}}},
+			"updated": now,
+		}}},
+		{{Key: "$set", Value: bson.M{"taken": bson.M{"$gte": bson.A{"$tokens", 1}}}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$cond": bson.A{"$taken", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"expires": bson.M{"$add": bson.A{now, bson.M{"$multiply": bson.A{
+				bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{burst, "$tokens"}}, rate}}, 1000,
+			}}}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket struct {
+		Tokens float64 `bson:"tokens"`
+		Taken  bool    `bson:"taken"`
+	}
+	err := r.collection.FindOneAndUpdate(r.ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another request created the bucket first: take from it.
+		err = r.collection.FindOneAndUpdate(r.ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	}
+	if err != nil {
+		slog.ErrorContext(r.ctx, "database query failed", "error", err)
+		return 0, false, err
+	}
+	return bucket.Tokens, bucket.Taken, nil
+}
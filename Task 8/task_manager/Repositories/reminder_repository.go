@@ -0,0 +1,109 @@
+package Repositories
+
+import (
+	"context"
+	"log/slog"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var reminder_ctx = GetContext()
+
+type IReminderRepository interface {
+	WithContext(ctx context.Context) IReminderRepository
+	Claim(reminder Domain.Reminder) (bool, error)
+	Release(reminder Domain.Reminder) error
+	GetReminders(taskID int) ([]Domain.Reminder, error)
+	GetNextReminderID() int
+}
+
+type ReminderRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewReminderRepository(dbName string) IReminderRepository {
+	collection := client.Database(dbName).Collection("reminders")
+
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "taskid", Value: 1},
+			{Key: "kind", Value: 1},
+			{Key: "leadminutes", Value: 1},
+			{Key: "duedate", Value: 1},
+		},
+		Options: options.Index().SetName("reminder_once").SetUnique(true),
+	}
+	if _, err := collection.Indexes().CreateOne(reminder_ctx, index); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &ReminderRepository{collection: collection, ctx: reminder_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (r *ReminderRepository) WithContext(ctx context.Context) IReminderRepository {
+	bound := *r
+	bound.ctx = ctx
+	return &bound
+}
+
+// Claim records a reminder before it is sent. It returns false when the same
+// reminder has already been recorded, by this process or another one.
+func (r *ReminderRepository) Claim(reminder Domain.Reminder) (bool, error) {
+	defer observe(r.ctx, "ReminderRepository", "Claim")()
+	if _, err := r.collection.InsertOne(r.ctx, reminder); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release forgets a claimed reminder that could not be sent, so that it is
+// tried again.
+func (r *ReminderRepository) Release(reminder Domain.Reminder) error {
+	defer observe(r.ctx, "ReminderRepository", "Release")()
+	filter := bson.M{
+		"taskid":      reminder.TaskID,
+		"kind":        reminder.Kind,
+		"leadminutes": reminder.LeadMinutes,
+		"duedate":     reminder.DueDate,
+	}
+	if _, err := r.collection.DeleteOne(r.ctx, filter); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ReminderRepository) GetReminders(taskID int) ([]Domain.Reminder, error) {
+	defer observe(r.ctx, "ReminderRepository", "GetReminders")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := r.collection.Find(r.ctx, bson.M{"taskid": taskID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := []Domain.Reminder{}
+	if err := cursor.All(r.ctx, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *ReminderRepository) GetNextReminderID() int {
+	defer observe(r.ctx, "ReminderRepository", "GetNextReminderID")()
+	var reminder Domain.Reminder
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := r.collection.FindOne(r.ctx, bson.D{}, findOptions).Decode(&reminder)
+	if err != nil {
+
+		return 1
+	}
+	return reminder.ID + 1
+}
@@ -0,0 +1,290 @@
+package Repositories
+
+import (
+	"context"
+	"sort"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var report_ctx = GetContext()
+
+// IReportRepository computes statistics over the tasks that are not in the
+// trash.
+type IReportRepository interface {
+	WithContext(ctx context.Context) IReportRepository
+	Throughput(filter Domain.ReportFilter) ([]Domain.ThroughputBucket, error)
+	Durations(filter Domain.ReportFilter) (Domain.TaskDurations, error)
+	Overdue(filter Domain.ReportFilter) (Domain.OverdueReport, error)
+	Workload(filter Domain.ReportFilter) ([]Domain.Workload, error)
+}
+
+// ReportRepository computes reports with aggregation pipelines over the task
+// collection.
+type ReportRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewReportRepository(dbName string) IReportRepository {
+	collection := client.Database(dbName).Collection("tasks")
+	return &ReportRepository{collection: collection, ctx: report_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (r *ReportRepository) WithContext(ctx context.Context) IReportRepository {
+	bound := *r
+	bound.ctx = ctx
+	return &bound
+}
+
+// Throughput counts the tasks created and completed in each period.
+func (r *ReportRepository) Throughput(filter Domain.ReportFilter) ([]Domain.ThroughputBucket, error) {
+	defer observe(r.ctx, "ReportRepository", "Throughput")()
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$match": bson.M{field: reportTimeRange(filter)}},
+			bson.M{"$group": bson.M{
+				"_id":   bson.M{"$dateToString": bson.M{"format": periodFormats[filter.Interval], "date": "$" + field}},
+				"count": bson.M{"$sum": 1},
+			}},
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: reportQuery(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"created":   countBy("createdat"),
+			"completed": countBy("completedat"),
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(r.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	type bucket struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	var results []struct {
+		Created   []bucket `bson:"created"`
+		Completed []bucket `bson:"completed"`
+	}
+	if err := cursor.All(r.ctx, &results); err != nil {
+		return nil, err
+	}
+
+	created, completed := map[string]int{}, map[string]int{}
+	if len(results) > 0 {
+		for _, b := range results[0].Created {
+			created[b.ID] = b.Count
+		}
+		for _, b := range results[0].Completed {
+			completed[b.ID] = b.Count
+		}
+	}
+	return throughputBuckets(created, completed), nil
+}
+
+// Durations returns the lead and cycle times of the tasks completed in the
+// filter's period.
+func (r *ReportRepository) Durations(filter Domain.ReportFilter) (Domain.TaskDurations, error) {
+	defer observe(r.ctx, "ReportRepository", "Durations")()
+	hoursSince := func(field string) bson.M {
+		return bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$completedat", field}}, 3600000}}
+	}
+	match := reportQuery(filter)
+	match["completedat"] = reportTimeRange(filter)
+	match["createdat"] = bson.M{"$type": "date"}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{
+			"lead": hoursSince("$createdat"),
+			"cycle": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{bson.M{"$type": "$startedat"}, "date"}},
+				hoursSince("$startedat"),
+				"$$REMOVE",
+			}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"lead":  bson.M{"$push": "$lead"},
+			"cycle": bson.M{"$push": "$cycle"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(r.ctx, pipeline)
+	if err != nil {
+		return Domain.TaskDurations{}, err
+	}
+	var results []struct {
+		Lead  []float64 `bson:"lead"`
+		Cycle []float64 `bson:"cycle"`
+	}
+	if err := cursor.All(r.ctx, &results); err != nil {
+		return Domain.TaskDurations{}, err
+	}
+
+	durations := Domain.TaskDurations{LeadTimes: []float64{}, CycleTimes: []float64{}}
+	if len(results) > 0 {
+		durations.LeadTimes = append(durations.LeadTimes, results[0].Lead...)
+		durations.CycleTimes = append(durations.CycleTimes, results[0].Cycle...)
+	}
+	sort.Float64s(durations.LeadTimes)
+	sort.Float64s(durations.CycleTimes)
+	return durations, nil
+}
+
+// Overdue counts the tasks marked overdue, per assignee and priority.
+func (r *ReportRepository) Overdue(filter Domain.ReportFilter) (Domain.OverdueReport, error) {
+	defer observe(r.ctx, "ReportRepository", "Overdue")()
+	countBy := func(field string) bson.A {
+		return bson.A{bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}}}
+	}
+	match := reportQuery(filter)
+	match["overdue"] = true
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"total":    bson.A{bson.M{"$count": "count"}},
+			"assignee": countBy("$assignee"),
+			"priority": countBy("$priority"),
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(r.ctx, pipeline)
+	if err != nil {
+		return Domain.OverdueReport{}, err
+	}
+	type bucket struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	var results []struct {
+		Total    []bucket `bson:"total"`
+		Assignee []bucket `bson:"assignee"`
+		Priority []bucket `bson:"priority"`
+	}
+	if err := cursor.All(r.ctx, &results); err != nil {
+		return Domain.OverdueReport{}, err
+	}
+
+	report := Domain.OverdueReport{ByAssignee: map[string]int{}, ByPriority: map[string]int{}}
+	if len(results) == 0 {
+		return report, nil
+	}
+	for _, b := range results[0].Total {
+		report.Total = b.Count
+	}
+	for _, b := range results[0].Assignee {
+		report.ByAssignee[b.ID] = b.Count
+	}
+	for _, b := range results[0].Priority {
+		report.ByPriority[b.ID] = b.Count
+	}
+	return report, nil
+}
+
+// Workload sums the open tasks of each assignee.
+func (r *ReportRepository) Workload(filter Domain.ReportFilter) ([]Domain.Workload, error) {
+	defer observe(r.ctx, "ReportRepository", "Workload")()
+	match := reportQuery(filter)
+	match["status"] = bson.M{"$not": exactRegex(Domain.StatusCompleted)}
+	started := bson.M{"$cond": bson.A{
+		bson.M{"$in": bson.A{bson.M{"$toLower": "$status"}, bson.A{"", Domain.StatusPending}}}, 0, 1,
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$assignee",
+			"open":        bson.M{"$sum": 1},
+			"inprogress":  bson.M{"$sum": started},
+			"overdue":     bson.M{"$sum": bson.M{"$cond": bson.A{"$overdue", 1, 0}}},
+			"storypoints": bson.M{"$sum": "$storypoints"},
+			"estimate":    bson.M{"$sum": "$estimate"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(r.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Assignee    string  `bson:"_id"`
+		Open        int     `bson:"open"`
+		InProgress  int     `bson:"inprogress"`
+		Overdue     int     `bson:"overdue"`
+		StoryPoints float64 `bson:"storypoints"`
+		Estimate    int     `bson:"estimate"`
+	}
+	if err := cursor.All(r.ctx, &results); err != nil {
+		return nil, err
+	}
+
+	workloads := []Domain.Workload{}
+	for _, result := range results {
+		workloads = append(workloads, Domain.Workload{
+			Assignee:        result.Assignee,
+			Open:            result.Open,
+			InProgress:      result.InProgress,
+			Overdue:         result.Overdue,
+			StoryPoints:     result.StoryPoints,
+			EstimateMinutes: result.Estimate,
+		})
+	}
+	return workloads, nil
+}
+
+// periodFormats are the $dateToString formats naming the period of each
+// interval, matching periodOf.
+var periodFormats = map[string]string{
+	Domain.IntervalDay:   "%Y-%m-%d",
+	Domain.IntervalWeek:  "%G-W%V",
+	Domain.IntervalMonth: "%Y-%m",
+}
+
+func reportQuery(filter Domain.ReportFilter) bson.M {
+	query := bson.M{"deletedat": nil}
+	if filter.ProjectID != 0 {
+		query["projectid"] = filter.ProjectID
+	}
+	return query
+}
+
+// reportTimeRange matches the times within the filter's period, and only
+// times: tasks without the time are left out.
+func reportTimeRange(filter Domain.ReportFilter) bson.M {
+	condition := bson.M{"$type": "date"}
+	if !filter.From.IsZero() {
+		condition["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		condition["$lt"] = filter.To
+	}
+	return condition
+}
+
+// throughputBuckets merges the tasks created and completed per period into
+// buckets in chronological order.
+func throughputBuckets(created, completed map[string]int) []Domain.ThroughputBucket {
+	periods := []string{}
+	for period := range created {
+		periods = append(periods, period)
+	}
+	for period := range completed {
+		if _, ok := created[period]; !ok {
+			periods = append(periods, period)
+		}
+	}
+	sort.Strings(periods)
+
+	buckets := make([]Domain.ThroughputBucket, len(periods))
+	for i, period := range periods {
+		buckets[i] = Domain.ThroughputBucket{Period: period, Created: created[period], Completed: completed[period]}
+	}
+	return buckets
+}
@@ -0,0 +1,331 @@
+package Repositories
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var search_ctx = GetContext()
+
+// ISearchRepository finds tasks and comments by their text. Implementations
+// that keep their own index are fed every change through the Index and
+// Remove methods; the others ignore them.
+type ISearchRepository interface {
+	WithContext(ctx context.Context) ISearchRepository
+	IndexTask(task Domain.Task)
+	IndexComment(comment Domain.Comment)
+	RemoveTask(id int)
+	RemoveComment(id int)
+	Search(query Domain.SearchQuery, limit int) ([]Domain.SearchHit, error)
+}
+
+// SearchRepository searches the task and comment collections through MongoDB
+// text indexes. Prefixes, which text indexes cannot match, are looked up with
+// regular expressions instead.
+type SearchRepository struct {
+	tasks    *mongo.Collection
+	comments *mongo.Collection
+	ctx      context.Context
+}
+
+func NewSearchRepository(dbName string) ISearchRepository {
+	tasks := client.Database(dbName).Collection("tasks")
+	comments := client.Database(dbName).Collection("comments")
+
+	taskIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+		Options: options.Index().
+			SetName("task_text").
+			SetWeights(bson.M{"title": 3, "description": 1}).
+			SetDefaultLanguage("none"),
+	}
+	if _, err := tasks.Indexes().CreateOne(search_ctx, taskIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	commentIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "body", Value: "text"}},
+		Options: options.Index().SetName("comment_text").SetDefaultLanguage("none"),
+	}
+	if _, err := comments.Indexes().CreateOne(search_ctx, commentIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &SearchRepository{tasks: tasks, comments: comments, ctx: search_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (s *SearchRepository) WithContext(ctx context.Context) ISearchRepository {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+func (s *SearchRepository) IndexTask(task Domain.Task)          {}
+func (s *SearchRepository) IndexComment(comment Domain.Comment) {}
+func (s *SearchRepository) RemoveTask(id int)                   {}
+func (s *SearchRepository) RemoveComment(id int)                {}
+
+func (s *SearchRepository) Search(query Domain.SearchQuery, limit int) ([]Domain.SearchHit, error) {
+	defer observe(s.ctx, "SearchRepository", "Search")()
+	taskFilter := searchTaskFilter(query)
+	if !query.HasText() {
+		return s.filterTasks(taskFilter, limit)
+	}
+
+	scores := map[int]float64{}
+	tasks := map[int]Domain.Task{}
+	if err := searchCollection(s.ctx, s.tasks, taskFilter, query, []string{"title", "description"}, func(cursor *mongo.Cursor, score float64) error {
+		var task Domain.Task
+		if err := cursor.Decode(&task); err != nil {
+			return err
+		}
+		scores[task.ID] += score
+		tasks[task.ID] = task
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	hits := []Domain.SearchHit{}
+	for id, task := range tasks {
+		hits = append(hits, Domain.SearchHit{
+			Type:   Domain.SearchTask,
+			TaskID: id,
+			Title:  task.Title,
+			Score:  scores[id],
+			Fields: map[string]string{"title": task.Title, "description": task.Description},
+		})
+	}
+
+	commentScores := map[int]float64{}
+	comments := map[int]Domain.Comment{}
+	if err := searchCollection(s.ctx, s.comments, bson.M{"deleted": false}, query, []string{"body"}, func(cursor *mongo.Cursor, score float64) error {
+		var comment Domain.Comment
+		if err := cursor.Decode(&comment); err != nil {
+			return err
+		}
+		commentScores[comment.ID] += score
+		comments[comment.ID] = comment
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Comments only count when their task is live and matches the qualifiers,
+	// which is only looked up for the tasks of the matching comments.
+	ids := []int{}
+	for _, comment := range comments {
+		if _, found := tasks[comment.TaskID]; !found {
+			ids = append(ids, comment.TaskID)
+		}
+	}
+	if len(ids) > 0 {
+		filter := bson.M{"id": bson.M{"$in": ids}}
+		for key, value := range taskFilter {
+			filter[key] = value
+		}
+		cursor, err := s.tasks.Find(s.ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		var commented []Domain.Task
+		if err := cursor.All(s.ctx, &commented); err != nil {
+			return nil, err
+		}
+		for _, task := range commented {
+			tasks[task.ID] = task
+		}
+	}
+	for id, comment := range comments {
+		task, live := tasks[comment.TaskID]
+		if !live {
+			continue
+		}
+		hits = append(hits, Domain.SearchHit{
+			Type:      Domain.SearchComment,
+			TaskID:    comment.TaskID,
+			CommentID: id,
+			Title:     task.Title,
+			Score:     commentScores[id],
+			Fields:    map[string]string{"body": comment.Body},
+		})
+	}
+
+	SortSearchHits(hits)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// filterTasks returns the tasks matching a query made only of qualifiers, by
+// ascending ID.
+func (s *SearchRepository) filterTasks(filter bson.M, limit int) ([]Domain.SearchHit, error) {
+	findOptions := options.Find().SetSort(bson.M{"id": 1})
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+	}
+	cursor, err := s.tasks.Find(s.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Domain.Task
+	if err := cursor.All(s.ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	hits := []Domain.SearchHit{}
+	for _, task := range tasks {
+		hits = append(hits, Domain.SearchHit{
+			Type:   Domain.SearchTask,
+			TaskID: task.ID,
+			Title:  task.Title,
+			Fields: map[string]string{"title": task.Title, "description": task.Description},
+		})
+	}
+	return hits, nil
+}
+
+// SortSearchHits orders hits by descending score, then tasks before comments
+// and by ascending ID, so that results are stable.
+func SortSearchHits(hits []Domain.SearchHit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Type != hits[j].Type {
+			return hits[i].Type == Domain.SearchTask
+		}
+		if hits[i].TaskID != hits[j].TaskID {
+			return hits[i].TaskID < hits[j].TaskID
+		}
+		return hits[i].CommentID < hits[j].CommentID
+	})
+}
+
+// searchCollection runs the text part of the query and the prefix part of the
+// query against a collection and hands every match to visit with its score.
+// Text matches are scored by MongoDB; prefix matches score 1 each.
+func searchCollection(ctx context.Context, collection *mongo.Collection, filter bson.M, query Domain.SearchQuery, fields []string, visit func(cursor *mongo.Cursor, score float64) error) error {
+	if len(query.Terms) > 0 || len(query.Phrases) > 0 {
+		search := strings.Join(query.Terms, " ")
+		for _, phrase := range query.Phrases {
+			search += ` "` + phrase + `"`
+		}
+
+		textFilter := bson.M{"$text": bson.M{"$search": search}}
+		for key, value := range filter {
+			textFilter[key] = value
+		}
+		findOptions := options.Find().
+			SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+			SetSort(bson.M{"score": bson.M{"$meta": "textScore"}})
+
+		cursor, err := collection.Find(ctx, textFilter, findOptions)
+		if err != nil {
+			return err
+		}
+		for cursor.Next(ctx) {
+			var scored struct {
+				Score float64 `bson:"score"`
+			}
+			if err := cursor.Decode(&scored); err != nil {
+				return err
+			}
+			if err := visit(cursor, scored.Score); err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+	}
+
+	if len(query.Prefixes) > 0 {
+		prefixes := bson.A{}
+		for _, prefix := range query.Prefixes {
+			for _, field := range fields {
+				prefixes = append(prefixes, bson.M{field: wordRegex(prefix, false)})
+			}
+		}
+		conditions := bson.A{filter, bson.M{"$or": prefixes}}
+		for _, phrase := range query.Phrases {
+			inAnyField := bson.A{}
+			for _, field := range fields {
+				inAnyField = append(inAnyField, bson.M{field: wordRegex(phrase, true)})
+			}
+			conditions = append(conditions, bson.M{"$or": inAnyField})
+		}
+
+		cursor, err := collection.Find(ctx, bson.M{"$and": conditions})
+		if err != nil {
+			return err
+		}
+		for cursor.Next(ctx) {
+			if err := visit(cursor, 1); err != nil {
+				return err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchTaskFilter matches the live tasks satisfying the qualifiers of the
+// query.
+func searchTaskFilter(query Domain.SearchQuery) bson.M {
+	filter := bson.M{"deletedat": nil}
+	if query.Status != "" {
+		filter["status"] = exactRegex(query.Status)
+	}
+	if query.Priority != "" {
+		filter["priority"] = exactRegex(query.Priority)
+	}
+	if query.Assignee != "" {
+		filter["assignee"] = query.Assignee
+	}
+	if query.Label != "" {
+		filter["labels"] = query.Label
+	}
+	if len(query.Due) > 0 {
+		operators := map[string]string{"<": "$lt", "<=": "$lte", ">": "$gt", ">=": "$gte", "=": "$eq"}
+		dueDay := bson.M{"$substrCP": bson.A{"$duedate", 0, 10}}
+		conditions := bson.A{bson.M{"$gt": bson.A{"$duedate", ""}}}
+		for _, due := range query.Due {
+			conditions = append(conditions, bson.M{operators[due.Op]: bson.A{dueDay, due.Date}})
+		}
+		filter["$expr"] = bson.M{"$and": conditions}
+	}
+	return filter
+}
+
+func exactRegex(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+}
+
+// wordRegex matches text starting at a word boundary: a whole phrase, or any
+// word starting with a prefix.
+func wordRegex(text string, phrase bool) primitive.Regex {
+	words := Domain.Tokenize(text)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	pattern := `\b` + strings.Join(words, `[^\p{L}\p{N}]+`)
+	if phrase {
+		pattern += `\b`
+	}
+	return primitive.Regex{Pattern: pattern, Options: "i"}
+}
@@ -0,0 +1,93 @@
+package Repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var history_ctx = GetContext()
+
+type ITaskHistoryRepository interface {
+	WithContext(ctx context.Context) ITaskHistoryRepository
+	SaveRevision(revision Domain.TaskRevision) error
+	GetRevisions(taskID int) ([]Domain.TaskRevision, error)
+	GetRevision(taskID, revision int) (Domain.TaskRevision, error)
+	GetNextRevision(taskID int) int
+	DeleteRevisions(taskIDs []int) error
+}
+
+type TaskHistoryRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewTaskHistoryRepository(dbName string) ITaskHistoryRepository {
+	collection := client.Database(dbName).Collection("task_revisions")
+	return &TaskHistoryRepository{collection: collection, ctx: history_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (h *TaskHistoryRepository) WithContext(ctx context.Context) ITaskHistoryRepository {
+	bound := *h
+	bound.ctx = ctx
+	return &bound
+}
+
+func (h *TaskHistoryRepository) SaveRevision(revision Domain.TaskRevision) error {
+	defer observe(h.ctx, "TaskHistoryRepository", "SaveRevision")()
+	if _, err := h.collection.InsertOne(h.ctx, revision); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *TaskHistoryRepository) GetRevisions(taskID int) ([]Domain.TaskRevision, error) {
+	defer observe(h.ctx, "TaskHistoryRepository", "GetRevisions")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "revision", Value: 1}})
+	cursor, err := h.collection.Find(h.ctx, bson.M{"taskid": taskID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []Domain.TaskRevision{}
+	if err := cursor.All(h.ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (h *TaskHistoryRepository) GetRevision(taskID, revision int) (Domain.TaskRevision, error) {
+	defer observe(h.ctx, "TaskHistoryRepository", "GetRevision")()
+	filter := bson.M{"taskid": taskID, "revision": revision}
+	var result Domain.TaskRevision
+	if err := h.collection.FindOne(h.ctx, filter).Decode(&result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (h *TaskHistoryRepository) GetNextRevision(taskID int) int {
+	defer observe(h.ctx, "TaskHistoryRepository", "GetNextRevision")()
+	var revision Domain.TaskRevision
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}})
+	err := h.collection.FindOne(h.ctx, bson.M{"taskid": taskID}, findOptions).Decode(&revision)
+	if err != nil {
+
+		return 1
+	}
+	return revision.Revision + 1
+}
+
+func (h *TaskHistoryRepository) DeleteRevisions(taskIDs []int) error {
+	defer observe(h.ctx, "TaskHistoryRepository", "DeleteRevisions")()
+	filter := bson.M{"taskid": bson.M{"$in": taskIDs}}
+	if _, err := h.collection.DeleteMany(h.ctx, filter); err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,45 +1,116 @@
 package Repositories
 
 import (
+	"context"
 	"errors"
 	"log"
+	"log/slog"
+	"strings"
 	"task_manager/Domain"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var (
-	task_ctx        = GetContext()
-	task_collection *mongo.Collection
-)
+var task_ctx = GetContext()
 
 type ITaskRepository interface {
+	WithContext(ctx context.Context) ITaskRepository
 	GetTasks() []Domain.Task
+	ListTasks() ([]Domain.Task, error)
 	CreateTask(task Domain.Task) error
 	GetTaskByID(id int) (Domain.Task, error)
 	GetNextTaskID() int
 	UpdateTask(id int, task Domain.Task) error
 	DeleteTask(id int) error
+	GetDeletedTasks() []Domain.Task
+	RestoreTask(id int) error
+	PurgeDeletedTasks(before time.Time) ([]int, error)
+	GetSubtasks(parentID int) []Domain.Task
+	GetBlockedTasks(blockerID int) []Domain.Task
+	AddDependency(id, blockerID int) error
+	RemoveDependency(id, blockerID int) error
+	GetTasksByProject(projectID int) []Domain.Task
+	SetTaskPosition(id int, status string, rank float64) error
+	FindTasks(filter Domain.TaskFilter) []Domain.Task
+	FacetTasks(filter Domain.TaskFilter) (Domain.TaskFacets, error)
+	RenameLabel(oldName, newName string) error
+	RemoveLabel(name string) error
+	GetSeries(seriesID int) []Domain.Task
+	GetLatestOccurrences() []Domain.Task
+	GetOpenTasksDueBefore(dueDate string) []Domain.Task
+	GetOverdueTasks() []Domain.Task
+	SetOverdue(id int, overdue bool) error
+	BulkWrite(writes []TaskWrite, atomic bool) ([]error, error)
+	GetTaskByExternalID(externalID string) (Domain.Task, error)
+}
+
+// TaskWrite is one of the changes applied by BulkWrite: a task to create,
+// update, or move to the trash at its DeletedAt time.
+type TaskWrite struct {
+	Op   string
+	Task Domain.Task
 }
 
-type TaskRepository struct{}
+// notDeleted matches the tasks that are not in the trash.
+var notDeleted = bson.M{"deletedat": nil}
+
+type TaskRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
 
 func NewTaskRepository(dbName string) ITaskRepository {
-	task_collection = client.Database(dbName).Collection("tasks")
-	return &TaskRepository{}
+	collection := client.Database(dbName).Collection("tasks")
+
+	// Two requests completing an occurrence at once cannot both create the
+	// next one.
+	seriesIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "seriesid", Value: 1}, {Key: "occurrence", Value: 1}},
+		Options: options.Index().
+			SetName("task_series").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"seriesid": bson.M{"$gt": 0}}),
+	}
+	if _, err := collection.Indexes().CreateOne(task_ctx, seriesIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	// Imports match tasks by their external ID, which is unique.
+	externalIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "externalid", Value: 1}},
+		Options: options.Index().
+			SetName("task_external_id").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"externalid": bson.M{"$gt": ""}}),
+	}
+	if _, err := collection.Indexes().CreateOne(task_ctx, externalIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &TaskRepository{collection: collection, ctx: task_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (t *TaskRepository) WithContext(ctx context.Context) ITaskRepository {
+	bound := *t
+	bound.ctx = ctx
+	return &bound
 }
 
 func (t *TaskRepository) GetTasks() []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetTasks")()
 	var tasks []Domain.Task
-	cursor, err := task_collection.Find(task_ctx, bson.M{})
+	cursor, err := t.collection.Find(t.ctx, notDeleted)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for cursor.Next(task_ctx) {
+	for cursor.Next(t.ctx) {
 		var task Domain.Task
 		if err := cursor.Decode(&task); err != nil {
 			log.Fatal(err)
@@ -51,27 +122,57 @@ func (t *TaskRepository) GetTasks() []Domain.Task {
 	return tasks
 }
 
+// ListTasks returns the tasks that are not in the trash, like GetTasks, but
+// returns the errors of MongoDB rather than exiting on them.
+func (t *TaskRepository) ListTasks() ([]Domain.Task, error) {
+	defer observe(t.ctx, "TaskRepository", "ListTasks")()
+	cursor, err := t.collection.Find(t.ctx, notDeleted)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []Domain.Task{}
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
 func (t *TaskRepository) CreateTask(task Domain.Task) error {
-	if _, err := task_collection.InsertOne(task_ctx, task); err != nil {
+	defer observe(t.ctx, "TaskRepository", "CreateTask")()
+	if _, err := t.collection.InsertOne(t.ctx, task); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (t *TaskRepository) GetTaskByID(id int) (Domain.Task, error) {
-	filter := bson.M{"id": id}
+	defer observe(t.ctx, "TaskRepository", "GetTaskByID")()
+	filter := bson.M{"id": id, "deletedat": nil}
 	var task Domain.Task
-	if err := task_collection.FindOne(task_ctx, filter).Decode(&task); err != nil {
+	if err := t.collection.FindOne(t.ctx, filter).Decode(&task); err != nil {
 		return task, err
 	}
 
 	return task, nil
 }
 
+// GetTaskByExternalID returns the task imported with the given external ID,
+// even if it is in the trash.
+func (t *TaskRepository) GetTaskByExternalID(externalID string) (Domain.Task, error) {
+	defer observe(t.ctx, "TaskRepository", "GetTaskByExternalID")()
+	var task Domain.Task
+	filter := bson.M{"externalid": externalID}
+	if err := t.collection.FindOne(t.ctx, filter).Decode(&task); err != nil {
+		return task, errors.New("task not found")
+	}
+	return task, nil
+}
+
 func (t *TaskRepository) GetNextTaskID() int {
+	defer observe(t.ctx, "TaskRepository", "GetNextTaskID")()
 	var task Domain.Task
 	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
-	err := task_collection.FindOne(task_ctx, bson.D{}, findOptions).Decode(&task)
+	err := t.collection.FindOne(t.ctx, bson.D{}, findOptions).Decode(&task)
 	if err != nil {
 
 		return 1
@@ -80,17 +181,11 @@ func (t *TaskRepository) GetNextTaskID() int {
 }
 
 func (t *TaskRepository) UpdateTask(id int, task Domain.Task) error {
-	filter := bson.M{"id": id}
+	defer observe(t.ctx, "TaskRepository", "UpdateTask")()
+	filter := bson.M{"id": id, "deletedat": nil}
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":       task.Title,
-			"description": task.Description,
-			"dueDate":     task.DueDate,
-			"status":      task.Status,
-		},
-	}
-	result := task_collection.FindOneAndUpdate(task_ctx, filter, update)
+	update := bson.M{"$set": taskFields(task)}
+	result := t.collection.FindOneAndUpdate(t.ctx, filter, update)
 	if result.Err() == mongo.ErrNoDocuments {
 		return errors.New("task not found")
 	}
@@ -104,14 +199,491 @@ func (t *TaskRepository) UpdateTask(id int, task Domain.Task) error {
 	return nil
 }
 
+// DeleteTask moves a task to the trash. It stays there until it is restored
+// or purged.
 func (t *TaskRepository) DeleteTask(id int) error {
-	filter := bson.M{"id": id}
-	result, err := task_collection.DeleteOne(task_ctx, filter)
+	defer observe(t.ctx, "TaskRepository", "DeleteTask")()
+	filter := bson.M{"id": id, "deletedat": nil}
+	update := bson.M{"$set": bson.M{"deletedat": time.Now().UTC()}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+func (t *TaskRepository) GetDeletedTasks() []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetDeletedTasks")()
+	tasks := []Domain.Task{}
+	filter := bson.M{"deletedat": bson.M{"$ne": nil}}
+	cursor, err := t.collection.Find(t.ctx, filter)
+	if err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+		return tasks
+	}
+
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+	}
+	return tasks
+}
+
+func (t *TaskRepository) RestoreTask(id int) error {
+	defer observe(t.ctx, "TaskRepository", "RestoreTask")()
+	filter := bson.M{"id": id, "deletedat": bson.M{"$ne": nil}}
+	update := bson.M{"$set": bson.M{"deletedat": nil}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("task not found in trash")
+	}
+	return nil
+}
+
+// PurgeDeletedTasks permanently removes the tasks that were moved to the
+// trash before the given time and returns their IDs.
+func (t *TaskRepository) PurgeDeletedTasks(before time.Time) ([]int, error) {
+	defer observe(t.ctx, "TaskRepository", "PurgeDeletedTasks")()
+	filter := bson.M{"deletedat": bson.M{"$ne": nil, "$lte": before}}
+	cursor, err := t.collection.Find(t.ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Domain.Task
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	if _, err := t.collection.DeleteMany(t.ctx, bson.M{"id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (t *TaskRepository) GetSubtasks(parentID int) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetSubtasks")()
+	return t.findTasks(bson.M{"parentid": parentID, "deletedat": nil})
+}
+
+// GetBlockedTasks returns the tasks that cannot be completed before the given
+// task.
+func (t *TaskRepository) GetBlockedTasks(blockerID int) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetBlockedTasks")()
+	return t.findTasks(bson.M{"blockedby": blockerID, "deletedat": nil})
+}
+
+func (t *TaskRepository) AddDependency(id, blockerID int) error {
+	defer observe(t.ctx, "TaskRepository", "AddDependency")()
+	filter := bson.M{"id": id, "deletedat": nil}
+	update := bson.M{"$addToSet": bson.M{"blockedby": blockerID}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+func (t *TaskRepository) RemoveDependency(id, blockerID int) error {
+	defer observe(t.ctx, "TaskRepository", "RemoveDependency")()
+	filter := bson.M{"id": id, "deletedat": nil}
+	update := bson.M{"$pull": bson.M{"blockedby": blockerID}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+// GetTasksByProject returns the tasks of a project in board order.
+func (t *TaskRepository) GetTasksByProject(projectID int) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetTasksByProject")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "id", Value: 1}})
+	return t.findTasks(bson.M{"projectid": projectID, "deletedat": nil}, findOptions)
+}
+
+// SetTaskPosition moves a task to another board column and position.
+func (t *TaskRepository) SetTaskPosition(id int, status string, rank float64) error {
+	defer observe(t.ctx, "TaskRepository", "SetTaskPosition")()
+	filter := bson.M{"id": id, "deletedat": nil}
+	update := bson.M{"$set": bson.M{"status": status, "rank": rank}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+func (t *TaskRepository) FindTasks(filter Domain.TaskFilter) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "FindTasks")()
+	return t.findTasks(taskFilterQuery(filter))
+}
+
+// FacetTasks counts the tasks matching the filter per label, status and
+// priority in a single aggregation.
+func (t *TaskRepository) FacetTasks(filter Domain.TaskFilter) (Domain.TaskFacets, error) {
+	defer observe(t.ctx, "TaskRepository", "FacetTasks")()
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: taskFilterQuery(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"total":    bson.A{bson.M{"$count": "count"}},
+			"labels":   append(bson.A{bson.M{"$unwind": "$labels"}}, countBy("$labels")...),
+			"status":   countBy("$status"),
+			"priority": countBy("$priority"),
+		}}},
+	}
+
+	cursor, err := t.collection.Aggregate(t.ctx, pipeline)
+	if err != nil {
+		return Domain.TaskFacets{}, err
+	}
+
+	type bucket struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	var results []struct {
+		Total    []bucket `bson:"total"`
+		Labels   []bucket `bson:"labels"`
+		Status   []bucket `bson:"status"`
+		Priority []bucket `bson:"priority"`
+	}
+	if err := cursor.All(t.ctx, &results); err != nil {
+		return Domain.TaskFacets{}, err
+	}
+
+	facets := Domain.TaskFacets{Labels: map[string]int{}, Status: map[string]int{}, Priority: map[string]int{}}
+	if len(results) == 0 {
+		return facets, nil
+	}
+	for _, b := range results[0].Total {
+		facets.Total = b.Count
+	}
+	for _, b := range results[0].Labels {
+		facets.Labels[b.ID] = b.Count
+	}
+	for _, b := range results[0].Status {
+		facets.Status[b.ID] = b.Count
+	}
+	for _, b := range results[0].Priority {
+		facets.Priority[b.ID] = b.Count
+	}
+	return facets, nil
+}
+
+func (t *TaskRepository) RenameLabel(oldName, newName string) error {
+	defer observe(t.ctx, "TaskRepository", "RenameLabel")()
+	filter := bson.M{"labels": oldName}
+	update := bson.M{"$set": bson.M{"labels.$": newName}}
+	if _, err := t.collection.UpdateMany(t.ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TaskRepository) RemoveLabel(name string) error {
+	defer observe(t.ctx, "TaskRepository", "RemoveLabel")()
+	filter := bson.M{"labels": name}
+	update := bson.M{"$pull": bson.M{"labels": name}}
+	if _, err := t.collection.UpdateMany(t.ctx, filter, update); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSeries returns the occurrences of a recurring task in order, including
+// the ones in the trash.
+func (t *TaskRepository) GetSeries(seriesID int) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetSeries")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "occurrence", Value: 1}})
+	return t.findTasks(bson.M{"seriesid": seriesID}, findOptions)
+}
+
+// GetLatestOccurrences returns the last occurrence of every series of
+// recurring tasks, whether or not it is in the trash.
+func (t *TaskRepository) GetLatestOccurrences() []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetLatestOccurrences")()
+	tasks := []Domain.Task{}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"seriesid": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "seriesid", Value: 1}, {Key: "occurrence", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$seriesid", "task": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$task"}}},
+	}
+	cursor, err := t.collection.Aggregate(t.ctx, pipeline)
+	if err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+		return tasks
+	}
+
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+	}
+	return tasks
+}
+
+// GetOpenTasksDueBefore returns the tasks that are not completed and have a
+// due date sorting before the given one.
+func (t *TaskRepository) GetOpenTasksDueBefore(dueDate string) []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetOpenTasksDueBefore")()
+	filter := bson.M{
+		"deletedat": nil,
+		"duedate":   bson.M{"$gt": "", "$lt": dueDate},
+		"status":    bson.M{"$not": exactRegex(Domain.StatusCompleted)},
+	}
+	return t.findTasks(filter)
+}
+
+func (t *TaskRepository) GetOverdueTasks() []Domain.Task {
+	defer observe(t.ctx, "TaskRepository", "GetOverdueTasks")()
+	return t.findTasks(bson.M{"overdue": true, "deletedat": nil})
+}
+
+func (t *TaskRepository) SetOverdue(id int, overdue bool) error {
+	defer observe(t.ctx, "TaskRepository", "SetOverdue")()
+	filter := bson.M{"id": id, "deletedat": nil}
+	update := bson.M{"$set": bson.M{"overdue": overdue}}
+	result, err := t.collection.UpdateOne(t.ctx, filter, update)
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.New("task not found")
 	}
 	return nil
 }
+
+// ErrTransactionsUnsupported is returned by atomic writes when MongoDB does
+// not run as a replica set, which transactions need.
+var ErrTransactionsUnsupported = errors.New("transactions need MongoDB to run as a replica set")
+
+// errTaskNotFound is the error of the updates and deletes whose task is
+// gone, or already in the trash, by the time they are written.
+var errTaskNotFound = errors.New("task not found")
+
+// BulkWrite applies the writes in one round trip. In atomic mode they are
+// applied in a transaction, which needs MongoDB to run as a replica set:
+// either every write is applied or none is, and the error says why. Otherwise
+// the writes that fail do not stop the others, and the returned slice holds
+// the error of each write, or nil.
+func (t *TaskRepository) BulkWrite(writes []TaskWrite, atomic bool) ([]error, error) {
+	defer observe(t.ctx, "TaskRepository", "BulkWrite")()
+	errs := make([]error, len(writes))
+	if len(writes) == 0 {
+		return errs, nil
+	}
+
+	models := []mongo.WriteModel{}
+	for _, write := range writes {
+		filter := bson.M{"id": write.Task.ID, "deletedat": nil}
+		switch write.Op {
+		case Domain.BulkCreate:
+			models = append(models, mongo.NewInsertOneModel().SetDocument(write.Task))
+		case Domain.BulkUpdate:
+			models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": taskFields(write.Task)}))
+		case Domain.BulkDelete:
+			models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": bson.M{"deletedat": write.Task.DeletedAt}}))
+		default:
+			return nil, errors.New("unknown write " + write.Op)
+		}
+	}
+
+	if !atomic {
+		result, err := t.collection.BulkWrite(t.ctx, models, options.BulkWrite().SetOrdered(false))
+		var bulkErr mongo.BulkWriteException
+		if err != nil && !errors.As(err, &bulkErr) {
+			return errs, err
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			errs[writeErr.Index] = errors.New(writeErr.Message)
+		}
+		// The result only counts the matches of every write together, so
+		// the writes that matched nothing are only looked for when some did.
+		if result != nil && int(result.MatchedCount) < t.expectedMatches(writes, errs) {
+			if err := t.markUnmatched(writes, errs); err != nil {
+				return errs, err
+			}
+		}
+		return errs, nil
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(t.ctx)
+	_, err = session.WithTransaction(t.ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		result, err := t.collection.BulkWrite(sc, models)
+		if err != nil {
+			return nil, err
+		}
+		if int(result.InsertedCount+result.MatchedCount) != len(models) {
+			return nil, errTaskNotFound
+		}
+		return nil, nil
+	})
+	if transactionsUnsupported(err) {
+		return errs, ErrTransactionsUnsupported
+	}
+	return errs, err
+}
+
+// expectedMatches counts the updates and deletes that did not fail, each of
+// which should have matched its task.
+func (t *TaskRepository) expectedMatches(writes []TaskWrite, errs []error) int {
+	expected := 0
+	for i, write := range writes {
+		if write.Op != Domain.BulkCreate && errs[i] == nil {
+			expected++
+		}
+	}
+	return expected
+}
+
+// markUnmatched sets the error of the updates and deletes that matched no
+// task. An update matched if its task is still out of the trash, and a
+// delete if its task went to the trash at the time it gave.
+func (t *TaskRepository) markUnmatched(writes []TaskWrite, errs []error) error {
+	ids := []int{}
+	for i, write := range writes {
+		if write.Op != Domain.BulkCreate && errs[i] == nil {
+			ids = append(ids, write.Task.ID)
+		}
+	}
+	cursor, err := t.collection.Find(t.ctx, bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	var tasks []Domain.Task
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		return err
+	}
+	byID := map[int]Domain.Task{}
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	for i, write := range writes {
+		if write.Op == Domain.BulkCreate || errs[i] != nil {
+			continue
+		}
+		task, ok := byID[write.Task.ID]
+		matched := ok && task.DeletedAt == nil
+		if write.Op == Domain.BulkDelete {
+			// MongoDB keeps times to the millisecond.
+			matched = ok && task.DeletedAt != nil && write.Task.DeletedAt != nil &&
+				task.DeletedAt.Equal(write.Task.DeletedAt.Truncate(time.Millisecond))
+		}
+		if !matched {
+			errs[i] = errTaskNotFound
+		}
+	}
+	return nil
+}
+
+// transactionsUnsupported reports whether err is the one MongoDB returns
+// for transactions on a standalone server.
+func transactionsUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == 20 {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "Transaction numbers are only allowed on a replica set member or mongos")
+}
+
+// taskFields are the fields set when a task is updated.
+func taskFields(task Domain.Task) bson.M {
+	return bson.M{
+		"title":       task.Title,
+		"description": task.Description,
+		"duedate":     task.DueDate,
+		"status":      task.Status,
+		"assignee":    task.Assignee,
+		"parentid":    task.ParentID,
+		"checklist":   task.Checklist,
+		"projectid":   task.ProjectID,
+		"rank":        task.Rank,
+		"labels":      task.Labels,
+		"priority":    task.Priority,
+		"storypoints": task.StoryPoints,
+		"estimate":    task.Estimate,
+		"recurrence":  task.Recurrence,
+		"seriesid":    task.SeriesID,
+		"occurrence":  task.Occurrence,
+		"overdue":     task.Overdue,
+		"externalid":  task.ExternalID,
+		"createdat":   task.CreatedAt,
+		"startedat":   task.StartedAt,
+		"completedat": task.CompletedAt,
+	}
+}
+
+// taskFilterQuery builds the query matching the tasks selected by a filter,
+// leaving out the tasks in the trash.
+func taskFilterQuery(filter Domain.TaskFilter) bson.M {
+	query := bson.M{"deletedat": nil}
+	labels := bson.M{}
+	if len(filter.Labels) > 0 {
+		labels["$all"] = filter.Labels
+	}
+	if len(filter.AnyLabels) > 0 {
+		labels["$in"] = filter.AnyLabels
+	}
+	if len(labels) > 0 {
+		query["labels"] = labels
+	}
+	if len(filter.Priorities) > 0 {
+		query["priority"] = bson.M{"$in": filter.Priorities}
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if len(filter.Assignees) > 0 {
+		query["assignee"] = bson.M{"$in": filter.Assignees}
+	}
+	if filter.Overdue {
+		query["overdue"] = true
+	}
+	return query
+}
+
+func (t *TaskRepository) findTasks(filter bson.M, opts ...*options.FindOptions) []Domain.Task {
+	tasks := []Domain.Task{}
+	cursor, err := t.collection.Find(t.ctx, filter, opts...)
+	if err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+		return tasks
+	}
+
+	if err := cursor.All(t.ctx, &tasks); err != nil {
+		slog.ErrorContext(t.ctx, "database query failed", "error", err)
+	}
+	return tasks
+}
@@ -1,6 +1,7 @@
 package Repositories
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"task_manager/Domain"
@@ -10,35 +11,47 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var (
-	user_ctx        = GetContext()
-	user_collection *mongo.Collection
-)
+var user_ctx = GetContext()
 
 type IUserRepository interface {
+	WithContext(ctx context.Context) IUserRepository
 	GetUsers() []Domain.User
 	CreateUser(user Domain.User) error
 	Promote(id int) error
 	GetUserbyUsername(username string) (Domain.User, error)
+	GetUserByID(id int) (Domain.User, error)
+	GetUsersByUsernames(usernames []string) ([]Domain.User, error)
 	GetNextUserID() int
 }
 
-type UserRepository struct{}
+type UserRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
 
 func NewUserRepository(dbName string) IUserRepository {
-	user_collection = client.Database(dbName).Collection("users")
-	return &UserRepository{}
+	collection := client.Database(dbName).Collection("users")
+	return &UserRepository{collection: collection, ctx: user_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (u *UserRepository) WithContext(ctx context.Context) IUserRepository {
+	bound := *u
+	bound.ctx = ctx
+	return &bound
 }
 
 func (u *UserRepository) GetUsers() []Domain.User {
+	defer observe(u.ctx, "UserRepository", "GetUsers")()
 	var users []Domain.User
-	cursor, err := user_collection.Find(user_ctx, bson.M{})
+	cursor, err := u.collection.Find(u.ctx, bson.M{})
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for cursor.Next(user_ctx) {
+	for cursor.Next(u.ctx) {
 		var user Domain.User
 		if err := cursor.Decode(&user); err != nil {
 			log.Fatal(err)
@@ -51,15 +64,17 @@ func (u *UserRepository) GetUsers() []Domain.User {
 }
 
 func (u *UserRepository) CreateUser(user Domain.User) error {
-	if _, err := user_collection.InsertOne(user_ctx, user); err != nil {
+	defer observe(u.ctx, "UserRepository", "CreateUser")()
+	if _, err := u.collection.InsertOne(u.ctx, user); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u *UserRepository) Promote(id int) error {
+	defer observe(u.ctx, "UserRepository", "Promote")()
 	filter := bson.M{"id": id}
-	user := user_collection.FindOne(user_ctx, filter)
+	user := u.collection.FindOne(u.ctx, filter)
 
 	if err := user.Err(); err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -71,7 +86,7 @@ func (u *UserRepository) Promote(id int) error {
 	}
 
 	update := bson.M{"$set": bson.M{"role": "admin"}}
-	_, err := user_collection.UpdateOne(user_ctx, filter, update)
+	_, err := u.collection.UpdateOne(u.ctx, filter, update)
 	if err != nil {
 
 		return err
@@ -80,9 +95,10 @@ func (u *UserRepository) Promote(id int) error {
 }
 
 func (u *UserRepository) GetUserbyUsername(username string) (Domain.User, error) {
+	defer observe(u.ctx, "UserRepository", "GetUserbyUsername")()
 	filter := bson.M{"username": username}
 	var user Domain.User
-	err := user_collection.FindOne(user_ctx, filter).Decode(&user)
+	err := u.collection.FindOne(u.ctx, filter).Decode(&user)
 	if err != nil {
 		return user, err
 
@@ -90,10 +106,39 @@ func (u *UserRepository) GetUserbyUsername(username string) (Domain.User, error)
 	return user, nil
 }
 
+func (u *UserRepository) GetUserByID(id int) (Domain.User, error) {
+	defer observe(u.ctx, "UserRepository", "GetUserByID")()
+	filter := bson.M{"id": id}
+	var user Domain.User
+	if err := u.collection.FindOne(u.ctx, filter).Decode(&user); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// GetUsersByUsernames returns the users of the given usernames, in one query.
+// Usernames without a user are left out.
+func (u *UserRepository) GetUsersByUsernames(usernames []string) ([]Domain.User, error) {
+	defer observe(u.ctx, "UserRepository", "GetUsersByUsernames")()
+	users := []Domain.User{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	cursor, err := u.collection.Find(u.ctx, bson.M{"username": bson.M{"$in": usernames}})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(u.ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (u *UserRepository) GetNextUserID() int {
+	defer observe(u.ctx, "UserRepository", "GetNextUserID")()
 	var user Domain.User
 	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
-	err := user_collection.FindOne(user_ctx, bson.D{}, findOptions).Decode(&user)
+	err := u.collection.FindOne(u.ctx, bson.D{}, findOptions).Decode(&user)
 	if err != nil {
 
 		return 1
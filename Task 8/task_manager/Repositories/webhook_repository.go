@@ -0,0 +1,228 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var webhook_ctx = GetContext()
+
+type IWebhookRepository interface {
+	WithContext(ctx context.Context) IWebhookRepository
+	GetWebhooks() ([]Domain.Webhook, error)
+	GetWebhookByID(id int) (Domain.Webhook, error)
+	GetSubscribers(eventType string) ([]Domain.Webhook, error)
+	CreateWebhook(webhook Domain.Webhook) error
+	UpdateWebhook(webhook Domain.Webhook) error
+	DeleteWebhook(id int) error
+	GetNextWebhookID() int
+	CreateDelivery(delivery Domain.WebhookDelivery) error
+	GetDeliveries(webhookID int, status string) ([]Domain.WebhookDelivery, error)
+	GetDeliveryByID(id int) (Domain.WebhookDelivery, error)
+	ClaimDueDelivery(now time.Time, lease time.Duration) (Domain.WebhookDelivery, error)
+	RecordAttempt(id int, attempt Domain.DeliveryAttempt, status string, nextAttemptAt time.Time) error
+	GetNextDeliveryID() int
+}
+
+type WebhookRepository struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+	ctx        context.Context
+}
+
+func NewWebhookRepository(dbName string) IWebhookRepository {
+	webhooks := client.Database(dbName).Collection("webhooks")
+	deliveries := client.Database(dbName).Collection("webhook_deliveries")
+	return &WebhookRepository{webhooks: webhooks, deliveries: deliveries, ctx: webhook_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (w *WebhookRepository) WithContext(ctx context.Context) IWebhookRepository {
+	bound := *w
+	bound.ctx = ctx
+	return &bound
+}
+
+func (w *WebhookRepository) GetWebhooks() ([]Domain.Webhook, error) {
+	defer observe(w.ctx, "WebhookRepository", "GetWebhooks")()
+	return w.findWebhooks(bson.M{})
+}
+
+func (w *WebhookRepository) GetWebhookByID(id int) (Domain.Webhook, error) {
+	defer observe(w.ctx, "WebhookRepository", "GetWebhookByID")()
+	var webhook Domain.Webhook
+	if err := w.webhooks.FindOne(w.ctx, bson.M{"id": id}).Decode(&webhook); err != nil {
+		return webhook, err
+	}
+	return webhook, nil
+}
+
+// GetSubscribers returns the enabled webhooks subscribed to an event type.
+func (w *WebhookRepository) GetSubscribers(eventType string) ([]Domain.Webhook, error) {
+	defer observe(w.ctx, "WebhookRepository", "GetSubscribers")()
+	return w.findWebhooks(bson.M{
+		"disabled": false,
+		"events":   bson.M{"$in": bson.A{eventType, Domain.WebhookAllEvents}},
+	})
+}
+
+func (w *WebhookRepository) CreateWebhook(webhook Domain.Webhook) error {
+	defer observe(w.ctx, "WebhookRepository", "CreateWebhook")()
+	if _, err := w.webhooks.InsertOne(w.ctx, webhook); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *WebhookRepository) UpdateWebhook(webhook Domain.Webhook) error {
+	defer observe(w.ctx, "WebhookRepository", "UpdateWebhook")()
+	filter := bson.M{"id": webhook.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"url":      webhook.URL,
+			"events":   webhook.Events,
+			"secret":   webhook.Secret,
+			"disabled": webhook.Disabled,
+		},
+	}
+	result, err := w.webhooks.UpdateOne(w.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("webhook not found")
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its deliveries.
+func (w *WebhookRepository) DeleteWebhook(id int) error {
+	defer observe(w.ctx, "WebhookRepository", "DeleteWebhook")()
+	result, err := w.webhooks.DeleteOne(w.ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("webhook not found")
+	}
+	if _, err := w.deliveries.DeleteMany(w.ctx, bson.M{"webhookid": id}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *WebhookRepository) GetNextWebhookID() int {
+	defer observe(w.ctx, "WebhookRepository", "GetNextWebhookID")()
+	var webhook Domain.Webhook
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := w.webhooks.FindOne(w.ctx, bson.D{}, findOptions).Decode(&webhook)
+	if err != nil {
+
+		return 1
+	}
+	return webhook.ID + 1
+}
+
+func (w *WebhookRepository) CreateDelivery(delivery Domain.WebhookDelivery) error {
+	defer observe(w.ctx, "WebhookRepository", "CreateDelivery")()
+	if _, err := w.deliveries.InsertOne(w.ctx, delivery); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDeliveries returns the deliveries of a webhook, latest first, optionally
+// only the ones with the given status.
+func (w *WebhookRepository) GetDeliveries(webhookID int, status string) ([]Domain.WebhookDelivery, error) {
+	defer observe(w.ctx, "WebhookRepository", "GetDeliveries")()
+	filter := bson.M{"webhookid": webhookID}
+	if status != "" {
+		filter["status"] = status
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: -1}})
+	cursor, err := w.deliveries.Find(w.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []Domain.WebhookDelivery{}
+	if err := cursor.All(w.ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepository) GetDeliveryByID(id int) (Domain.WebhookDelivery, error) {
+	defer observe(w.ctx, "WebhookRepository", "GetDeliveryByID")()
+	var delivery Domain.WebhookDelivery
+	if err := w.deliveries.FindOne(w.ctx, bson.M{"id": id}).Decode(&delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// ClaimDueDelivery takes the pending delivery that has waited the longest
+// for its next attempt, if it is due, and holds it for the lease. A delivery
+// left unfinished by a worker that stopped is taken again once the lease is
+// over. It returns mongo.ErrNoDocuments when no delivery is due.
+func (w *WebhookRepository) ClaimDueDelivery(now time.Time, lease time.Duration) (Domain.WebhookDelivery, error) {
+	defer observe(w.ctx, "WebhookRepository", "ClaimDueDelivery")()
+	filter := bson.M{"status": Domain.DeliveryPending, "nextattemptat": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"nextattemptat": now.Add(lease)}}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextattemptat", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery Domain.WebhookDelivery
+	err := w.deliveries.FindOneAndUpdate(w.ctx, filter, update, findOptions).Decode(&delivery)
+	return delivery, err
+}
+
+func (w *WebhookRepository) RecordAttempt(id int, attempt Domain.DeliveryAttempt, status string, nextAttemptAt time.Time) error {
+	defer observe(w.ctx, "WebhookRepository", "RecordAttempt")()
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  bson.M{"status": status, "nextattemptat": nextAttemptAt},
+	}
+	result, err := w.deliveries.UpdateOne(w.ctx, bson.M{"id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("delivery not found")
+	}
+	return nil
+}
+
+func (w *WebhookRepository) GetNextDeliveryID() int {
+	defer observe(w.ctx, "WebhookRepository", "GetNextDeliveryID")()
+	var delivery Domain.WebhookDelivery
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := w.deliveries.FindOne(w.ctx, bson.D{}, findOptions).Decode(&delivery)
+	if err != nil {
+
+		return 1
+	}
+	return delivery.ID + 1
+}
+
+func (w *WebhookRepository) findWebhooks(filter bson.M) ([]Domain.Webhook, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := w.webhooks.Find(w.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := []Domain.Webhook{}
+	if err := cursor.All(w.ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
@@ -0,0 +1,253 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var worklog_ctx = GetContext()
+
+type IWorklogRepository interface {
+	WithContext(ctx context.Context) IWorklogRepository
+	CreateWorklog(worklog Domain.Worklog) error
+	StartTimer(worklog Domain.Worklog) (bool, error)
+	GetRunningTimer(taskID int, user string) (Domain.Worklog, error)
+	StopTimer(worklog Domain.Worklog) error
+	GetWorklogs(filter Domain.WorklogFilter) ([]Domain.Worklog, error)
+	GetWorklogByID(id int) (Domain.Worklog, error)
+	DeleteWorklog(id int) error
+	DeleteWorklogsByTasks(taskIDs []int) error
+	TotalWorklogs(filter Domain.WorklogFilter, groupBy []string) ([]Domain.TimeTotal, error)
+	GetNextWorklogID() int
+}
+
+type WorklogRepository struct {
+	collection *mongo.Collection
+	ctx        context.Context
+}
+
+func NewWorklogRepository(dbName string) IWorklogRepository {
+	collection := client.Database(dbName).Collection("worklogs")
+
+	// A user has at most one timer running on a task.
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: "taskid", Value: 1}, {Key: "user", Value: 1}},
+		Options: options.Index().SetName("worklog_one_timer").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"running": true}),
+	}
+	if _, err := collection.Indexes().CreateOne(worklog_ctx, index); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &WorklogRepository{collection: collection, ctx: worklog_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (w *WorklogRepository) WithContext(ctx context.Context) IWorklogRepository {
+	bound := *w
+	bound.ctx = ctx
+	return &bound
+}
+
+func (w *WorklogRepository) CreateWorklog(worklog Domain.Worklog) error {
+	defer observe(w.ctx, "WorklogRepository", "CreateWorklog")()
+	if _, err := w.collection.InsertOne(w.ctx, worklog); err != nil {
+		return err
+	}
+	return nil
+}
+
+// StartTimer records a running timer. It returns false when the user already
+// has a timer running on the task.
+func (w *WorklogRepository) StartTimer(worklog Domain.Worklog) (bool, error) {
+	defer observe(w.ctx, "WorklogRepository", "StartTimer")()
+	if _, err := w.collection.InsertOne(w.ctx, worklog); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *WorklogRepository) GetRunningTimer(taskID int, user string) (Domain.Worklog, error) {
+	defer observe(w.ctx, "WorklogRepository", "GetRunningTimer")()
+	var worklog Domain.Worklog
+	filter := bson.M{"taskid": taskID, "user": user, "running": true}
+	if err := w.collection.FindOne(w.ctx, filter).Decode(&worklog); err != nil {
+		return worklog, err
+	}
+	return worklog, nil
+}
+
+// StopTimer saves a stopped timer, unless it was stopped in the meantime.
+func (w *WorklogRepository) StopTimer(worklog Domain.Worklog) error {
+	defer observe(w.ctx, "WorklogRepository", "StopTimer")()
+	filter := bson.M{"id": worklog.ID, "running": true}
+	update := bson.M{"$set": bson.M{
+		"endedat": worklog.EndedAt,
+		"minutes": worklog.Minutes,
+		"note":    worklog.Note,
+		"running": false,
+	}}
+	result, err := w.collection.UpdateOne(w.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("timer not running")
+	}
+	return nil
+}
+
+// GetWorklogs returns the worklogs matching the filter in the order they
+// started.
+func (w *WorklogRepository) GetWorklogs(filter Domain.WorklogFilter) ([]Domain.Worklog, error) {
+	defer observe(w.ctx, "WorklogRepository", "GetWorklogs")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "startedat", Value: 1}, {Key: "id", Value: 1}})
+	cursor, err := w.collection.Find(w.ctx, worklogFilterQuery(filter), findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	worklogs := []Domain.Worklog{}
+	if err := cursor.All(w.ctx, &worklogs); err != nil {
+		return nil, err
+	}
+	return worklogs, nil
+}
+
+func (w *WorklogRepository) GetWorklogByID(id int) (Domain.Worklog, error) {
+	defer observe(w.ctx, "WorklogRepository", "GetWorklogByID")()
+	var worklog Domain.Worklog
+	if err := w.collection.FindOne(w.ctx, bson.M{"id": id}).Decode(&worklog); err != nil {
+		return worklog, err
+	}
+	return worklog, nil
+}
+
+func (w *WorklogRepository) DeleteWorklog(id int) error {
+	defer observe(w.ctx, "WorklogRepository", "DeleteWorklog")()
+	result, err := w.collection.DeleteOne(w.ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("worklog not found")
+	}
+	return nil
+}
+
+func (w *WorklogRepository) DeleteWorklogsByTasks(taskIDs []int) error {
+	defer observe(w.ctx, "WorklogRepository", "DeleteWorklogsByTasks")()
+	if _, err := w.collection.DeleteMany(w.ctx, bson.M{"taskid": bson.M{"$in": taskIDs}}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TotalWorklogs sums the minutes of the stopped worklogs matching the filter,
+// grouped by any of task, user and UTC day, in a single aggregation.
+func (w *WorklogRepository) TotalWorklogs(filter Domain.WorklogFilter, groupBy []string) ([]Domain.TimeTotal, error) {
+	defer observe(w.ctx, "WorklogRepository", "TotalWorklogs")()
+	match := worklogFilterQuery(filter)
+	match["running"] = false
+
+	key := bson.M{}
+	sort := bson.D{}
+	for _, group := range groupBy {
+		switch group {
+		case Domain.GroupByTask:
+			key["taskid"] = "$taskid"
+		case Domain.GroupByUser:
+			key["user"] = "$user"
+		case Domain.GroupByDay:
+			key["day"] = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$startedat"}}
+		}
+	}
+	for _, field := range []string{"day", "taskid", "user"} {
+		if _, ok := key[field]; ok {
+			sort = append(sort, bson.E{Key: "_id." + field, Value: 1})
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     key,
+			"minutes": bson.M{"$sum": "$minutes"},
+			"entries": bson.M{"$sum": 1},
+		}}},
+	}
+	if len(sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+
+	cursor, err := w.collection.Aggregate(w.ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ID struct {
+			TaskID int    `bson:"taskid"`
+			User   string `bson:"user"`
+			Day    string `bson:"day"`
+		} `bson:"_id"`
+		Minutes int `bson:"minutes"`
+		Entries int `bson:"entries"`
+	}
+	if err := cursor.All(w.ctx, &results); err != nil {
+		return nil, err
+	}
+
+	totals := []Domain.TimeTotal{}
+	for _, result := range results {
+		totals = append(totals, Domain.TimeTotal{
+			TaskID:  result.ID.TaskID,
+			User:    result.ID.User,
+			Day:     result.ID.Day,
+			Minutes: result.Minutes,
+			Entries: result.Entries,
+		})
+	}
+	return totals, nil
+}
+
+func (w *WorklogRepository) GetNextWorklogID() int {
+	defer observe(w.ctx, "WorklogRepository", "GetNextWorklogID")()
+	var worklog Domain.Worklog
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := w.collection.FindOne(w.ctx, bson.D{}, findOptions).Decode(&worklog)
+	if err != nil {
+
+		return 1
+	}
+	return worklog.ID + 1
+}
+
+func worklogFilterQuery(filter Domain.WorklogFilter) bson.M {
+	query := bson.M{}
+	if filter.TaskIDs != nil {
+		query["taskid"] = bson.M{"$in": filter.TaskIDs}
+	}
+	if filter.User != "" {
+		query["user"] = filter.User
+	}
+	started := bson.M{}
+	if !filter.From.IsZero() {
+		started["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		started["$lt"] = filter.To
+	}
+	if len(started) > 0 {
+		query["startedat"] = started
+	}
+	return query
+}
@@ -0,0 +1,192 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var workspace_ctx = GetContext()
+
+// IWorkspaceRepository stores the workspaces and their members. They are
+// kept in the instance database, next to the users; the data of each
+// workspace is in the workspace's own database, see WorkspaceDatabase.
+type IWorkspaceRepository interface {
+	WithContext(ctx context.Context) IWorkspaceRepository
+	GetWorkspaces() ([]Domain.Workspace, error)
+	GetWorkspaceByID(id int) (Domain.Workspace, error)
+	CreateWorkspace(workspace Domain.Workspace) error
+	GetNextWorkspaceID() int
+	GetMember(workspaceID int, username string) (Domain.Membership, error)
+	GetMembers(workspaceID int) ([]Domain.Membership, error)
+	GetMemberships(username string) ([]Domain.Membership, error)
+	SetMember(member Domain.Membership) error
+	RemoveMember(workspaceID int, username string) error
+}
+
+type WorkspaceRepository struct {
+	workspaces *mongo.Collection
+	members    *mongo.Collection
+	ctx        context.Context
+}
+
+func NewWorkspaceRepository(dbName string) IWorkspaceRepository {
+	workspaces := client.Database(dbName).Collection("workspaces")
+	members := client.Database(dbName).Collection("workspace_members")
+
+	idIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetName("workspace_id").SetUnique(true),
+	}
+	if _, err := workspaces.Indexes().CreateOne(workspace_ctx, idIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	memberIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "workspaceid", Value: 1}, {Key: "username", Value: 1}},
+		Options: options.Index().SetName("workspace_member").SetUnique(true),
+	}
+	if _, err := members.Indexes().CreateOne(workspace_ctx, memberIndex); err != nil {
+		slog.Error("index creation failed", "error", err)
+	}
+
+	return &WorkspaceRepository{workspaces: workspaces, members: members, ctx: workspace_ctx}
+}
+
+// WithContext returns the repository working within ctx, which carries the
+// request's ID and trace into its logs and spans.
+func (w *WorkspaceRepository) WithContext(ctx context.Context) IWorkspaceRepository {
+	bound := *w
+	bound.ctx = ctx
+	return &bound
+}
+
+// WorkspaceDatabase names the database holding the data of a workspace. The
+// default workspace keeps the instance database, which is where the data
+// from before workspaces is.
+func WorkspaceDatabase(dbName string, workspaceID int) string {
+	if workspaceID == Domain.DefaultWorkspaceID {
+		return dbName
+	}
+	return fmt.Sprintf("%s_ws%d", dbName, workspaceID)
+}
+
+func (w *WorkspaceRepository) GetWorkspaces() ([]Domain.Workspace, error) {
+	defer observe(w.ctx, "WorkspaceRepository", "GetWorkspaces")()
+	findOptions := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := w.workspaces.Find(w.ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := []Domain.Workspace{}
+	if err := cursor.All(w.ctx, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (w *WorkspaceRepository) GetWorkspaceByID(id int) (Domain.Workspace, error) {
+	defer observe(w.ctx, "WorkspaceRepository", "GetWorkspaceByID")()
+	var workspace Domain.Workspace
+	if err := w.workspaces.FindOne(w.ctx, bson.M{"id": id}).Decode(&workspace); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return workspace, errors.New("workspace not found")
+		}
+		return workspace, err
+	}
+	return workspace, nil
+}
+
+func (w *WorkspaceRepository) CreateWorkspace(workspace Domain.Workspace) error {
+	defer observe(w.ctx, "WorkspaceRepository", "CreateWorkspace")()
+	if _, err := w.workspaces.InsertOne(w.ctx, workspace); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *WorkspaceRepository) GetNextWorkspaceID() int {
+	defer observe(w.ctx, "WorkspaceRepository", "GetNextWorkspaceID")()
+	var workspace Domain.Workspace
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}})
+	err := w.workspaces.FindOne(w.ctx, bson.D{}, findOptions).Decode(&workspace)
+	if err != nil {
+
+		return 1
+	}
+	return workspace.ID + 1
+}
+
+func (w *WorkspaceRepository) GetMember(workspaceID int, username string) (Domain.Membership, error) {
+	defer observe(w.ctx, "WorkspaceRepository", "GetMember")()
+	var member Domain.Membership
+	filter := bson.M{"workspaceid": workspaceID, "username": username}
+	if err := w.members.FindOne(w.ctx, filter).Decode(&member); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return member, errors.New("member not found")
+		}
+		return member, err
+	}
+	return member, nil
+}
+
+func (w *WorkspaceRepository) GetMembers(workspaceID int) ([]Domain.Membership, error) {
+	defer observe(w.ctx, "WorkspaceRepository", "GetMembers")()
+	return w.findMembers(bson.M{"workspaceid": workspaceID}, "username")
+}
+
+// GetMemberships returns the workspaces a user is a member of, oldest
+// workspace first.
+func (w *WorkspaceRepository) GetMemberships(username string) ([]Domain.Membership, error) {
+	defer observe(w.ctx, "WorkspaceRepository", "GetMemberships")()
+	return w.findMembers(bson.M{"username": username}, "workspaceid")
+}
+
+// SetMember adds a member to a workspace, or changes the role of an existing
+// one.
+func (w *WorkspaceRepository) SetMember(member Domain.Membership) error {
+	defer observe(w.ctx, "WorkspaceRepository", "SetMember")()
+	filter := bson.M{"workspaceid": member.WorkspaceID, "username": member.Username}
+	update := bson.M{
+		"$set":         bson.M{"role": member.Role},
+		"$setOnInsert": bson.M{"joinedat": member.JoinedAt},
+	}
+	if _, err := w.members.UpdateOne(w.ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *WorkspaceRepository) RemoveMember(workspaceID int, username string) error {
+	defer observe(w.ctx, "WorkspaceRepository", "RemoveMember")()
+	filter := bson.M{"workspaceid": workspaceID, "username": username}
+	result, err := w.members.DeleteOne(w.ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+func (w *WorkspaceRepository) findMembers(filter bson.M, sortBy string) ([]Domain.Membership, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: sortBy, Value: 1}})
+	cursor, err := w.members.Find(w.ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []Domain.Membership{}
+	if err := cursor.All(w.ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
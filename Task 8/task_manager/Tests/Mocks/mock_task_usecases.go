@@ -1,7 +1,9 @@
 package Mocks
 
 import (
+	"io"
 	"task_manager/Domain"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -22,21 +24,116 @@ func (m *MockTaskUsecases) GetTaskByID(id int) (Domain.Task, error) {
 	return args.Get(0).(Domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUsecases) CreateTask(task Domain.Task) (Domain.Task, error) {
-	args := m.Called(task)
+func (m *MockTaskUsecases) CreateTask(task Domain.Task, actor string) (Domain.Task, error) {
+	args := m.Called(task, actor)
 	return args.Get(0).(Domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUsecases) UpdateTask(id int, updatedTask Domain.Task) error {
-	args := m.Called(id, updatedTask)
+func (m *MockTaskUsecases) UpdateTask(id int, updatedTask Domain.Task, actor string) error {
+	args := m.Called(id, updatedTask, actor)
 	return args.Error(0)
 }
 
-func (m *MockTaskUsecases) DeleteTask(id int) error {
+func (m *MockTaskUsecases) DeleteTask(id int, actor string) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecases) GetHistory(id int) ([]Domain.TaskRevision, error) {
 	args := m.Called(id)
+	return args.Get(0).([]Domain.TaskRevision), args.Error(1)
+}
+
+func (m *MockTaskUsecases) RevertTask(id, revision int, actor string) (Domain.Task, error) {
+	args := m.Called(id, revision, actor)
+	return args.Get(0).(Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) GetTrash() []Domain.Task {
+	args := m.Called()
+	return args.Get(0).([]Domain.Task)
+}
+
+func (m *MockTaskUsecases) RestoreTask(id int, actor string) (Domain.Task, error) {
+	args := m.Called(id, actor)
+	return args.Get(0).(Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) PurgeTrash() error {
+	args := m.Called()
 	return args.Error(0)
 }
 
+func (m *MockTaskUsecases) GetSubtasks(id int) ([]Domain.Task, Domain.Progress, error) {
+	args := m.Called(id)
+	return args.Get(0).([]Domain.Task), args.Get(1).(Domain.Progress), args.Error(2)
+}
+
+func (m *MockTaskUsecases) CreateSubtask(parentID int, task Domain.Task, actor string) (Domain.Task, error) {
+	args := m.Called(parentID, task, actor)
+	return args.Get(0).(Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) GetDependencies(id int) ([]Domain.Task, []Domain.Task, error) {
+	args := m.Called(id)
+	return args.Get(0).([]Domain.Task), args.Get(1).([]Domain.Task), args.Error(2)
+}
+
+func (m *MockTaskUsecases) AddDependency(id, blockerID int, actor string) error {
+	args := m.Called(id, blockerID, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecases) RemoveDependency(id, blockerID int, actor string) error {
+	args := m.Called(id, blockerID, actor)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecases) GetTasksByProject(projectID int) []Domain.Task {
+	args := m.Called(projectID)
+	return args.Get(0).([]Domain.Task)
+}
+
+func (m *MockTaskUsecases) FindTasks(filter Domain.TaskFilter) []Domain.Task {
+	args := m.Called(filter)
+	return args.Get(0).([]Domain.Task)
+}
+
+func (m *MockTaskUsecases) FacetTasks(filter Domain.TaskFilter) (Domain.TaskFacets, error) {
+	args := m.Called(filter)
+	return args.Get(0).(Domain.TaskFacets), args.Error(1)
+}
+
+func (m *MockTaskUsecases) GetOccurrences(id int) ([]Domain.Task, error) {
+	args := m.Called(id)
+	return args.Get(0).([]Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) UpdateFutureOccurrences(id int, updatedTask Domain.Task, actor string) ([]Domain.Task, error) {
+	args := m.Called(id, updatedTask, actor)
+	return args.Get(0).([]Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) GenerateOccurrences(now time.Time) ([]Domain.Task, error) {
+	args := m.Called(now)
+	return args.Get(0).([]Domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecases) BulkTasks(request Domain.BulkRequest, actor string) ([]Domain.BulkResult, error) {
+	args := m.Called(request, actor)
+	return args.Get(0).([]Domain.BulkResult), args.Error(1)
+}
+
+func (m *MockTaskUsecases) ExportTasks(filter Domain.TaskFilter, format string, w io.Writer) error {
+	args := m.Called(filter, format, w)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecases) ImportTasks(r io.Reader, options Domain.ImportOptions, actor string) (Domain.ImportReport, error) {
+	args := m.Called(r, options, actor)
+	return args.Get(0).(Domain.ImportReport), args.Error(1)
+}
+
 func (m *MockTaskUsecases) GetNextTaskID() int {
 	args := m.Called()
 	return args.Int(0)
@@ -32,7 +32,17 @@ func (m *MockUserUsecases) GetUserbyUsername(username string) (Domain.User, erro
 	return args.Get(0).(Domain.User), args.Error(1)
 }
 
+func (m *MockUserUsecases) GetUserByID(id int) (Domain.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(Domain.User), args.Error(1)
+}
+
 func (m *MockUserUsecases) GetNextUserID() int {
 	args := m.Called()
 	return args.Int(0)
 }
+
+func (m *MockUserUsecases) GetUsersByUsernames(usernames []string) ([]Domain.User, error) {
+	args := m.Called(usernames)
+	return args.Get(0).([]Domain.User), args.Error(1)
+}
@@ -0,0 +1,40 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the diff of an updated task only reports the changed fields
+func TestDiff_UpdatedTask(t *testing.T) {
+	before := Domain.Task{ID: 1, Title: "Old", Description: "Same", Status: "pending"}
+	after := Domain.Task{ID: 1, Title: "New", Description: "Same", Status: "completed"}
+
+	changes := Usecases.Diff(before, after)
+
+	assert.Len(t, changes, 2)
+	assert.Equal(t, Domain.FieldChange{From: "Old", To: "New"}, changes["title"])
+	assert.Equal(t, Domain.FieldChange{From: "pending", To: "completed"}, changes["status"])
+}
+
+// Test the diff of a deletion reports every field as removed
+func TestDiff_Deletion(t *testing.T) {
+	changes := Usecases.Diff(Domain.Task{ID: 3, Title: "Gone"}, nil)
+
+	assert.Equal(t, Domain.FieldChange{From: "Gone", To: nil}, changes["title"])
+	assert.Equal(t, Domain.FieldChange{From: float64(3), To: nil}, changes["id"])
+}
+
+// Test passwords never end up in the audit log
+func TestDiff_SkipsPassword(t *testing.T) {
+	before := Domain.User{ID: 1, Username: "test", Password: "hash1", Role: "user"}
+	after := Domain.User{ID: 1, Username: "test", Password: "hash2", Role: "admin"}
+
+	changes := Usecases.Diff(before, after)
+
+	assert.NotContains(t, changes, "password")
+	assert.Equal(t, Domain.FieldChange{From: "user", To: "admin"}, changes["role"])
+}
@@ -0,0 +1,197 @@
+package Tests
+
+import (
+	"errors"
+	"fmt"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test an empty filter matches every task
+func TestTaskFilterIsEmpty(t *testing.T) {
+	assert.True(t, Domain.TaskFilter{}.IsEmpty())
+	assert.True(t, Domain.TaskFilter{Labels: []string{}}.IsEmpty())
+	assert.False(t, Domain.TaskFilter{Labels: []string{"release-1.2"}}.IsEmpty())
+	assert.False(t, Domain.TaskFilter{Overdue: true}.IsEmpty())
+}
+
+// Test a bulk request needs operations
+func TestBulkTasks_NoOperations(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+
+	_, err := service.BulkTasks(Domain.BulkRequest{}, "admin")
+
+	assert.True(t, errors.Is(err, Usecases.ErrInvalidBulk))
+}
+
+// Test invalid operations fail on their own and stop an atomic request
+func TestBulkTasks_InvalidOperations(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	request := Domain.BulkRequest{
+		Atomic: true,
+		Operations: []Domain.BulkOperation{
+			{Op: "archive", ID: 1},
+			{Op: Domain.BulkDelete},
+			{Op: Domain.BulkUpdate, Filter: &Domain.TaskFilter{}, Set: map[string]interface{}{"status": "completed"}},
+			{Op: Domain.BulkCreate},
+		},
+	}
+
+	results, err := service.BulkTasks(request, "admin")
+
+	assert.True(t, errors.Is(err, Usecases.ErrBulkFailed))
+	assert.Len(t, results, 4)
+	for index, result := range results {
+		assert.Equal(t, index, result.Index)
+		assert.Equal(t, Domain.BulkFailed, result.Status)
+		assert.NotEmpty(t, result.Error)
+	}
+}
+
+// bulkTasks creates tasks to run bulk requests on, with a label of their
+// own.
+func bulkTasks(t *testing.T, service Usecases.ITaskService, titles ...string) ([]Domain.Task, string) {
+	label := fmt.Sprintf("bulk-%d", time.Now().UnixNano())
+	tasks := []Domain.Task{}
+	for _, title := range titles {
+		task, err := service.CreateTask(Domain.Task{Title: title, Status: "pending", Labels: []string{label}}, "admin")
+		require.NoError(t, err)
+		tasks = append(tasks, task)
+	}
+	return tasks, label
+}
+
+// Test tasks are created, updated and deleted in one request
+func TestBulkTasks_CreateUpdateDelete(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	tasks, _ := bulkTasks(t, service, "To update", "To delete")
+	request := Domain.BulkRequest{Operations: []Domain.BulkOperation{
+		{Op: Domain.BulkCreate, Task: &Domain.Task{Title: "Created in bulk", Status: "pending"}},
+		{Op: Domain.BulkUpdate, ID: tasks[0].ID, Set: map[string]interface{}{"status": "completed", "description": "done in bulk"}},
+		{Op: Domain.BulkDelete, ID: tasks[1].ID},
+	}}
+
+	results, err := service.BulkTasks(request, "admin")
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for index, result := range results {
+		assert.Equal(t, index, result.Index)
+		assert.Equal(t, Domain.BulkSucceeded, result.Status, result.Error)
+	}
+	created, err := service.GetTaskByID(results[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Created in bulk", created.Title)
+	updated, _ := service.GetTaskByID(tasks[0].ID)
+	assert.Equal(t, "completed", updated.Status)
+	assert.Equal(t, "done in bulk", updated.Description)
+	_, err = service.GetTaskByID(tasks[1].ID)
+	assert.Error(t, err, "deleted tasks go to the trash")
+}
+
+// Test an update by filter changes every task it matches
+func TestBulkTasks_FilterUpdate(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	tasks, label := bulkTasks(t, service, "First", "Second")
+	other, _ := bulkTasks(t, service, "Unlabelled")
+	request := Domain.BulkRequest{Operations: []Domain.BulkOperation{
+		{Op: Domain.BulkUpdate, Filter: &Domain.TaskFilter{Labels: []string{label}}, Set: map[string]interface{}{"description": "matched"}},
+	}}
+
+	results, err := service.BulkTasks(request, "admin")
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	ids := []int{}
+	for _, result := range results {
+		assert.Equal(t, 0, result.Index)
+		assert.Equal(t, Domain.BulkSucceeded, result.Status, result.Error)
+		ids = append(ids, result.ID)
+	}
+	assert.ElementsMatch(t, []int{tasks[0].ID, tasks[1].ID}, ids)
+	for _, task := range tasks {
+		updated, _ := service.GetTaskByID(task.ID)
+		assert.Equal(t, "matched", updated.Description)
+	}
+	unchanged, _ := service.GetTaskByID(other[0].ID)
+	assert.Empty(t, unchanged.Description)
+}
+
+// Test the operations that fail, when checked or when written, do not stop
+// the others
+func TestBulkTasks_PartialFailure(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	tasks, _ := bulkTasks(t, service, "To update")
+	externalID := fmt.Sprintf("BULK-%d", time.Now().UnixNano())
+	request := Domain.BulkRequest{Operations: []Domain.BulkOperation{
+		{Op: Domain.BulkUpdate, ID: tasks[0].ID, Set: map[string]interface{}{"status": "completed"}},
+		{Op: Domain.BulkUpdate, ID: 987654321, Set: map[string]interface{}{"status": "completed"}},
+		{Op: Domain.BulkCreate, Task: &Domain.Task{Title: "First", ExternalID: externalID}},
+		{Op: Domain.BulkCreate, Task: &Domain.Task{Title: "Same external ID", ExternalID: externalID}},
+	}}
+
+	results, err := service.BulkTasks(request, "admin")
+
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	statuses := map[int]string{}
+	for _, result := range results {
+		statuses[result.Index] = result.Status
+	}
+	assert.Equal(t, map[int]string{0: Domain.BulkSucceeded, 1: Domain.BulkFailed, 2: Domain.BulkSucceeded, 3: Domain.BulkFailed}, statuses)
+	updated, _ := service.GetTaskByID(tasks[0].ID)
+	assert.Equal(t, "completed", updated.Status)
+}
+
+// Test updates and deletes whose task is gone by the time they are written
+// are reported as failed
+func TestTaskRepository_BulkWriteNotFound(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	tasks, _ := bulkTasks(t, service, "To delete")
+	repo := Repositories.NewTaskRepository("test_task_manager")
+	now := time.Now().UTC()
+
+	errs, err := repo.BulkWrite([]Repositories.TaskWrite{
+		{Op: Domain.BulkUpdate, Task: Domain.Task{ID: 987654321, Title: "Gone"}},
+		{Op: Domain.BulkDelete, Task: Domain.Task{ID: tasks[0].ID, DeletedAt: &now}},
+		{Op: Domain.BulkDelete, Task: Domain.Task{ID: 987654322, DeletedAt: &now}},
+	}, false)
+
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "task not found")
+	assert.NoError(t, errs[1])
+	assert.EqualError(t, errs[2], "task not found")
+}
+
+// Test an atomic request that fails when written leaves every task as it
+// was, whether MongoDB rolls the transaction back or cannot run one
+func TestBulkTasks_AtomicRollback(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	tasks, _ := bulkTasks(t, service, "To update")
+	externalID := fmt.Sprintf("BULK-%d", time.Now().UnixNano())
+	request := Domain.BulkRequest{Atomic: true, Operations: []Domain.BulkOperation{
+		{Op: Domain.BulkUpdate, ID: tasks[0].ID, Set: map[string]interface{}{"status": "completed"}},
+		{Op: Domain.BulkCreate, Task: &Domain.Task{Title: "First", ExternalID: externalID}},
+		{Op: Domain.BulkCreate, Task: &Domain.Task{Title: "Same external ID", ExternalID: externalID}},
+	}}
+
+	results, err := service.BulkTasks(request, "admin")
+
+	assert.True(t, errors.Is(err, Usecases.ErrBulkFailed) || errors.Is(err, Usecases.ErrAtomicUnsupported), err)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Equal(t, Domain.BulkSkipped, result.Status)
+		assert.Nil(t, result.Task)
+	}
+	unchanged, _ := service.GetTaskByID(tasks[0].ID)
+	assert.Equal(t, "pending", unchanged.Status)
+	_, err = Repositories.NewTaskRepository("test_task_manager").GetTaskByExternalID(externalID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package Tests
+
+import (
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test mentions are extracted in order and without duplicates
+func TestParseMentions(t *testing.T) {
+	mentions := Usecases.ParseMentions("@alice can you check this with @bob_2? cc @alice.")
+
+	assert.Equal(t, []string{"alice", "bob_2"}, mentions)
+}
+
+// Test email addresses and bare @ signs are not mentions
+func TestParseMentions_IgnoresEmails(t *testing.T) {
+	mentions := Usecases.ParseMentions("mail me at me@example.com @ noon")
+
+	assert.Empty(t, mentions)
+}
@@ -0,0 +1,119 @@
+package Tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"task_manager/Infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Test the default configuration is valid
+func TestDefaultConfig(t *testing.T) {
+	config := Infrastructure.DefaultConfig()
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "task_manager", config.Mongo.Database)
+	assert.Equal(t, "localhost:8080", config.Server.Addr)
+}
+
+// Test flags override environment variables, which override the file
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+mongo:
+  database: from_file
+server:
+  addr: file:8080
+auth:
+  bcrypt_cost: 12
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("LISTEN_ADDR", "env:8080")
+	t.Setenv("BCRYPT_COST", "11")
+
+	config, printConfig, err := Infrastructure.LoadConfig([]string{"--auth-bcrypt-cost", "13"})
+	assert.NoError(t, err)
+	assert.False(t, printConfig)
+	assert.Equal(t, "from_file", config.Mongo.Database)
+	assert.Equal(t, "env:8080", config.Server.Addr)
+	assert.Equal(t, 13, config.Auth.BcryptCost)
+}
+
+// Test TOML files are read, durations and dates included
+func TestLoadConfigTOML(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+[tasks]
+trash_retention = "72h"
+
+[reminders]
+lead_times = ["24h", "1h"]
+
+[api]
+legacy_sunset = "2027-06-30"
+`)
+
+	config, printConfig, err := Infrastructure.LoadConfig([]string{"--config", path, "--print-config"})
+	assert.NoError(t, err)
+	assert.True(t, printConfig)
+	assert.Equal(t, 72*time.Hour, config.Tasks.TrashRetention.Duration)
+	assert.Equal(t, []Infrastructure.Duration{{Duration: 24 * time.Hour}, {Duration: time.Hour}}, config.Reminders.LeadTimes)
+	assert.Equal(t, time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC), config.API.LegacySunset.Time)
+}
+
+// Test unknown keys and malformed values are reported with where they come from
+func TestLoadConfigErrors(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "server:\n  adr: localhost:9090\n")
+	_, _, err := Infrastructure.LoadConfig([]string{"--config", path})
+	assert.ErrorContains(t, err, "adr")
+
+	t.Setenv("REMINDER_INTERVAL", "soon")
+	_, _, err = Infrastructure.LoadConfig(nil)
+	assert.ErrorContains(t, err, "REMINDER_INTERVAL")
+}
+
+// Test validation reports every problem at once
+func TestConfigValidate(t *testing.T) {
+	config := Infrastructure.DefaultConfig()
+	config.Server.Addr = "8080"
+	config.Server.AllowedOrigins = []string{"app.example.com"}
+	config.Auth.BcryptCost = 99
+	config.Server.ShutdownTimeout.Duration = 0
+	config.Notifier.Type = "smtp"
+	config.Log.Level = "verbose"
+	config.Tracing.Endpoint = "localhost:4318"
+	config.Tracing.SampleRatio = 2
+	config.RateLimit.Policies[0].Key = "session"
+	config.API.LegacySunset.Time = config.API.LegacyDeprecated.AddDate(0, 0, -1)
+
+	err := config.Validate()
+	assert.Error(t, err)
+	for _, key := range []string{"server.addr", "server.allowed_origins", "server.shutdown_timeout", "auth.bcrypt_cost", "notifier.smtp.addr", "log.level", "tracing.endpoint", "tracing.sample_ratio", "rate_limit.policies.auth", "api.legacy_sunset"} {
+		assert.Contains(t, err.Error(), key)
+	}
+}
+
+// Test printed configurations hide secrets
+func TestConfigRedacted(t *testing.T) {
+	config := Infrastructure.DefaultConfig()
+	config.Mongo.URI = "mongodb://app:hunter2@db:27017/"
+	config.Auth.JWTSecret = "a very secret signing key"
+	config.Attachments.S3.SecretKey = "s3cr3t"
+
+	var out strings.Builder
+	assert.NoError(t, Infrastructure.WriteConfig(&out, config.Redacted()))
+
+	printed := out.String()
+	assert.NotContains(t, printed, "hunter2")
+	assert.NotContains(t, printed, "a very secret signing key")
+	assert.NotContains(t, printed, "s3cr3t")
+	assert.Contains(t, printed, "app:REDACTED@db:27017")
+	assert.Equal(t, "a very secret signing key", config.Auth.JWTSecret)
+}
@@ -33,7 +33,7 @@ func (suite *ControllerTestSuite) SetupTest() {
 	suite.userService = Usecases.NewUserService("test_task_manager") // Create a new user service with mock database "test_task_manager"
 	suite.taskRepo = new(Mocks.MockTaskRepository)
 	suite.taskService = Usecases.NewTaskService("test_task_manager")
-	suite.controller = controllers.NewController() // Create a new controller
+	suite.controller = controllers.NewController("test_task_manager") // Create a new controller
 }
 
 // Tear down the test suite
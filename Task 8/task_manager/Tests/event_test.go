@@ -0,0 +1,129 @@
+package Tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_manager/Delivery/controllers"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+// Test events are numbered and passed to subscribers and handlers
+func TestEventBusPublish(t *testing.T) {
+	bus := Usecases.NewEventBus(10)
+	handled := []int{}
+	bus.Handle(func(event Domain.Event) { handled = append(handled, event.ID) })
+	_, events, cancel := bus.Subscribe(0)
+	defer cancel()
+
+	first := bus.Publish(Domain.Event{Type: Domain.ActionTaskCreated})
+	second := bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated})
+
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, 2, second.ID)
+	assert.False(t, first.Timestamp.IsZero())
+	assert.Equal(t, []int{1, 2}, handled)
+	assert.Equal(t, 1, (<-events).ID)
+	assert.Equal(t, 2, (<-events).ID)
+}
+
+// Test subscribers resume after their last event within the kept history
+func TestEventBusResume(t *testing.T) {
+	bus := Usecases.NewEventBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated})
+	}
+
+	missed, _, cancel := bus.Subscribe(3)
+	cancel()
+	assert.Len(t, missed, 2)
+	assert.Equal(t, 4, missed[0].ID)
+
+	missed, _, cancel = bus.Subscribe(1)
+	cancel()
+	assert.Len(t, missed, 3)
+	assert.Equal(t, 3, missed[0].ID)
+
+	missed, _, cancel = bus.Subscribe(42)
+	cancel()
+	assert.Empty(t, missed)
+}
+
+// Test a subscriber that falls behind is dropped
+func TestEventBusSlowSubscriber(t *testing.T) {
+	bus := Usecases.NewEventBus(10)
+	_, events, cancel := bus.Subscribe(0)
+	defer cancel()
+
+	for i := 0; i < 100; i++ {
+		bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Less(t, received, 100)
+}
+
+// Test closing the bus ends the streams, the current and the later ones
+func TestEventBusClose(t *testing.T) {
+	bus := Usecases.NewEventBus(10)
+	_, events, cancel := bus.Subscribe(0)
+	defer cancel()
+
+	bus.Close()
+	_, ok := <-events
+	assert.False(t, ok)
+
+	bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated})
+	missed, later, cancelLater := bus.Subscribe(0)
+	defer cancelLater()
+	assert.Empty(t, missed)
+	_, ok = <-later
+	assert.False(t, ok)
+}
+
+// Test the token can be passed as a query parameter
+func TestQueryToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events", Infrastructure.QueryToken, func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader("Authorization"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?access_token=abc", nil))
+	assert.Equal(t, "Bearer abc", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events?access_token=abc", nil)
+	req.Header.Set("Authorization", "Bearer xyz")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "Bearer xyz", w.Body.String())
+}
+
+// Test browsers may only open the event WebSocket from allowed origins
+func TestEventWebSocketOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events/ws", controllers.NewController("test_task_manager").StreamEventsWS)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/events/ws"
+
+	_, err := websocket.Dial(url, "", "https://evil.example.com")
+	assert.Error(t, err)
+
+	ws, err := websocket.Dial(url, "", server.URL)
+	if assert.NoError(t, err) {
+		ws.Close()
+	}
+}
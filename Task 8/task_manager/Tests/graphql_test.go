@@ -0,0 +1,373 @@
+package Tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"task_manager/Delivery/controllers"
+	"task_manager/Delivery/graphql"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// graphqlTasks serves tasks from memory. The methods the resolvers do not
+// call are left to the nil service it embeds.
+type graphqlTasks struct {
+	Usecases.ITaskService
+	tasks   []Domain.Task
+	created []Domain.Task
+}
+
+func (s *graphqlTasks) In(int) Usecases.ITaskService                      { return s }
+func (s *graphqlTasks) WithContext(context.Context) Usecases.ITaskService { return s }
+func (s *graphqlTasks) FindTasks(Domain.TaskFilter) []Domain.Task         { return s.tasks }
+
+func (s *graphqlTasks) GetTaskByID(id int) (Domain.Task, error) {
+	for _, task := range s.tasks {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+	return Domain.Task{}, Usecases.ErrInvalidTask
+}
+
+func (s *graphqlTasks) CreateTask(task Domain.Task, actor string) (Domain.Task, error) {
+	task.ID = 100 + len(s.created)
+	s.created = append(s.created, task)
+	return task, nil
+}
+
+// graphqlUsers serves users from memory and counts the batches they are
+// loaded in.
+type graphqlUsers struct {
+	Usecases.IUserService
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (s *graphqlUsers) In(int) Usecases.IUserService                      { return s }
+func (s *graphqlUsers) WithContext(context.Context) Usecases.IUserService { return s }
+
+func (s *graphqlUsers) GetUsersByUsernames(usernames []string) ([]Domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, usernames)
+	users := []Domain.User{}
+	for _, username := range usernames {
+		if username != "stranger" {
+			users = append(users, Domain.User{Username: username, Role: "member"})
+		}
+	}
+	return users, nil
+}
+
+// graphqlComments serves two comments per task and counts the batches they
+// are loaded in.
+type graphqlComments struct {
+	Usecases.ICommentService
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (s *graphqlComments) In(int) Usecases.ICommentService                      { return s }
+func (s *graphqlComments) WithContext(context.Context) Usecases.ICommentService { return s }
+
+func (s *graphqlComments) GetLatestComments(taskIDs []int, limit int) (map[int][]Domain.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, taskIDs)
+	latest := map[int][]Domain.Comment{}
+	for _, id := range taskIDs {
+		latest[id] = []Domain.Comment{
+			{ID: id * 10, TaskID: id, Author: "carol", Body: "first"},
+			{ID: id*10 + 1, TaskID: id, Author: "dave", Body: "second"},
+		}[2-limit:]
+	}
+	return latest, nil
+}
+
+// graphqlEvents streams the events of a bus of its own.
+type graphqlEvents struct {
+	Usecases.IEventService
+	bus *Usecases.EventBus
+}
+
+func (s *graphqlEvents) In(int) Usecases.IEventService                      { return s }
+func (s *graphqlEvents) WithContext(context.Context) Usecases.IEventService { return s }
+
+func (s *graphqlEvents) Subscribe(lastID int) ([]Domain.Event, <-chan Domain.Event, func()) {
+	return s.bus.Subscribe(lastID)
+}
+
+func (s *graphqlEvents) CanSee(event Domain.Event, username string, isAdmin bool) bool {
+	return event.IsTaskEvent()
+}
+
+type graphqlFixture struct {
+	schema   *graphql.Schema
+	tasks    *graphqlTasks
+	users    *graphqlUsers
+	comments *graphqlComments
+	events   *graphqlEvents
+	audited  []string
+}
+
+func newGraphQLFixture() *graphqlFixture {
+	f := &graphqlFixture{
+		tasks: &graphqlTasks{tasks: []Domain.Task{
+			{ID: 1, Title: "Fix login", Assignee: "alice"},
+			{ID: 2, Title: "Write docs", Assignee: "bob"},
+			{ID: 3, Title: "Release", Assignee: "alice"},
+			{ID: 4, Title: "Triage", Assignee: "stranger"},
+		}},
+		users:    &graphqlUsers{},
+		comments: &graphqlComments{},
+		events:   &graphqlEvents{bus: Usecases.NewEventBus(10)},
+	}
+	f.schema = graphql.NewSchema(graphql.Services{
+		Tasks:    f.tasks,
+		Users:    f.users,
+		Comments: f.comments,
+		Events:   f.events,
+		Audit: func(ctx context.Context, action, targetType string, targetID int, before, after interface{}) {
+			f.audited = append(f.audited, action)
+		},
+	})
+	return f
+}
+
+func (f *graphqlFixture) exec(viewer graphql.Viewer, query string, variables map[string]interface{}) (map[string]interface{}, []string) {
+	response := f.schema.Exec(graphql.WithViewer(context.Background(), viewer), graphql.Request{Query: query, Variables: variables})
+	var data map[string]interface{}
+	json.Unmarshal(response.Data, &data)
+	errors := []string{}
+	for _, err := range response.Errors {
+		errors = append(errors, err.Message)
+	}
+	return data, errors
+}
+
+var graphqlMember = graphql.Viewer{Username: "alice", WorkspaceID: 1}
+
+// Test nested fields are loaded in batches rather than once per task
+func TestGraphQLBatching(t *testing.T) {
+	f := newGraphQLFixture()
+	data, errors := f.exec(graphqlMember, `{
+		tasks {
+			title
+			assignee { username }
+			comments(last: 1) { body author { username } }
+		}
+	}`, nil)
+	require.Empty(t, errors)
+
+	tasks := data["tasks"].([]interface{})
+	require.Len(t, tasks, 4)
+	first := tasks[0].(map[string]interface{})
+	assert.Equal(t, "Fix login", first["title"])
+	assert.Equal(t, map[string]interface{}{"username": "alice"}, first["assignee"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"body": "second", "author": map[string]interface{}{"username": "dave"}}}, first["comments"])
+	assert.Nil(t, tasks[3].(map[string]interface{})["assignee"])
+
+	assert.Equal(t, [][]int{{1, 2, 3, 4}}, f.comments.batches)
+	assert.LessOrEqual(t, len(f.users.batches), 2)
+	loaded := []string{}
+	for _, batch := range f.users.batches {
+		loaded = append(loaded, batch...)
+	}
+	assert.ElementsMatch(t, []string{"alice", "bob", "stranger", "dave"}, loaded)
+}
+
+// Test operations too deep or too complex are refused before they run
+func TestGraphQLLimits(t *testing.T) {
+	f := newGraphQLFixture()
+
+	_, errors := f.exec(graphqlMember, `query($n: Int!) { tasks(first: $n) { comments(last: 100) { author { username email role } } } }`,
+		map[string]interface{}{"n": 1000})
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0], "complexity")
+	assert.Empty(t, f.comments.batches)
+
+	_, errors = f.exec(graphqlMember, `query { ...many } fragment many on Query { tasks(first: 500) { comments { id } } }`, nil)
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0], "complexity")
+
+	_, errors = f.exec(graphqlMember, `query($n: Int = 1000) { tasks(first: $n) { comments(last: 100) { id } } }`, nil)
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0], "complexity")
+
+	_, errors = f.exec(graphqlMember, "# inline\n{ ... on Query { tasks(first: 10000000000) { id } } }", nil)
+	require.Len(t, errors, 1)
+	assert.Contains(t, errors[0], "complexity")
+
+	_, errors = f.exec(graphqlMember, `{ tasks(first: 2) { comments(last: 1) { author { username } } } }`, nil)
+	assert.Empty(t, errors)
+
+	_, errors = f.exec(graphqlMember, `{ __schema { types { fields { type { ofType { ofType { ofType { ofType { name } } } } } } } } }`, nil)
+	require.NotEmpty(t, errors)
+	assert.Contains(t, errors[0], "depth")
+}
+
+// Test mutations need the roles their REST routes need, and are audited
+func TestGraphQLMutations(t *testing.T) {
+	f := newGraphQLFixture()
+	mutation := `mutation { createTask(input: {title: "Plan", assignee: "bob", labels: ["ops"]}) { id title labels assignee { username } } }`
+
+	data, errors := f.exec(graphqlMember, mutation, nil)
+	assert.Equal(t, []string{"Requires the admin role in the workspace"}, errors)
+	assert.Nil(t, data)
+	assert.Empty(t, f.tasks.created)
+
+	data, errors = f.exec(graphql.Viewer{Username: "alice", Admin: true, WorkspaceID: 1}, mutation, nil)
+	require.Empty(t, errors)
+	assert.Equal(t, map[string]interface{}{
+		"id": float64(100), "title": "Plan", "labels": []interface{}{"ops"},
+		"assignee": map[string]interface{}{"username": "bob"},
+	}, data["createTask"])
+	assert.Equal(t, []string{Domain.ActionTaskCreated}, f.audited)
+}
+
+// Test subscriptions send the task events as they are published
+func TestGraphQLSubscription(t *testing.T) {
+	f := newGraphQLFixture()
+	ctx, cancel := context.WithCancel(graphql.WithViewer(context.Background(), graphqlMember))
+	defer cancel()
+
+	responses, err := f.schema.Subscribe(ctx, graphql.Request{Query: `subscription { taskChanged(id: 2) { type actor task { title assignee { username } } } }`})
+	require.NoError(t, err)
+
+	f.events.bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated, Actor: "bob", TaskID: 1,
+		Data: map[string]interface{}{"task": Domain.Task{ID: 1, Title: "Fix login"}}})
+	f.events.bus.Publish(Domain.Event{Type: Domain.ActionTaskUpdated, Actor: "bob", TaskID: 2,
+		Data: map[string]interface{}{"task": Domain.Task{ID: 2, Title: "Write more docs", Assignee: "bob"}}})
+
+	select {
+	case response := <-responses:
+		encoded, _ := json.Marshal(response)
+		assert.JSONEq(t, `{"data":{"taskChanged":{"type":"task.updated","actor":"bob","task":{"title":"Write more docs","assignee":{"username":"bob"}}}}}`, string(encoded))
+	case <-time.After(time.Second):
+		t.Fatal("no event sent")
+	}
+
+	cancel()
+	for range responses {
+	}
+}
+
+// Test operations run over the graphql-transport-ws protocol
+func TestGraphQLWebSocket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := controllers.NewController("test_task_manager")
+	router := gin.New()
+	router.GET("/graphql/ws", controller.GraphQLWS)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/graphql/ws"
+	_, err := websocket.Dial(url, "", server.URL)
+	assert.Error(t, err, "the subprotocol is required")
+
+	ws, err := websocket.Dial(url, "graphql-transport-ws", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	type message struct {
+		ID      string          `json:"id,omitempty"`
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload,omitempty"`
+	}
+	receive := func() message {
+		var m message
+		require.NoError(t, websocket.JSON.Receive(ws, &m))
+		return m
+	}
+
+	require.NoError(t, websocket.JSON.Send(ws, message{Type: "connection_init"}))
+	assert.Equal(t, "connection_ack", receive().Type)
+
+	websocket.JSON.Send(ws, message{ID: "1", Type: "subscribe", Payload: json.RawMessage(`{"query":"{ __typename }"}`)})
+	next := receive()
+	assert.Equal(t, message{ID: "1", Type: "next", Payload: json.RawMessage(`{"data":{"__typename":"Query"}}`)}, next)
+	assert.Equal(t, message{ID: "1", Type: "complete"}, receive())
+
+	websocket.JSON.Send(ws, message{ID: "2", Type: "subscribe", Payload: json.RawMessage(`{"query":"subscription { taskChanged { type task { title } } }"}`)})
+	websocket.JSON.Send(ws, message{Type: "ping"})
+	assert.Equal(t, "pong", receive().Type)
+
+	Usecases.Events.Publish(Domain.Event{Type: Domain.ActionTaskCreated, WorkspaceID: Domain.DefaultWorkspaceID, TaskID: 9,
+		Data: map[string]interface{}{"task": Domain.Task{ID: 9, Title: "Streamed"}}})
+	next = receive()
+	assert.Equal(t, "next", next.Type)
+	assert.JSONEq(t, `{"data":{"taskChanged":{"type":"task.created","task":{"title":"Streamed"}}}}`, string(next.Payload))
+
+	websocket.JSON.Send(ws, message{ID: "2", Type: "complete"})
+	websocket.JSON.Send(ws, message{Type: "ping"})
+	assert.Equal(t, "pong", receive().Type)
+}
+
+// graphqlWSServer serves GET /graphql/ws on a server whose requests time out
+// after readTimeout, when it is not 0.
+func graphqlWSServer(t *testing.T, readTimeout time.Duration) (*httptest.Server, string) {
+	gin.SetMode(gin.TestMode)
+	controller := controllers.NewController("test_task_manager")
+	router := gin.New()
+	router.GET("/graphql/ws", controller.GraphQLWS)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.ReadTimeout = readTimeout
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, "ws" + strings.TrimPrefix(server.URL, "http") + "/graphql/ws"
+}
+
+// Test subscriptions outlive the read timeout of the server
+func TestGraphQLWebSocketReadTimeout(t *testing.T) {
+	server, url := graphqlWSServer(t, 200*time.Millisecond)
+	ws, err := websocket.Dial(url, "graphql-transport-ws", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var message map[string]interface{}
+	websocket.JSON.Send(ws, map[string]string{"type": "connection_init"})
+	require.NoError(t, websocket.JSON.Receive(ws, &message))
+	websocket.JSON.Send(ws, map[string]interface{}{"id": "1", "type": "subscribe", "payload": map[string]string{"query": "subscription { taskChanged(id: 31) { type } }"}})
+	websocket.JSON.Send(ws, map[string]string{"type": "ping"})
+	require.NoError(t, websocket.JSON.Receive(ws, &message))
+	assert.Equal(t, "pong", message["type"])
+
+	time.Sleep(500 * time.Millisecond)
+	Usecases.Events.Publish(Domain.Event{Type: Domain.ActionTaskUpdated, WorkspaceID: Domain.DefaultWorkspaceID, TaskID: 31,
+		Data: map[string]interface{}{"task": Domain.Task{ID: 31}}})
+	require.NoError(t, websocket.JSON.Receive(ws, &message))
+	assert.Equal(t, "next", message["type"])
+}
+
+// Test browsers may only open the WebSocket from allowed origins
+func TestGraphQLWebSocketOrigin(t *testing.T) {
+	server, url := graphqlWSServer(t, 0)
+	t.Cleanup(func() { Infrastructure.UseAllowedOrigins(nil) })
+
+	_, err := websocket.Dial(url, "graphql-transport-ws", "https://evil.example.com")
+	assert.Error(t, err)
+
+	Infrastructure.UseAllowedOrigins([]string{"https://app.example.com"})
+	for _, origin := range []string{server.URL, "https://app.example.com"} {
+		ws, err := websocket.Dial(url, "graphql-transport-ws", origin)
+		if assert.NoError(t, err, origin) {
+			ws.Close()
+		}
+	}
+	_, err = websocket.Dial(url, "graphql-transport-ws", "https://evil.example.com")
+	assert.Error(t, err)
+}
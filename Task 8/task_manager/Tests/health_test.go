@@ -0,0 +1,99 @@
+package Tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task_manager/Delivery/controllers"
+	"task_manager/Infrastructure"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	var out strings.Builder
+	assert.NoError(t, Infrastructure.WriteMetrics(&out))
+	return out.String()
+}
+
+// Test liveness does not depend on the database
+func TestHealthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", (&controllers.Controller{}).Healthz)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+// Test readiness checks the database
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", (&controllers.Controller{}).Readyz)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// Test requests are counted by route pattern and status
+func TestRequestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Infrastructure.CountRequests)
+	router.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+	router.GET("/metrics", Infrastructure.Metrics)
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE http_requests_total counter\n")
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/metrics-test/:id",status="418"} 2`)
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/metrics-test/:id",status="418",le="+Inf"} 2`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="418"} 2`)
+	assert.NotContains(t, body, "/metrics-test/1")
+}
+
+// Test database latencies fall in the right histogram buckets
+func TestDBOperationMetrics(t *testing.T) {
+	Infrastructure.ObserveDBOperation("TestRepository", "GetThings", 30*time.Millisecond)
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `db_operation_duration_seconds_bucket{repository="TestRepository",method="GetThings",le="0.025"} 0`)
+	assert.Contains(t, body, `db_operation_duration_seconds_bucket{repository="TestRepository",method="GetThings",le="0.05"} 1`)
+	assert.Contains(t, body, `db_operation_duration_seconds_sum{repository="TestRepository",method="GetThings"} 0.03`)
+}
+
+// Test refused tokens are counted by reason
+func TestAuthFailureMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/logged", Infrastructure.Logged, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/logged", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, scrapeMetrics(t), `auth_failures_total{reason="invalid_token"} `)
+}
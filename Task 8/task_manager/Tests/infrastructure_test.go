@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"task_manager/Domain"
 	"task_manager/Infrastructure"
 	"testing"
 
@@ -33,6 +34,7 @@ func (suite *AuthMiddlewareTestSuite) SetupSuite() {
 	suite.router = gin.Default()
 
 	// Register routes once in SetupSuite
+	Infrastructure.UseDatabase("test_task_manager")
 	suite.router.POST("/login", Infrastructure.Login("test_task_manager"))
 	suite.router.GET("/logged", Infrastructure.Logged)
 	suite.router.GET("/admin", Infrastructure.Admin)
@@ -66,13 +68,13 @@ func (suite *PasswordServiceTestSuite) TestComparePasswords_EmptyPassword() {
 
 // JWTServiceTestSuite tests
 func (suite *JWTServiceTestSuite) TestGenerateToken_Success() {
-	token, err := Infrastructure.GenerateToken("testuser", "user")
+	token, err := Infrastructure.GenerateToken("testuser", Domain.DefaultWorkspaceID)
 	suite.NoError(err)
 	suite.NotEmpty(token)
 }
 
 func (suite *JWTServiceTestSuite) TestValidateToken_Success() {
-	tokenString, _ := Infrastructure.GenerateToken("testuser", "user")
+	tokenString, _ := Infrastructure.GenerateToken("testuser", Domain.DefaultWorkspaceID)
 	token, err := Infrastructure.ValidateToken(tokenString)
 	suite.NoError(err)
 	suite.True(token.Valid)
@@ -131,7 +133,7 @@ func (suite *AuthMiddlewareTestSuite) TestLogged_InvalidToken() {
 
 func (suite *AuthMiddlewareTestSuite) TestAdmin_Unauthorized() {
 	w := httptest.NewRecorder()
-	token, _ := Infrastructure.GenerateToken("testuser", "user")
+	token, _ := Infrastructure.GenerateToken("testuser", Domain.DefaultWorkspaceID)
 	req, _ := http.NewRequest("GET", "/admin", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 	suite.router.ServeHTTP(w, req)
@@ -0,0 +1,37 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LabelUsecaseTestSuite struct {
+	suite.Suite
+	labelService Usecases.ILabelService
+}
+
+func (suite *LabelUsecaseTestSuite) SetupTest() {
+	suite.labelService = Usecases.NewLabelService("test_task_manager")
+}
+
+// Test labels need a hex color
+func (suite *LabelUsecaseTestSuite) TestCreateLabel_InvalidColor() {
+	_, err := suite.labelService.CreateLabel(Domain.Label{Name: "release-1.2", Color: "green"})
+
+	assert.ErrorIs(suite.T(), err, Usecases.ErrInvalidLabel)
+}
+
+// Test label names cannot be used as list separators
+func (suite *LabelUsecaseTestSuite) TestCreateLabel_InvalidName() {
+	_, err := suite.labelService.CreateLabel(Domain.Label{Name: "a,b", Color: "#1f883d"})
+
+	assert.ErrorIs(suite.T(), err, Usecases.ErrInvalidLabel)
+}
+
+func TestLabelUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(LabelUsecaseTestSuite))
+}
@@ -0,0 +1,124 @@
+package Tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"task_manager/Delivery/controllers"
+	"task_manager/Infrastructure"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// captureLogs makes the default logger write to a buffer until the test ends.
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(Infrastructure.NewLogger(&out, level))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &out
+}
+
+func requestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Infrastructure.RequestID, Infrastructure.LogRequests)
+	router.GET("/ping", func(c *gin.Context) {
+		slog.InfoContext(c.Request.Context(), "pong")
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+// Test the request ID of the client is kept, returned and logged
+func TestRequestIDAccepted(t *testing.T) {
+	logs := captureLogs(t, slog.LevelInfo)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	req.Header.Set(Infrastructure.RequestIDHeader, "client-id-42")
+	requestIDRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, "client-id-42", w.Header().Get(Infrastructure.RequestIDHeader))
+	lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(line, &record))
+		assert.Equal(t, "client-id-42", record["request_id"])
+	}
+}
+
+// Test a request ID is generated when the client sends none or an unusable one
+func TestRequestIDGenerated(t *testing.T) {
+	captureLogs(t, slog.LevelInfo)
+	for _, header := range []string{"", "two words", "line\nbreak"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ping", nil)
+		if header != "" {
+			req.Header[Infrastructure.RequestIDHeader] = []string{header}
+		}
+		requestIDRouter().ServeHTTP(w, req)
+
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), w.Header().Get(Infrastructure.RequestIDHeader))
+	}
+}
+
+// Test records logged with a context carry its request and trace IDs
+func TestLoggerContextIDs(t *testing.T) {
+	var out bytes.Buffer
+	logger := Infrastructure.NewLogger(&out, slog.LevelInfo)
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(Infrastructure.WithRequestID(context.Background(), "abc"), "op")
+	defer span.End()
+
+	logger.DebugContext(ctx, "hidden")
+	logger.InfoContext(ctx, "shown", "answer", 42)
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "shown", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, float64(42), record["answer"])
+	assert.Equal(t, "abc", record["request_id"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+}
+
+// Test the spans of a request nest from the route to the service to the repository
+func TestTraceSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	gin.SetMode(gin.TestMode)
+	controller := controllers.NewController("test_task_manager")
+	router := gin.New()
+	router.Use(Infrastructure.Trace)
+	router.GET("/labels", controller.GetLabels)
+
+	req, _ := http.NewRequest("GET", "/labels", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, service, repository := spans["GET /labels"], spans["LabelService.GetLabels"], spans["LabelRepository.GetLabels"]
+	if assert.NotNil(t, server) && assert.NotNil(t, service) && assert.NotNil(t, repository) {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+		assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+		assert.Equal(t, service.SpanContext().SpanID(), repository.Parent().SpanID())
+	}
+}
@@ -0,0 +1,28 @@
+package Tests
+
+import (
+	"context"
+	"log"
+	"os"
+	"task_manager/Infrastructure"
+	"task_manager/Repositories"
+	"testing"
+	"time"
+)
+
+// TestMain connects to the MongoDB server of the default configuration
+// before running the tests.
+func TestMain(m *testing.M) {
+	if err := Repositories.Connect(Infrastructure.DefaultConfig().Mongo.URI); err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err := Repositories.WaitForServer(ctx)
+	cancel()
+	if err != nil {
+		log.Fatal(err)
+	}
+	code := m.Run()
+	Repositories.Disconnect(context.Background())
+	os.Exit(code)
+}
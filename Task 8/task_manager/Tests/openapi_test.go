@@ -0,0 +1,180 @@
+package Tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"task_manager/Delivery/client"
+	"task_manager/Delivery/openapi"
+	"task_manager/Delivery/routers"
+	"task_manager/Infrastructure"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the router serves every operation of the document, along with the
+// unversioned aliases of the ones of /v1, and no other
+func TestOpenAPIRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := routers.SetupRouter("test_task_manager")
+
+	routed := []string{}
+	for _, route := range router.Routes() {
+		routed = append(routed, route.Method+" "+route.Path)
+	}
+	documented := []string{}
+	for _, route := range openapi.Spec().Routes() {
+		documented = append(documented, route.Method+" "+route.Path)
+		if legacy := Infrastructure.UnversionedRoute(route.Path); legacy != route.Path {
+			documented = append(documented, route.Method+" "+legacy)
+		}
+	}
+	assert.ElementsMatch(t, documented, routed)
+}
+
+// Test the operations documented as secured refuse requests without a token
+// with the documented error body
+func TestOpenAPISecurity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := routers.SetupRouter("test_task_manager")
+
+	for _, route := range openapi.Spec().Routes() {
+		if len(route.Operation.Security) == 0 {
+			continue
+		}
+		path := strings.NewReplacer(":username", "alice", ":id", "1", ":rev", "1", ":blocker_id", "2",
+			":comment_id", "1", ":attachment_id", "1", ":worklog_id", "1", ":delivery_id", "1").Replace(route.Path)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route.Method, path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route.Method+" "+route.Path)
+		var body openapi.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.NotEmpty(t, body.Error, route.Method+" "+route.Path)
+	}
+}
+
+// collectRefs gathers the $ref values of a decoded JSON document.
+func collectRefs(value interface{}, refs map[string]bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs[ref] = true
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+// Test the document and its docs page are served, with every reference
+// resolved
+func TestOpenAPIDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/openapi.json", openapi.Serve)
+	router.GET("/docs", openapi.Docs)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+
+	refs := map[string]bool{}
+	collectRefs(document, refs)
+	assert.Contains(t, refs, "#/components/schemas/Task")
+	components := document["components"].(map[string]interface{})
+	for ref := range refs {
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		require.Len(t, parts, 2, ref)
+		kind, _ := components[parts[0]].(map[string]interface{})
+		assert.Contains(t, kind, parts[1], ref)
+	}
+
+	task := openapi.Spec().Components.Schemas["Task"]
+	assert.Contains(t, task.Properties, "due_date")
+	assert.True(t, openapi.Spec().Components.Schemas["User"].Properties["password"].WriteOnly)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/docs", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+}
+
+// Test the generated client is the one the document generates
+func TestClientUpToDate(t *testing.T) {
+	generated, err := openapi.GenerateClient(openapi.JSON(), "client")
+	require.NoError(t, err)
+	committed, err := os.ReadFile("../Delivery/client/client_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(generated), "run go generate ./Delivery/client")
+}
+
+// Test the client sends typed requests and reports failures as errors
+func TestClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/tasks", func(c *gin.Context) {
+		assert.Equal(t, "Bearer secret", c.GetHeader("Authorization"))
+		assert.Equal(t, "bug,ui", c.Query("labels"))
+		assert.Equal(t, "true", c.Query("overdue"))
+		c.JSON(http.StatusOK, []gin.H{{"id": 1, "title": "Fix login", "created_at": "2024-05-01T10:00:00Z"}})
+	})
+	router.POST("/v1/tasks/:id/dependencies", func(c *gin.Context) {
+		var body map[string]int
+		assert.NoError(t, c.ShouldBindJSON(&body))
+		assert.Equal(t, map[string]int{"blocked_by": 2}, body)
+		c.JSON(http.StatusCreated, gin.H{"id": 1, "blocked_by": []int{2}})
+	})
+	router.DELETE("/v1/tasks/:id", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+	})
+	router.GET("/v1/labels", func(c *gin.Context) {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, retry later"})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	api := client.New(server.URL)
+	api.Token = "secret"
+	ctx := context.Background()
+
+	tasks, err := api.ListTasks(ctx, &client.ListTasksParams{Labels: []string{"bug", "ui"}, Overdue: true})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Fix login", tasks[0].Title)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), *tasks[0].CreatedAt)
+
+	task, err := api.AddDependency(ctx, 1, client.DependencyRequest{BlockedBy: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, task.BlockedBy)
+
+	err = api.DeleteTask(ctx, 7)
+	var failure *client.Error
+	require.ErrorAs(t, err, &failure)
+	assert.Equal(t, http.StatusNotFound, failure.StatusCode)
+	assert.Equal(t, "task not found", failure.Message)
+
+	_, err = api.ListLabels(ctx)
+	require.ErrorAs(t, err, &failure)
+	assert.Equal(t, 30*time.Second, failure.RetryAfter)
+}
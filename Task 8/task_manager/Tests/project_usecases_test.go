@@ -0,0 +1,132 @@
+package Tests
+
+import (
+	"fmt"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test ranks are picked between the neighbours of the target position
+func TestPositionRank(t *testing.T) {
+	ranks := []float64{1024, 2048, 3072}
+
+	rank, ok := Usecases.PositionRank(ranks, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 1536.0, rank)
+
+	rank, _ = Usecases.PositionRank(ranks, 0)
+	assert.Equal(t, 0.0, rank)
+
+	rank, _ = Usecases.PositionRank(ranks, 10)
+	assert.Equal(t, 4096.0, rank)
+
+	rank, _ = Usecases.PositionRank(nil, 3)
+	assert.Equal(t, 1024.0, rank)
+}
+
+// Test a full gap between neighbours asks for the column to be renumbered
+func TestPositionRank_NoRoomLeft(t *testing.T) {
+	_, ok := Usecases.PositionRank([]float64{1, 1}, 1)
+
+	assert.False(t, ok)
+}
+
+// Test the role of a project member is looked up by username
+func TestProjectRoleOf(t *testing.T) {
+	project := Domain.Project{Members: []Domain.ProjectMember{
+		{Username: "alice", Role: Domain.ProjectRoleOwner},
+		{Username: "bob", Role: Domain.ProjectRoleViewer},
+	}}
+
+	assert.Equal(t, Domain.ProjectRoleViewer, project.RoleOf("bob"))
+	assert.Equal(t, "", project.RoleOf("carol"))
+}
+
+// newProject creates a project owned by a user of its own, and returns the
+// owner's name along with it.
+func newProject(t *testing.T, service Usecases.IProjectService) (Domain.Project, string) {
+	owner := fmt.Sprintf("owner-%d", time.Now().UnixNano())
+	project, err := service.CreateProject(Domain.Project{Name: "Board"}, owner)
+	require.Nil(t, err)
+	return project, owner
+}
+
+// Test tasks created in a project go to the bottom of their column
+func TestCreateProjectTask(t *testing.T) {
+	service := Usecases.NewProjectService("test_task_manager")
+	project, owner := newProject(t, service)
+
+	first, err := service.CreateProjectTask(project.ID, Domain.Task{Title: "First"}, owner, false)
+	require.Nil(t, err)
+	second, err := service.CreateProjectTask(project.ID, Domain.Task{Title: "Second"}, owner, false)
+	require.Nil(t, err)
+
+	assert.Equal(t, project.ID, first.ProjectID)
+	assert.Equal(t, "pending", first.Status)
+	assert.Greater(t, second.Rank, first.Rank)
+
+	board, err := service.GetBoard(project.ID, owner, false)
+	require.Nil(t, err)
+	require.Len(t, board, len(Domain.DefaultColumns))
+	assert.Equal(t, []int{first.ID, second.ID}, taskIDs(board[0].Tasks))
+	assert.Empty(t, board[1].Tasks)
+}
+
+// Test moving a task reorders its column or changes its status
+func TestMoveProjectTask(t *testing.T) {
+	service := Usecases.NewProjectService("test_task_manager")
+	project, owner := newProject(t, service)
+	first, err := service.CreateProjectTask(project.ID, Domain.Task{Title: "First"}, owner, false)
+	require.Nil(t, err)
+	second, err := service.CreateProjectTask(project.ID, Domain.Task{Title: "Second"}, owner, false)
+	require.Nil(t, err)
+
+	_, err = service.MoveTask(project.ID, second.ID, "pending", 0, owner, false)
+	require.Nil(t, err)
+	board, _ := service.GetBoard(project.ID, owner, false)
+	assert.Equal(t, []int{second.ID, first.ID}, taskIDs(board[0].Tasks))
+
+	moved, err := service.MoveTask(project.ID, first.ID, "in_progress", 0, owner, false)
+	require.Nil(t, err)
+	assert.Equal(t, "in_progress", moved.Status)
+	board, _ = service.GetBoard(project.ID, owner, false)
+	assert.Equal(t, []int{second.ID}, taskIDs(board[0].Tasks))
+	assert.Equal(t, []int{first.ID}, taskIDs(board[1].Tasks))
+
+	_, err = service.MoveTask(project.ID, first.ID, "archived", 0, owner, false)
+	assert.ErrorIs(t, err, Usecases.ErrInvalidProject)
+}
+
+// Test the project roles decide who sees and changes the board
+func TestProjectRoles(t *testing.T) {
+	service := Usecases.NewProjectService("test_task_manager")
+	project, owner := newProject(t, service)
+	task, err := service.CreateProjectTask(project.ID, Domain.Task{Title: "Task"}, owner, false)
+	require.Nil(t, err)
+
+	// A viewer must be a member of the workspace before joining the project
+	viewer := fmt.Sprintf("viewer-%d", time.Now().UnixNano())
+	require.Nil(t, Usecases.NewUserService("test_task_manager").CreateUser(Domain.User{Username: viewer, Password: "password"}))
+	_, err = Usecases.NewWorkspaceService("test_task_manager").SetMember(Domain.Membership{Username: viewer})
+	require.Nil(t, err)
+	_, err = service.SetMember(project.ID, Domain.ProjectMember{Username: viewer, Role: Domain.ProjectRoleViewer}, owner, false)
+	require.Nil(t, err)
+
+	_, err = service.GetBoard(project.ID, viewer, false)
+	assert.Nil(t, err)
+	_, err = service.CreateProjectTask(project.ID, Domain.Task{Title: "Viewed"}, viewer, false)
+	assert.ErrorIs(t, err, Usecases.ErrProjectForbidden)
+	_, err = service.MoveTask(project.ID, task.ID, "in_progress", 0, viewer, false)
+	assert.ErrorIs(t, err, Usecases.ErrProjectForbidden)
+
+	// Projects are hidden from users outside them, but not from admins
+	_, err = service.GetBoard(project.ID, "stranger", false)
+	assert.ErrorIs(t, err, Usecases.ErrProjectNotFound)
+	_, err = service.GetBoard(project.ID, "stranger", true)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,150 @@
+package Tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"task_manager/Infrastructure"
+	"task_manager/Repositories"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func rateLimitRouter(t *testing.T, policies ...Infrastructure.RateLimitPolicy) *gin.Engine {
+	Infrastructure.UseRateLimits(policies, Infrastructure.NewMemoryRateLimitStore())
+	t.Cleanup(func() { Infrastructure.UseRateLimits(nil, nil) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Infrastructure.RateLimit)
+	router.POST("/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func limitedRequest(router *gin.Engine, method, path string, header ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Test token buckets allow bursts and refill over time
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := Infrastructure.NewMemoryRateLimitStore()
+	now := time.Now()
+
+	tokens, taken, _ := store.Take("k", 2, 1, now)
+	assert.True(t, taken)
+	assert.Equal(t, 1.0, tokens)
+	_, taken, _ = store.Take("k", 2, 1, now)
+	assert.True(t, taken)
+	_, taken, _ = store.Take("k", 2, 1, now)
+	assert.False(t, taken)
+
+	_, taken, _ = store.Take("other", 2, 1, now)
+	assert.True(t, taken)
+
+	tokens, taken, _ = store.Take("k", 2, 1, now.Add(1500*time.Millisecond))
+	assert.True(t, taken)
+	assert.InDelta(t, 0.5, tokens, 1e-9)
+
+	tokens, _, _ = store.Take("k", 2, 1, now.Add(time.Hour))
+	assert.Equal(t, 1.0, tokens)
+}
+
+// Test requests over the limit are refused with the rate limit headers
+func TestRateLimitMiddleware(t *testing.T) {
+	router := rateLimitRouter(t, Infrastructure.RateLimitPolicy{
+		Name: "auth", Routes: []string{"POST /login"}, Key: "ip", Limit: 2, Period: Infrastructure.Duration{Duration: time.Minute},
+	})
+
+	w := limitedRequest(router, "POST", "/login")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2;w=60;burst=2", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "POST", "/login").Code)
+	w = limitedRequest(router, "POST", "/login")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Contains(t, scrapeMetrics(t), `rate_limited_total{policy="auth"} `)
+
+	// Routes of no policy are not limited.
+	w = limitedRequest(router, "GET", "/tasks")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+// issuedKeys are the API keys a test issued.
+type issuedKeys map[string]bool
+
+func (k issuedKeys) Issued(key string) bool { return k[key] }
+
+// Test users and issued API keys have buckets of their own, apart from
+// their ip
+func TestRateLimitKeys(t *testing.T) {
+	router := rateLimitRouter(t,
+		Infrastructure.RateLimitPolicy{Name: "users", Routes: []string{"*"}, Key: "user", Limit: 1, Period: Infrastructure.Duration{Duration: time.Minute}},
+		Infrastructure.RateLimitPolicy{Name: "keys", Routes: []string{"POST /login"}, Key: "api_key", Limit: 1, Period: Infrastructure.Duration{Duration: time.Minute}},
+	)
+	alice, _ := Infrastructure.GenerateToken("alice", 1)
+	bob, _ := Infrastructure.GenerateToken("bob", 1)
+
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer "+alice).Code)
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer "+alice).Code)
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer "+bob).Code)
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks").Code)
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer forged").Code)
+
+	Infrastructure.UseAPIKeys(issuedKeys{"key-1": true, "key-2": true})
+	t.Cleanup(func() { Infrastructure.UseAPIKeys(nil) })
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "POST", "/login", Infrastructure.APIKeyHeader, "key-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(router, "POST", "/login", Infrastructure.APIKeyHeader, "key-1").Code)
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "POST", "/login", Infrastructure.APIKeyHeader, "key-2").Code)
+}
+
+// Test made up API keys share the bucket of their user or ip
+func TestRateLimitUnknownAPIKeys(t *testing.T) {
+	router := rateLimitRouter(t,
+		Infrastructure.RateLimitPolicy{Name: "keys", Routes: []string{"*"}, Key: "api_key", Limit: 1, Period: Infrastructure.Duration{Duration: time.Minute}},
+	)
+	alice, _ := Infrastructure.GenerateToken("alice", 1)
+
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks", Infrastructure.APIKeyHeader, "made-up-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(router, "GET", "/tasks", Infrastructure.APIKeyHeader, "made-up-2").Code)
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer "+alice, Infrastructure.APIKeyHeader, "made-up-3").Code)
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(router, "GET", "/tasks", "Authorization", "Bearer "+alice, Infrastructure.APIKeyHeader, "made-up-4").Code)
+
+	Infrastructure.UseAPIKeys(issuedKeys{"issued": true})
+	t.Cleanup(func() { Infrastructure.UseAPIKeys(nil) })
+	assert.Equal(t, http.StatusOK, limitedRequest(router, "GET", "/tasks", Infrastructure.APIKeyHeader, "issued").Code)
+}
+
+// Test the shared store refills and takes tokens like the memory one
+func TestRateLimitRepository(t *testing.T) {
+	repo := Repositories.NewRateLimitRepository("test_task_manager")
+	key := "test:" + time.Now().Format(time.RFC3339Nano)
+	now := time.Now().Truncate(time.Millisecond)
+
+	tokens, taken, err := repo.Take(key, 2, 1, now)
+	assert.NoError(t, err)
+	assert.True(t, taken)
+	assert.Equal(t, 1.0, tokens)
+	repo.Take(key, 2, 1, now)
+	_, taken, _ = repo.Take(key, 2, 1, now)
+	assert.False(t, taken)
+
+	tokens, taken, _ = repo.Take(key, 2, 1, now.Add(1500*time.Millisecond))
+	assert.True(t, taken)
+	assert.InDelta(t, 0.5, tokens, 1e-9)
+}
@@ -0,0 +1,127 @@
+package Tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(value string) time.Time {
+	date, _ := time.Parse("2006-01-02", value)
+	return date
+}
+
+// Test a rule is parsed and formatted back in normal form
+func TestParseRecurrence(t *testing.T) {
+	rule, err := Usecases.ParseRecurrence("RRULE:freq=monthly;byday=2TU,-1fr;until=20241231T235959Z")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Domain.FreqMonthly, rule.Freq)
+	assert.Equal(t, []Domain.WeekdayNum{{Ordinal: 2, Weekday: time.Tuesday}, {Ordinal: -1, Weekday: time.Friday}}, rule.ByDay)
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=2TU,-1FR;UNTIL=20241231", rule.String())
+}
+
+// Test unsupported or inconsistent rules are rejected
+func TestParseRecurrence_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=DAILY;COUNT=3;UNTIL=20241231",
+		"FREQ=DAILY;BYHOUR=9",
+	} {
+		_, err := Usecases.ParseRecurrence(rule)
+		assert.ErrorIs(t, err, Usecases.ErrInvalidRecurrence, rule)
+	}
+}
+
+// Test the dates following an occurrence for each frequency
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		rule string
+		from string
+		want []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", "2024-09-01", []string{"2024-09-03", "2024-09-05"}},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "2024-09-05", []string{"2024-09-06", "2024-09-09"}},
+		{"FREQ=WEEKLY", "2024-09-02", []string{"2024-09-09", "2024-09-16"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2024-09-02", []string{"2024-09-05", "2024-09-16", "2024-09-19"}},
+		{"FREQ=MONTHLY", "2024-01-31", []string{"2024-03-31", "2024-05-31"}},
+		{"FREQ=MONTHLY;BYDAY=2TU", "2024-09-10", []string{"2024-10-08", "2024-11-12"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "2024-09-27", []string{"2024-10-25", "2024-11-29"}},
+	}
+
+	for _, test := range tests {
+		rule, err := Usecases.ParseRecurrence(test.rule)
+		assert.Nil(t, err)
+
+		date := day(test.from)
+		for n, want := range test.want {
+			next, ok := rule.Next(date, n+1)
+			assert.True(t, ok, test.rule)
+			assert.Equal(t, want, next.Format("2006-01-02"), test.rule)
+			date = next
+		}
+	}
+}
+
+// Test a series ends after COUNT occurrences or after UNTIL
+func TestRecurrenceNext_End(t *testing.T) {
+	rule, _ := Usecases.ParseRecurrence("FREQ=DAILY;COUNT=2")
+	_, ok := rule.Next(day("2024-09-01"), 1)
+	assert.True(t, ok)
+	_, ok = rule.Next(day("2024-09-02"), 2)
+	assert.False(t, ok)
+
+	rule, _ = Usecases.ParseRecurrence("FREQ=WEEKLY;UNTIL=20240910")
+	_, ok = rule.Next(day("2024-09-02"), 1)
+	assert.True(t, ok)
+	_, ok = rule.Next(day("2024-09-09"), 2)
+	assert.False(t, ok)
+}
+
+// Test the scheduler stops once its context is done
+func TestRecurrenceSchedulerStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := Usecases.StartRecurrenceScheduler(ctx, Usecases.NewTaskService("test_task_manager"), Usecases.NewWorkspaceService("test_task_manager"))
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the scheduler did not stop")
+	}
+}
+
+// Test completing an imported recurring task creates the next occurrence
+// without the external ID of the imported one
+func TestCompleteImportedRecurringTask(t *testing.T) {
+	service := Usecases.NewTaskService("test_task_manager")
+	externalID := fmt.Sprintf("EXT-%d", time.Now().UnixNano())
+	input := fmt.Sprintf("external_id,title,due_date,recurrence\n%s,Water the plants,%s,FREQ=DAILY\n", externalID, time.Now().UTC().Format("2006-01-02"))
+
+	report, err := service.ImportTasks(strings.NewReader(input), Domain.ImportOptions{Format: Domain.FormatCSV}, "tester")
+	require.Nil(t, err)
+	require.Len(t, report.Rows, 1)
+	imported, err := service.GetTaskByID(report.Rows[0].TaskID)
+	require.Nil(t, err)
+
+	imported.Status = Domain.StatusCompleted
+	assert.Nil(t, service.UpdateTask(imported.ID, imported, "tester"))
+
+	occurrences, err := service.GetOccurrences(imported.ID)
+	require.Nil(t, err)
+	require.Len(t, occurrences, 2)
+	assert.Equal(t, externalID, occurrences[0].ExternalID)
+	assert.Equal(t, "", occurrences[1].ExternalID)
+}
@@ -0,0 +1,114 @@
+package Tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test due dates with and without a time of day
+func TestTaskDue(t *testing.T) {
+	due, ok := Domain.Task{DueDate: "2024-09-01"}.Due()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), due)
+
+	due, ok = Domain.Task{DueDate: "2024-09-01T09:30:00Z"}.Due()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 9, 1, 9, 30, 0, 0, time.UTC), due)
+
+	_, ok = Domain.Task{DueDate: "next week"}.Due()
+	assert.False(t, ok)
+}
+
+// Test only open tasks past their due time are overdue
+func TestTaskIsOverdueAt(t *testing.T) {
+	now := time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC)
+
+	assert.True(t, Domain.Task{DueDate: "2024-09-01", Status: "pending"}.IsOverdueAt(now))
+	assert.False(t, Domain.Task{DueDate: "2024-09-01", Status: "Completed"}.IsOverdueAt(now))
+	assert.False(t, Domain.Task{DueDate: "2024-09-02", Status: "pending"}.IsOverdueAt(now))
+	assert.False(t, Domain.Task{Status: "pending"}.IsOverdueAt(now))
+}
+
+// Test the reminder due depends on the lead times reached
+func TestReminderFor(t *testing.T) {
+	due := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	leadTimes := []time.Duration{24 * time.Hour, time.Hour}
+
+	_, _, ok := Usecases.ReminderFor(due, due.Add(-25*time.Hour), leadTimes)
+	assert.False(t, ok)
+
+	kind, lead, ok := Usecases.ReminderFor(due, due.Add(-2*time.Hour), leadTimes)
+	assert.True(t, ok)
+	assert.Equal(t, Domain.ReminderDueSoon, kind)
+	assert.Equal(t, 24*time.Hour, lead)
+
+	kind, lead, _ = Usecases.ReminderFor(due, due.Add(-time.Minute), leadTimes)
+	assert.Equal(t, Domain.ReminderDueSoon, kind)
+	assert.Equal(t, time.Hour, lead)
+
+	kind, _, _ = Usecases.ReminderFor(due, due, leadTimes)
+	assert.Equal(t, Domain.ReminderOverdue, kind)
+}
+
+// Test the text of reminders
+func TestReminderNotification(t *testing.T) {
+	user := Domain.User{Username: "alice", Email: "alice@example.com"}
+	task := Domain.Task{ID: 4, Title: "Ship it", DueDate: "2024-09-01"}
+
+	notification := Usecases.ReminderNotification(Domain.Reminder{Kind: Domain.ReminderDueSoon, LeadMinutes: 90}, user, task)
+	assert.Equal(t, `Task "Ship it" is due in 1h30m`, notification.Subject)
+	assert.Equal(t, "alice@example.com", notification.Email)
+
+	notification = Usecases.ReminderNotification(Domain.Reminder{Kind: Domain.ReminderOverdue}, user, task)
+	assert.Equal(t, `Task "Ship it" is overdue`, notification.Subject)
+}
+
+// Test the webhook notifier posts the notification as JSON
+func TestWebhookNotifier(t *testing.T) {
+	var received Domain.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	err := Infrastructure.NewWebhookNotifier(server.URL).Notify(Domain.Notification{Recipient: "alice", Subject: "hello"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", received.Subject)
+}
+
+// Test the webhook notifier reports failed deliveries
+func TestWebhookNotifier_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := Infrastructure.NewWebhookNotifier(server.URL).Notify(Domain.Notification{})
+
+	assert.NotNil(t, err)
+}
+
+// Test the mailbox notifier writes one email per notification
+func TestMailboxNotifier(t *testing.T) {
+	notifier := &Infrastructure.MailboxNotifier{Dir: t.TempDir(), From: "tasks@example.com"}
+
+	err := notifier.Notify(Domain.Notification{Recipient: "alice", Email: "alice@example.com", Subject: "Reminder", Body: "Ship it"})
+	assert.Nil(t, err)
+
+	files, _ := os.ReadDir(notifier.Dir)
+	assert.Len(t, files, 1)
+	message, _ := os.ReadFile(notifier.Dir + "/" + files[0].Name())
+	assert.True(t, strings.Contains(string(message), "To: alice@example.com\r\n"))
+	assert.True(t, strings.HasSuffix(string(message), "\r\n\r\nShip it\r\n"))
+}
@@ -0,0 +1,100 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(value string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return &t
+}
+
+var reportedTasks = []Domain.Task{
+	{ID: 1, Status: "completed", Assignee: "alice", ProjectID: 1,
+		CreatedAt: at("2024-08-19T09:00:00Z"), StartedAt: at("2024-08-20T09:00:00Z"), CompletedAt: at("2024-08-21T09:00:00Z")},
+	{ID: 2, Status: "completed", Assignee: "bob", ProjectID: 1,
+		CreatedAt: at("2024-08-20T09:00:00Z"), CompletedAt: at("2024-08-27T09:00:00Z")},
+	{ID: 3, Status: "in_progress", Assignee: "alice", ProjectID: 1, Overdue: true, Priority: "P1", StoryPoints: 3, Estimate: 90,
+		CreatedAt: at("2024-08-26T09:00:00Z"), StartedAt: at("2024-08-26T10:00:00Z")},
+	{ID: 4, Status: "pending", Assignee: "alice", ProjectID: 2, StoryPoints: 2,
+		CreatedAt: at("2024-08-27T09:00:00Z")},
+	{ID: 5, Status: "pending", ProjectID: 1},
+	{ID: 6, Status: "completed", ProjectID: 1, DeletedAt: at("2024-08-28T09:00:00Z"),
+		CreatedAt: at("2024-08-20T09:00:00Z"), CompletedAt: at("2024-08-21T09:00:00Z")},
+}
+
+func memoryReports() Repositories.IReportRepository {
+	return Repositories.NewMemoryReportRepository(func() []Domain.Task { return reportedTasks })
+}
+
+// Test tasks created and completed are counted per ISO week
+func TestMemoryReport_Throughput(t *testing.T) {
+	buckets, err := memoryReports().Throughput(Domain.ReportFilter{Interval: Domain.IntervalWeek})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Domain.ThroughputBucket{
+		{Period: "2024-W34", Created: 2, Completed: 1},
+		{Period: "2024-W35", Created: 2, Completed: 1},
+	}, buckets)
+}
+
+// Test throughput is limited to a project and period
+func TestMemoryReport_ThroughputFiltered(t *testing.T) {
+	filter := Domain.ReportFilter{
+		ProjectID: 1,
+		Interval:  Domain.IntervalDay,
+		From:      *at("2024-08-20T00:00:00Z"),
+		To:        *at("2024-08-27T00:00:00Z"),
+	}
+
+	buckets, err := memoryReports().Throughput(filter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Domain.ThroughputBucket{
+		{Period: "2024-08-20", Created: 1},
+		{Period: "2024-08-21", Completed: 1},
+		{Period: "2024-08-26", Created: 1},
+	}, buckets)
+}
+
+// Test lead times cover every completed task and cycle times the started ones
+func TestMemoryReport_Durations(t *testing.T) {
+	durations, err := memoryReports().Durations(Domain.ReportFilter{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{48, 168}, durations.LeadTimes)
+	assert.Equal(t, []float64{24}, durations.CycleTimes)
+}
+
+// Test overdue tasks and open work are counted per assignee
+func TestMemoryReport_OverdueAndWorkload(t *testing.T) {
+	overdue, err := memoryReports().Overdue(Domain.ReportFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, Domain.OverdueReport{Total: 1, ByAssignee: map[string]int{"alice": 1}, ByPriority: map[string]int{"P1": 1}}, overdue)
+
+	workloads, err := memoryReports().Workload(Domain.ReportFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Domain.Workload{
+		{Assignee: "", Open: 1},
+		{Assignee: "alice", Open: 2, InProgress: 1, Overdue: 1, StoryPoints: 5, EstimateMinutes: 90},
+	}, workloads)
+}
+
+// Test percentiles are taken by nearest rank
+func TestSummarize(t *testing.T) {
+	values := []float64{}
+	for i := 1; i <= 20; i++ {
+		values = append(values, float64(i))
+	}
+
+	summary := Usecases.Summarize(values)
+
+	assert.Equal(t, Domain.Percentiles{Count: 20, Mean: 10.5, P50: 10, P75: 15, P90: 18, P95: 19, Max: 20}, summary)
+	assert.Equal(t, Domain.Percentiles{}, Usecases.Summarize(nil))
+}
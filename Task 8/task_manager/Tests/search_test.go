@@ -0,0 +1,113 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test words, phrases, prefixes and qualifiers are told apart
+func TestParseSearchQuery(t *testing.T) {
+	query, err := Usecases.ParseSearchQuery(`Deploy "release notes" stag* status:pending assignee:alice due:<2024-09-01`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"deploy"}, query.Terms)
+	assert.Equal(t, []string{"release notes"}, query.Phrases)
+	assert.Equal(t, []string{"stag"}, query.Prefixes)
+	assert.Equal(t, "pending", query.Status)
+	assert.Equal(t, "alice", query.Assignee)
+	assert.Equal(t, []Domain.DueCondition{{Op: "<", Date: "2024-09-01"}}, query.Due)
+}
+
+// Test invalid queries are rejected
+func TestParseSearchQuery_Invalid(t *testing.T) {
+	_, err := Usecases.ParseSearchQuery(`"" *`)
+	assert.ErrorIs(t, err, Usecases.ErrInvalidQuery)
+
+	_, err = Usecases.ParseSearchQuery("deploy due:<tomorrow")
+	assert.ErrorIs(t, err, Usecases.ErrInvalidQuery)
+}
+
+// Test the text around and inside the marks is escaped as HTML
+func TestHighlight_EscapesHTML(t *testing.T) {
+	query, _ := Usecases.ParseSearchQuery("deploy")
+
+	highlights := Usecases.Highlight(map[string]string{
+		"description": `<script>alert("deploy")</script> & <b>deploy</b>`,
+	}, query)
+
+	assert.Equal(t, map[string]string{
+		"description": `&lt;script&gt;alert(&#34;<mark>deploy</mark>&#34;)&lt;/script&gt; &amp; &lt;b&gt;<mark>deploy</mark>&lt;/b&gt;`,
+	}, highlights)
+}
+
+// Test a query made only of qualifiers is a filter
+func TestParseSearchQuery_QualifiersOnly(t *testing.T) {
+	query, err := Usecases.ParseSearchQuery("status:open assignee:bob")
+
+	assert.Nil(t, err)
+	assert.False(t, query.HasText())
+	assert.Equal(t, "open", query.Status)
+	assert.Equal(t, "bob", query.Assignee)
+}
+
+// Test matches are wrapped in mark tags
+func TestHighlight(t *testing.T) {
+	query, _ := Usecases.ParseSearchQuery(`deploy* "release notes"`)
+
+	highlights := Usecases.Highlight(map[string]string{
+		"title":       "Deployment of the Release Notes page",
+		"description": "nothing to see",
+	}, query)
+
+	assert.Equal(t, map[string]string{
+		"title": "<mark>Deployment</mark> of the <mark>Release Notes</mark> page",
+	}, highlights)
+}
+
+// Test the in-memory index ranks, filters and forgets documents
+func TestMemorySearchRepository(t *testing.T) {
+	index := Repositories.NewMemorySearchRepository()
+	index.IndexTask(Domain.Task{ID: 1, Title: "Deploy staging", Description: "after the release", Status: "pending"})
+	index.IndexTask(Domain.Task{ID: 2, Title: "Write release notes", Description: "deploy docs", Status: "completed"})
+	index.IndexComment(Domain.Comment{ID: 7, TaskID: 2, Body: "The release notes are ready"})
+
+	query, _ := Usecases.ParseSearchQuery("deploy")
+	hits, err := index.Search(query, 10)
+	assert.Nil(t, err)
+	assert.Len(t, hits, 2)
+	// A match in the title weighs more than one in the description
+	assert.Equal(t, 1, hits[0].TaskID)
+
+	query, _ = Usecases.ParseSearchQuery(`"release notes" status:completed`)
+	hits, _ = index.Search(query, 10)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, "Write release notes", hits[1].Title)
+
+	index.RemoveTask(2)
+	hits, _ = index.Search(query, 10)
+	assert.Empty(t, hits)
+}
+
+// Test the in-memory index returns the tasks matching a filter-only query
+func TestMemorySearchRepository_QualifiersOnly(t *testing.T) {
+	index := Repositories.NewMemorySearchRepository()
+	index.IndexTask(Domain.Task{ID: 1, Title: "Deploy staging", Status: "pending", Assignee: "bob"})
+	index.IndexTask(Domain.Task{ID: 2, Title: "Write release notes", Status: "pending", Assignee: "alice"})
+	index.IndexTask(Domain.Task{ID: 3, Title: "Fix the build", Status: "pending", Assignee: "bob"})
+	index.IndexComment(Domain.Comment{ID: 7, TaskID: 1, Body: "On it"})
+
+	query, _ := Usecases.ParseSearchQuery("status:pending assignee:bob")
+	hits, err := index.Search(query, 10)
+	assert.Nil(t, err)
+	assert.Len(t, hits, 2)
+	assert.Equal(t, 1, hits[0].TaskID)
+	assert.Equal(t, 3, hits[1].TaskID)
+	assert.Equal(t, Domain.SearchTask, hits[1].Type)
+
+	hits, _ = index.Search(query, 1)
+	assert.Len(t, hits, 1)
+}
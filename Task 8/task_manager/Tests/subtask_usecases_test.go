@@ -0,0 +1,49 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test a dependency closing a loop is detected
+func TestCreatesDependencyCycle(t *testing.T) {
+	// 1 is blocked by 2, which is blocked by 3
+	graph := map[int][]int{1: {2}, 2: {3}}
+	blockedBy := func(id int) []int { return graph[id] }
+
+	assert.True(t, Usecases.CreatesDependencyCycle(3, 1, blockedBy))
+	assert.True(t, Usecases.CreatesDependencyCycle(2, 2, blockedBy))
+	assert.False(t, Usecases.CreatesDependencyCycle(1, 3, blockedBy))
+	assert.False(t, Usecases.CreatesDependencyCycle(4, 1, blockedBy))
+}
+
+// Test progress rolls up from subtasks and checklists
+func TestRollUpProgress(t *testing.T) {
+	children := map[int][]Domain.Task{
+		1: {
+			{ID: 2, Status: Domain.StatusCompleted},
+			{ID: 3, Checklist: []Domain.ChecklistItem{{Text: "a", Done: true}, {Text: "b"}}},
+		},
+	}
+	subtasksOf := func(id int) []Domain.Task { return children[id] }
+
+	progress := Usecases.RollUpProgress(Domain.Task{ID: 1, Status: "pending"}, subtasksOf)
+
+	assert.Equal(t, 2, progress.TotalSubtasks)
+	assert.Equal(t, 1, progress.CompletedSubtasks)
+	assert.Equal(t, 75.0, progress.Percent)
+}
+
+// Test a completed task is done regardless of its checklist
+func TestRollUpProgress_Completed(t *testing.T) {
+	task := Domain.Task{ID: 1, Status: "Completed", Checklist: []Domain.ChecklistItem{{Text: "a"}}}
+
+	progress := Usecases.RollUpProgress(task, func(int) []Domain.Task { return nil })
+
+	assert.Equal(t, 100.0, progress.Percent)
+	assert.Equal(t, 0, progress.ChecklistDone)
+	assert.Equal(t, 1, progress.ChecklistTotal)
+}
@@ -45,6 +45,64 @@ func (suite *TaskUsecaseTestSuite) TestGetTaskByID_TaskDoesNotExist() {
 	assert.Equal(suite.T(), Domain.Task{}, task)
 }
 
+// Test reverting a task restores the fields of the chosen revision
+func (suite *TaskUsecaseTestSuite) TestRevertTask() {
+	task, err := suite.taskService.CreateTask(Domain.Task{Title: "Original", Status: "pending"}, "tester")
+	assert.Nil(suite.T(), err)
+
+	err = suite.taskService.UpdateTask(task.ID, Domain.Task{Title: "Changed", Status: "completed"}, "tester")
+	assert.Nil(suite.T(), err)
+
+	reverted, err := suite.taskService.RevertTask(task.ID, 1, "tester")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Original", reverted.Title)
+	assert.Equal(suite.T(), "pending", reverted.Status)
+
+	history, err := suite.taskService.GetHistory(task.ID)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), history, 3)
+	assert.Equal(suite.T(), Domain.RevisionReverted, history[2].Action)
+	assert.Equal(suite.T(), Domain.FieldChange{From: "Changed", To: "Original"}, history[2].Changes["title"])
+}
+
+// Test a deleted task goes to the trash and can be restored
+func (suite *TaskUsecaseTestSuite) TestDeleteAndRestoreTask() {
+	task, err := suite.taskService.CreateTask(Domain.Task{Title: "Trashed"}, "tester")
+	assert.Nil(suite.T(), err)
+
+	assert.Nil(suite.T(), suite.taskService.DeleteTask(task.ID, "tester"))
+	_, err = suite.taskService.GetTaskByID(task.ID)
+	assert.NotNil(suite.T(), err)
+	assert.Contains(suite.T(), taskIDs(suite.taskService.GetTrash()), task.ID)
+
+	restored, err := suite.taskService.RestoreTask(task.ID, "tester")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), restored.DeletedAt)
+	assert.NotContains(suite.T(), taskIDs(suite.taskService.GetTrash()), task.ID)
+}
+
+func taskIDs(tasks []Domain.Task) []int {
+	ids := []int{}
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// Test updates change the due date that tasks are read with
+func (suite *TaskUsecaseTestSuite) TestUpdateTask_DueDate() {
+	task, err := suite.taskService.CreateTask(Domain.Task{Title: "Due soon", Status: "pending", DueDate: "2024-09-01"}, "tester")
+	assert.Nil(suite.T(), err)
+
+	task.DueDate = "2024-10-01"
+	err = suite.taskService.UpdateTask(task.ID, task, "tester")
+	assert.Nil(suite.T(), err)
+
+	updated, err := suite.taskService.GetTaskByID(task.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "2024-10-01", updated.DueDate)
+}
+
 // Run the test suite
 func TestTaskUsecaseTestSuite(t *testing.T) {
 	suite.Run(t, new(TaskUsecaseTestSuite))
@@ -0,0 +1,107 @@
+package Tests
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exportedTasks = []Domain.Task{
+	{ID: 1, Title: "Ship it", Status: "completed", DueDate: "2024-09-01", Labels: []string{"release", "ops"}, StoryPoints: 2.5},
+	{ID: 2, Title: "Write notes, then publish", Description: "line one\nline two", Status: "pending", DueDate: "2024-09-02T09:30:00Z", Priority: "P1", ExternalID: "JIRA-7"},
+}
+
+// Test tasks are exported as CSV with a header
+func TestWriteTasks_CSV(t *testing.T) {
+	var out bytes.Buffer
+
+	err := Usecases.WriteTasks(&out, Domain.FormatCSV, exportedTasks, time.Now())
+
+	assert.Nil(t, err)
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, "id,external_id,title,description,status,due_date,assignee,priority,labels,story_points,estimate_minutes,project_id,parent_id,recurrence", lines[0])
+	assert.Equal(t, `1,,Ship it,,completed,2024-09-01,,,"release,ops",2.5,,,,`, lines[1])
+}
+
+// Test tasks are exported as iCalendar to-dos due on their due date
+func TestWriteTasks_ICalendar(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Date(2024, 8, 20, 9, 0, 0, 0, time.UTC)
+
+	err := Usecases.WriteTasks(&out, Domain.FormatICS, exportedTasks, now)
+
+	assert.Nil(t, err)
+	calendar := out.String()
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Contains(t, calendar, "UID:task-1@task_manager\r\nDTSTAMP:20240820T090000Z\r\n")
+	assert.Contains(t, calendar, "DUE;VALUE=DATE:20240901\r\nSTATUS:COMPLETED\r\n")
+	assert.Contains(t, calendar, "CATEGORIES:release,ops\r\n")
+	assert.Contains(t, calendar, "SUMMARY:Write notes\\, then publish\r\nDESCRIPTION:line one\\nline two\r\n")
+	assert.Contains(t, calendar, "DUE:20240902T093000Z\r\nSTATUS:NEEDS-ACTION\r\nPRIORITY:3\r\n")
+}
+
+// Test long iCalendar lines are folded
+func TestWriteTasks_ICalendarFolding(t *testing.T) {
+	var out bytes.Buffer
+	tasks := []Domain.Task{{ID: 1, Title: strings.Repeat("é", 100)}}
+
+	Usecases.WriteTasks(&out, Domain.FormatICS, tasks, time.Now())
+
+	for _, line := range strings.Split(out.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, strings.ReplaceAll(out.String(), "\r\n ", ""), "SUMMARY:"+strings.Repeat("é", 100))
+}
+
+// Test unknown export formats are rejected
+func TestWriteTasks_UnknownFormat(t *testing.T) {
+	err := Usecases.WriteTasks(&bytes.Buffer{}, "xml", exportedTasks, time.Now())
+
+	assert.True(t, errors.Is(err, Usecases.ErrUnknownFormat))
+}
+
+// Test CSV columns are mapped to task fields by mapping or by name
+func TestParseImport_CSV(t *testing.T) {
+	input := "\ufeffKey,Summary,Status,Story Points,Sprint\nJIRA-7,Ship it,pending,3,12\n"
+
+	rows, ignored, err := Usecases.ParseImport(strings.NewReader(input), Domain.FormatCSV, map[string]string{"key": "external_id", "Summary": "title"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Sprint"}, ignored)
+	assert.Equal(t, []map[string]interface{}{
+		{"external_id": "JIRA-7", "title": "Ship it", "status": "pending", "story_points": "3"},
+	}, rows)
+}
+
+// Test JSON imports may be arrays or JSON Lines
+func TestParseImport_JSON(t *testing.T) {
+	for _, input := range []string{
+		`[{"title": "One", "id": 4}, {"title": "Two"}]`,
+		"{\"title\": \"One\", \"id\": 4}\n{\"title\": \"Two\"}\n",
+	} {
+		rows, ignored, err := Usecases.ParseImport(strings.NewReader(input), Domain.FormatJSON, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"id"}, ignored)
+		assert.Equal(t, []map[string]interface{}{{"title": "One"}, {"title": "Two"}}, rows)
+	}
+}
+
+// Test malformed imports and mappings are rejected
+func TestParseImport_Invalid(t *testing.T) {
+	_, _, err := Usecases.ParseImport(strings.NewReader("title\n"), Domain.FormatCSV, map[string]string{"Key": "rank"})
+	assert.True(t, errors.Is(err, Usecases.ErrInvalidImport))
+
+	_, _, err = Usecases.ParseImport(strings.NewReader("Sprint,Points\n1,2\n"), Domain.FormatCSV, nil)
+	assert.True(t, errors.Is(err, Usecases.ErrInvalidImport))
+
+	_, _, err = Usecases.ParseImport(strings.NewReader(`[{"title": }]`), Domain.FormatJSON, nil)
+	assert.True(t, errors.Is(err, Usecases.ErrInvalidImport))
+}
@@ -0,0 +1,106 @@
+package Tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"task_manager/Delivery/controllers"
+	"task_manager/Delivery/routers"
+	"task_manager/Infrastructure"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// versionedRouter returns the router of the API with a v2 after v1, which
+// serves GET /labels with a stub and no longer serves GET /search.
+func versionedRouter(t *testing.T, legacy routers.LegacyRoutes) *gin.Engine {
+	versions, previous := routers.Versions, routers.Legacy
+	t.Cleanup(func() { routers.Versions, routers.Legacy = versions, previous })
+
+	routers.Versions = append(append([]routers.Version(nil), versions...), routers.Version{
+		Name: "v2",
+		Routes: func(routes *routers.Routes, controller controllers.IController, dbName string) {
+			routes.GET("/labels", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"labels": []string{}})
+			})
+			routes.Remove("GET", "/search")
+		},
+	})
+	routers.Legacy = legacy
+
+	gin.SetMode(gin.TestMode)
+	return routers.SetupRouter("test_task_manager")
+}
+
+func serve(router *gin.Engine, path, version string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	if version != "" {
+		req.Header.Set(Infrastructure.VersionHeader, version)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Test versions are served side by side, each with the routes of the one
+// before it it does not change
+func TestVersionedRoutes(t *testing.T) {
+	router := versionedRouter(t, routers.LegacyRoutes{Enabled: true})
+
+	w := serve(router, "/v1/labels", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "v1", w.Header().Get(Infrastructure.APIVersionHeader))
+
+	w = serve(router, "/v2/labels", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v2", w.Header().Get(Infrastructure.APIVersionHeader))
+
+	w = serve(router, "/v2/tasks/1", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "v2", w.Header().Get(Infrastructure.APIVersionHeader))
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "/v2/search", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "/v1/healthz", "").Code)
+	assert.Empty(t, serve(router, "/v1/labels", "").Header().Get("Deprecation"))
+}
+
+// Test the unversioned routes serve the version asked for, the first by
+// default, and announce they are deprecated
+func TestLegacyRoutes(t *testing.T) {
+	deprecated := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	router := versionedRouter(t, routers.LegacyRoutes{Enabled: true, Deprecated: deprecated, Sunset: sunset})
+
+	w := serve(router, "/labels", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "v1", w.Header().Get(Infrastructure.APIVersionHeader))
+	assert.Equal(t, "@"+strconv.FormatInt(deprecated.Unix(), 10), w.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/labels>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = serve(router, "/labels", "v2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v2", w.Header().Get(Infrastructure.APIVersionHeader))
+
+	assert.Equal(t, http.StatusNotAcceptable, serve(router, "/labels", "v9").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "/search", "v2").Code)
+	assert.Empty(t, serve(router, "/healthz", "").Header().Get("Deprecation"))
+}
+
+// Test the unversioned routes are not served once disabled
+func TestLegacyRoutesDisabled(t *testing.T) {
+	router := versionedRouter(t, routers.LegacyRoutes{})
+	assert.Equal(t, http.StatusNotFound, serve(router, "/labels", "").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "/v2/labels", "").Code)
+}
+
+// Test version prefixes are taken off routes
+func TestUnversionedRoute(t *testing.T) {
+	assert.Equal(t, "/tasks/:id", Infrastructure.UnversionedRoute("/v1/tasks/:id"))
+	assert.Equal(t, "/", Infrastructure.UnversionedRoute("/v12"))
+	assert.Equal(t, "/videos", Infrastructure.UnversionedRoute("/videos"))
+	assert.Equal(t, "/healthz", Infrastructure.UnversionedRoute("/healthz"))
+}
@@ -0,0 +1,45 @@
+package Tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the signature is the HMAC of the timestamp and payload
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"type":"task.created"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(payload)))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, expected, Usecases.SignWebhookPayload("secret", 1700000000, payload))
+	assert.NotEqual(t, expected, Usecases.SignWebhookPayload("secret", 1700000001, payload))
+	assert.NotEqual(t, expected, Usecases.SignWebhookPayload("other", 1700000000, payload))
+}
+
+// Test retries back off exponentially up to a cap
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, Usecases.WebhookBackoff(1))
+	assert.Equal(t, 2*time.Minute, Usecases.WebhookBackoff(2))
+	assert.Equal(t, 64*time.Minute, Usecases.WebhookBackoff(7))
+	assert.Equal(t, 6*time.Hour, Usecases.WebhookBackoff(10))
+	assert.Equal(t, 6*time.Hour, Usecases.WebhookBackoff(100))
+}
+
+// Test webhooks receive the events they subscribe to
+func TestWebhookSubscribes(t *testing.T) {
+	webhook := Domain.Webhook{Events: []string{Domain.ActionTaskCreated, Domain.EventTaskStatusChanged}}
+	assert.True(t, webhook.Subscribes("task.status_changed"))
+	assert.False(t, webhook.Subscribes("user.promoted"))
+
+	webhook.Events = []string{Domain.WebhookAllEvents}
+	assert.True(t, webhook.Subscribes("user.promoted"))
+}
@@ -0,0 +1,49 @@
+package Tests
+
+import (
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test timers are rounded to the nearest minute
+func TestTimerMinutes(t *testing.T) {
+	started := time.Date(2024, 8, 20, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, Usecases.TimerMinutes(started, started.Add(29*time.Second)))
+	assert.Equal(t, 1, Usecases.TimerMinutes(started, started.Add(30*time.Second)))
+	assert.Equal(t, 95, Usecases.TimerMinutes(started, started.Add(time.Hour+35*time.Minute+10*time.Second)))
+}
+
+// Test time logged is compared with the estimate of each task
+func TestBuildTimeReport(t *testing.T) {
+	tasks := []Domain.Task{
+		{ID: 1, Title: "Design", Estimate: 120},
+		{ID: 2, Title: "Build", Estimate: 60},
+		{ID: 3, Title: "Unplanned"},
+		{ID: 4, Title: "Untouched"},
+	}
+	totals := []Domain.TimeTotal{
+		{TaskID: 1, User: "alice", Minutes: 45},
+		{TaskID: 1, User: "bob", Minutes: 30},
+		{TaskID: 2, User: "alice", Minutes: 90},
+		{TaskID: 3, User: "bob", Minutes: 15},
+	}
+
+	report := Usecases.BuildTimeReport(tasks, totals)
+
+	assert.Equal(t, 180, report.EstimateMinutes)
+	assert.Equal(t, 180, report.LoggedMinutes)
+	assert.Len(t, report.Tasks, 3)
+	assert.Equal(t, Domain.TaskTimeUsage{
+		TaskID: 1, Title: "Design", EstimateMinutes: 120, LoggedMinutes: 75, VarianceMinutes: -45,
+		Users: map[string]int{"alice": 45, "bob": 30},
+	}, report.Tasks[0])
+	assert.True(t, report.Tasks[1].OverEstimate)
+	assert.Equal(t, 30, report.Tasks[1].VarianceMinutes)
+	assert.False(t, report.Tasks[2].OverEstimate)
+	assert.Equal(t, 15, report.Tasks[2].LoggedMinutes)
+}
@@ -0,0 +1,50 @@
+package Tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Repositories"
+	"task_manager/Usecases"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the default workspace keeps the instance database and the others get their own
+func TestWorkspaceDatabase(t *testing.T) {
+	assert.Equal(t, "task_manager", Repositories.WorkspaceDatabase("task_manager", Domain.DefaultWorkspaceID))
+	assert.Equal(t, "task_manager_ws2", Repositories.WorkspaceDatabase("task_manager", 2))
+	assert.Equal(t, "task_manager_ws12", Repositories.WorkspaceDatabase("task_manager", 12))
+}
+
+// Test event streams only carry the events of their own workspace
+func TestEventServiceCanSeeWorkspace(t *testing.T) {
+	service := Usecases.NewEventService("test_task_manager").In(2)
+
+	own := Domain.Event{Type: Domain.ActionTaskCreated, WorkspaceID: 2, TaskID: 1}
+	other := Domain.Event{Type: Domain.ActionTaskCreated, WorkspaceID: 3, TaskID: 1}
+
+	assert.True(t, service.CanSee(own, "alice", true))
+	assert.False(t, service.CanSee(other, "alice", true))
+}
+
+// Test tokens of users who are not members of their workspace are refused
+func TestLoggedNotAMember(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	Infrastructure.UseDatabase("test_task_manager")
+	router := gin.New()
+	router.GET("/logged", Infrastructure.Logged, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	token, err := Infrastructure.GenerateToken("nobody", 999)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/logged", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,291 @@
+package Usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	ErrAttachmentType     = errors.New("attachment type not allowed")
+	ErrChecksumMismatch   = errors.New("checksum does not match the content")
+	ErrNotUploader        = errors.New("only the uploader can delete this attachment")
+	ErrNoBlobStore        = errors.New("no blob store configured")
+	ErrBlobStore          = errors.New("blob store failed")
+)
+
+// AttachmentMaxSize is the size of the largest attachment accepted, in bytes.
+var AttachmentMaxSize int64 = 25 << 20
+
+// AttachmentTypes are the media types attachments may have. A type ending
+// in /* allows every subtype.
+var AttachmentTypes = []string{
+	"image/*",
+	"text/plain",
+	"text/markdown",
+	"text/csv",
+	"application/pdf",
+	"application/json",
+	"application/zip",
+	"application/msword",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// IBlobStore stores the content of attachments.
+type IBlobStore interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Open returns the content stored under a key, which can be read from
+	// any offset.
+	Open(key string) (io.ReadSeekCloser, error)
+	Delete(key string) error
+}
+
+// blobStore holds the attachments. Without one, attachments cannot be
+// uploaded.
+var blobStore IBlobStore
+
+// UseBlobStore sets the store attachments are kept in.
+func UseBlobStore(store IBlobStore) {
+	blobStore = store
+}
+
+// Upload is a file uploaded as an attachment. Content must be readable
+// twice: once to check it and once to store it.
+type Upload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Content     io.ReadSeeker
+	// SHA256 is the checksum the client computed, if any, in hex.
+	SHA256 string
+}
+
+type IAttachmentService interface {
+	In(workspaceID int) IAttachmentService
+	WithContext(ctx context.Context) IAttachmentService
+	Upload(taskID int, upload Upload, actor string) (Domain.Attachment, error)
+	GetAttachments(taskID int) ([]Domain.Attachment, error)
+	GetAttachment(taskID, id int) (Domain.Attachment, error)
+	Open(attachment Domain.Attachment) (io.ReadSeekCloser, error)
+	Delete(taskID, id int, actor string, isAdmin bool) (Domain.Attachment, error)
+}
+
+type AttachmentService struct {
+	*workspace
+}
+
+func NewAttachmentService(dbName string) IAttachmentService {
+	return &AttachmentService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the attachments of the given workspace.
+func (a *AttachmentService) In(workspaceID int) IAttachmentService {
+	return &AttachmentService{a.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (a *AttachmentService) WithContext(ctx context.Context) IAttachmentService {
+	return &AttachmentService{a.withContext(ctx)}
+}
+
+// Upload checks the size and type of a file, computes its checksum, and
+// stores it as an attachment of a task.
+func (a *AttachmentService) Upload(taskID int, upload Upload, actor string) (Domain.Attachment, error) {
+	defer a.span("AttachmentService.Upload")()
+	var attachment Domain.Attachment
+	if blobStore == nil {
+		return attachment, ErrNoBlobStore
+	}
+	if _, err := a.taskRepo.GetTaskByID(taskID); err != nil {
+		return attachment, err
+	}
+	if upload.Size > AttachmentMaxSize {
+		return attachment, fmt.Errorf("%w: the limit is %d bytes", ErrAttachmentTooLarge, AttachmentMaxSize)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return attachment, err
+	}
+	contentType, err := AttachmentType(upload.ContentType, head[:n])
+	if err != nil {
+		return attachment, err
+	}
+
+	if _, err := upload.Content.Seek(0, io.SeekStart); err != nil {
+		return attachment, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, upload.Content)
+	if err != nil {
+		return attachment, err
+	}
+	if size > AttachmentMaxSize {
+		return attachment, fmt.Errorf("%w: the limit is %d bytes", ErrAttachmentTooLarge, AttachmentMaxSize)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if upload.SHA256 != "" && !strings.EqualFold(upload.SHA256, checksum) {
+		return attachment, ErrChecksumMismatch
+	}
+	if _, err := upload.Content.Seek(0, io.SeekStart); err != nil {
+		return attachment, err
+	}
+
+	attachment = Domain.Attachment{
+		ID:          a.attachmentRepo.GetNextAttachmentID(),
+		TaskID:      taskID,
+		Filename:    attachmentFilename(upload.Filename),
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      checksum,
+		UploadedBy:  actor,
+		CreatedAt:   time.Now().UTC(),
+	}
+	// Attachment IDs are only unique within a workspace, hence the prefix.
+	attachment.StorageKey = fmt.Sprintf("workspaces/%d/tasks/%d/%d", a.id, taskID, attachment.ID)
+	if err := blobStore.Put(attachment.StorageKey, upload.Content, size, contentType); err != nil {
+		return attachment, fmt.Errorf("%w: %v", ErrBlobStore, err)
+	}
+	if err := a.attachmentRepo.CreateAttachment(attachment); err != nil {
+		if err := blobStore.Delete(attachment.StorageKey); err != nil {
+			slog.ErrorContext(a.ctx, "blob left behind", "key", attachment.StorageKey, "error", err)
+		}
+		return attachment, err
+	}
+	return attachment, nil
+}
+
+func (a *AttachmentService) GetAttachments(taskID int) ([]Domain.Attachment, error) {
+	defer a.span("AttachmentService.GetAttachments")()
+	if _, err := a.taskRepo.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+	return a.attachmentRepo.GetAttachments(taskID)
+}
+
+func (a *AttachmentService) GetAttachment(taskID, id int) (Domain.Attachment, error) {
+	defer a.span("AttachmentService.GetAttachment")()
+	attachment, err := a.attachmentRepo.GetAttachmentByID(id)
+	if err != nil || attachment.TaskID != taskID {
+		return attachment, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (a *AttachmentService) Open(attachment Domain.Attachment) (io.ReadSeekCloser, error) {
+	defer a.span("AttachmentService.Open")()
+	if blobStore == nil {
+		return nil, ErrNoBlobStore
+	}
+	blob, err := blobStore.Open(attachment.StorageKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBlobStore, err)
+	}
+	return blob, nil
+}
+
+// Delete removes an attachment. Only its uploader and admins can.
+func (a *AttachmentService) Delete(taskID, id int, actor string, isAdmin bool) (Domain.Attachment, error) {
+	defer a.span("AttachmentService.Delete")()
+	attachment, err := a.GetAttachment(taskID, id)
+	if err != nil {
+		return attachment, err
+	}
+	if attachment.UploadedBy != actor && !isAdmin {
+		return attachment, ErrNotUploader
+	}
+
+	if err := a.attachmentRepo.DeleteAttachment(id); err != nil {
+		return attachment, err
+	}
+	a.deleteBlob(attachment.StorageKey)
+	return attachment, nil
+}
+
+// AttachmentType returns the media type of an attachment: the one it was
+// uploaded with, or else the one sniffed from its first bytes. Either way it
+// must be one of AttachmentTypes. HTML and SVG, which browsers may run
+// scripts from, are refused whatever type they were sent with.
+func AttachmentType(declared string, head []byte) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if sniffed == "text/html" {
+		return "", fmt.Errorf("%w: text/html", ErrAttachmentType)
+	}
+
+	contentType, _, err := mime.ParseMediaType(declared)
+	if err != nil || contentType == "application/octet-stream" {
+		contentType = sniffed
+	}
+	if contentType == "image/svg+xml" {
+		return "", fmt.Errorf("%w: %s", ErrAttachmentType, contentType)
+	}
+	for _, allowed := range AttachmentTypes {
+		if contentType == allowed || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*"))) {
+			return contentType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrAttachmentType, contentType)
+}
+
+// attachmentFilename keeps the base name of an uploaded file, without
+// control characters.
+func attachmentFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+// removeTaskAttachments deletes the attachments of tasks purged from the
+// trash.
+func (ws *workspace) removeTaskAttachments(taskIDs []int) {
+	attachments, err := ws.attachmentRepo.GetAttachmentsByTasks(taskIDs)
+	if err != nil {
+		slog.ErrorContext(ws.ctx, "attachments of purged tasks left behind", "error", err)
+		return
+	}
+	for _, attachment := range attachments {
+		if err := ws.attachmentRepo.DeleteAttachment(attachment.ID); err != nil {
+			slog.ErrorContext(ws.ctx, "attachment of purged task left behind", "attachment_id", attachment.ID, "error", err)
+			continue
+		}
+		ws.deleteBlob(attachment.StorageKey)
+	}
+}
+
+func (ws *workspace) deleteBlob(key string) {
+	if blobStore == nil {
+		return
+	}
+	if err := blobStore.Delete(key); err != nil {
+		slog.ErrorContext(ws.ctx, "blob left behind", "key", key, "error", err)
+	}
+}
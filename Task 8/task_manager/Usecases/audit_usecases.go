@@ -0,0 +1,105 @@
+package Usecases
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"task_manager/Domain"
+	"time"
+)
+
+type IAuditService interface {
+	In(workspaceID int) IAuditService
+	WithContext(ctx context.Context) IAuditService
+	Record(entry Domain.AuditEntry) error
+	GetEntries(filter Domain.AuditFilter) ([]Domain.AuditEntry, error)
+	Export(filter Domain.AuditFilter, w io.Writer) error
+}
+
+type AuditService struct {
+	*workspace
+}
+
+func NewAuditService(dbName string) IAuditService {
+	return &AuditService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the audit log of the given workspace.
+func (a *AuditService) In(workspaceID int) IAuditService {
+	return &AuditService{a.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (a *AuditService) WithContext(ctx context.Context) IAuditService {
+	return &AuditService{a.withContext(ctx)}
+}
+
+func (a *AuditService) Record(entry Domain.AuditEntry) error {
+	defer a.span("AuditService.Record")()
+	entry.ID = a.auditRepo.GetNextAuditID()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	return a.auditRepo.Append(entry)
+}
+
+func (a *AuditService) GetEntries(filter Domain.AuditFilter) ([]Domain.AuditEntry, error) {
+	defer a.span("AuditService.GetEntries")()
+	return a.auditRepo.Find(filter)
+}
+
+// Export writes the matching entries as JSON Lines, one entry per line.
+func (a *AuditService) Export(filter Domain.AuditFilter, w io.Writer) error {
+	defer a.span("AuditService.Export")()
+	entries, err := a.auditRepo.Find(filter)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff compares the JSON representation of two values and returns the fields
+// that differ. Either side may be nil, e.g. for a creation or a deletion.
+// Password fields are never included.
+func Diff(before, after interface{}) map[string]Domain.FieldChange {
+	from := toFieldMap(before)
+	to := toFieldMap(after)
+
+	changes := map[string]Domain.FieldChange{}
+	for field, value := range from {
+		if !reflect.DeepEqual(value, to[field]) {
+			changes[field] = Domain.FieldChange{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, ok := from[field]; !ok {
+			changes[field] = Domain.FieldChange{From: nil, To: value}
+		}
+	}
+	delete(changes, "password")
+
+	return changes
+}
+
+func toFieldMap(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
@@ -0,0 +1,269 @@
+package Usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+var (
+	ErrInvalidBulk = errors.New("invalid bulk request")
+	ErrBulkFailed  = errors.New("bulk request not applied")
+	// ErrAtomicUnsupported is returned by atomic requests when MongoDB does
+	// not run as a replica set, which they need.
+	ErrAtomicUnsupported = errors.New("atomic requests are not supported by this database")
+)
+
+// BulkMaxTasks is how many tasks a bulk request may change, counting every
+// task matched by a filter.
+var BulkMaxTasks = 1000
+
+// bulkFixedFields are the task fields an update cannot set.
+var bulkFixedFields = map[string]bool{
+	"id":           true,
+	"blocked_by":   true,
+	"rank":         true,
+	"series_id":    true,
+	"occurrence":   true,
+	"overdue":      true,
+	"created_at":   true,
+	"started_at":   true,
+	"completed_at": true,
+	"deleted_at":   true,
+	"external_id":  true,
+}
+
+// BulkTasks applies a batch of operations, checking every one of them before
+// writing the changes in one go, or only checks them in a dry run.
+// Operations are checked against the tasks as they were before the request,
+// and a task may only be changed by one of them. It returns the result for
+// every task; in an atomic request that fails nothing is written and the
+// error is ErrBulkFailed, or ErrAtomicUnsupported without transactions.
+func (t *TaskService) BulkTasks(request Domain.BulkRequest, actor string) ([]Domain.BulkResult, error) {
+	defer t.span("TaskService.BulkTasks")()
+	if len(request.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBulk)
+	}
+	if len(request.Operations) > BulkMaxTasks {
+		return nil, fmt.Errorf("%w: more than %d operations", ErrInvalidBulk, BulkMaxTasks)
+	}
+
+	batch := &bulkBatch{workspace: t.workspace, nextID: t.getNextTaskID(), changed: map[int]int{}}
+	for index, operation := range request.Operations {
+		batch.prepare(index, operation)
+	}
+	if len(batch.results) > BulkMaxTasks {
+		return nil, fmt.Errorf("%w: more than %d tasks", ErrInvalidBulk, BulkMaxTasks)
+	}
+
+	if request.Atomic && len(batch.writes) < len(batch.results) {
+		batch.skipPending("another operation failed")
+		return batch.results, ErrBulkFailed
+	}
+	if request.DryRun {
+		for _, index := range batch.pending {
+			batch.results[index].Status = Domain.BulkValid
+		}
+		return batch.results, nil
+	}
+
+	errs, err := t.taskRepo.BulkWrite(batch.writes, request.Atomic)
+	if err != nil {
+		if request.Atomic {
+			batch.skipPending(err.Error())
+			if errors.Is(err, Repositories.ErrTransactionsUnsupported) {
+				return batch.results, fmt.Errorf("%w: %v", ErrAtomicUnsupported, err)
+			}
+			return batch.results, fmt.Errorf("%w: %v", ErrBulkFailed, err)
+		}
+		return nil, err
+	}
+
+	deleted := false
+	for i, index := range batch.pending {
+		result := &batch.results[index]
+		if errs[i] != nil {
+			result.Status = Domain.BulkFailed
+			result.Error = errs[i].Error()
+			result.Task = nil
+			continue
+		}
+		result.Status = Domain.BulkSucceeded
+		switch result.Op {
+		case Domain.BulkCreate:
+			t.saveRevision(Domain.RevisionCreated, actor, nil, *result.Task)
+		case Domain.BulkUpdate:
+			if err := t.finishUpdate(*result.Before, *result.Task, actor); err != nil {
+				slog.ErrorContext(t.ctx, "bulk update not finished", "task_id", result.ID, "error", err)
+			}
+		case Domain.BulkDelete:
+			t.saveRevision(Domain.RevisionDeleted, actor, *result.Before, *result.Task)
+			deleted = true
+		}
+	}
+	if deleted {
+		if err := t.PurgeTrash(); err != nil {
+			slog.ErrorContext(t.ctx, "trash not purged", "error", err)
+		}
+	}
+	return batch.results, nil
+}
+
+// bulkBatch collects the results and writes of a bulk request as its
+// operations are checked.
+type bulkBatch struct {
+	*workspace
+	results []Domain.BulkResult
+	writes  []Repositories.TaskWrite
+	// pending holds the index of the result of each write.
+	pending []int
+	// changed maps the tasks already changed to the operation changing them.
+	changed map[int]int
+	nextID  int
+}
+
+func (b *bulkBatch) prepare(index int, operation Domain.BulkOperation) {
+	switch operation.Op {
+	case Domain.BulkCreate:
+		if operation.Task == nil {
+			b.fail(index, operation.Op, 0, errors.New("task is required"))
+			return
+		}
+		task := *operation.Task
+		task.ID = b.nextID
+		task, err := b.prepareTask(task)
+		if err != nil {
+			b.fail(index, operation.Op, 0, err)
+			return
+		}
+		b.nextID++
+		b.add(index, operation.Op, nil, task)
+
+	case Domain.BulkUpdate:
+		if operation.Set == nil {
+			b.fail(index, operation.Op, operation.ID, errors.New("set is required"))
+			return
+		}
+		if operation.Filter != nil {
+			if operation.ID != 0 || operation.Filter.IsEmpty() {
+				b.fail(index, operation.Op, operation.ID, errors.New("give either an id or a non-empty filter"))
+				return
+			}
+			for _, task := range b.taskRepo.FindTasks(*operation.Filter) {
+				b.update(index, task, operation.Set)
+			}
+			return
+		}
+		existing, err := b.existing(operation)
+		if err != nil {
+			b.fail(index, operation.Op, operation.ID, err)
+			return
+		}
+		b.update(index, existing, operation.Set)
+
+	case Domain.BulkDelete:
+		existing, err := b.existing(operation)
+		if err != nil {
+			b.fail(index, operation.Op, operation.ID, err)
+			return
+		}
+		deleted := existing
+		now := time.Now().UTC()
+		deleted.DeletedAt = &now
+		b.add(index, operation.Op, &existing, deleted)
+
+	default:
+		b.fail(index, operation.Op, operation.ID, fmt.Errorf("unknown operation %q", operation.Op))
+	}
+}
+
+// existing loads the task an operation changes by ID, unless another
+// operation already changes it.
+func (b *bulkBatch) existing(operation Domain.BulkOperation) (Domain.Task, error) {
+	if operation.ID == 0 {
+		return Domain.Task{}, errors.New("id is required")
+	}
+	if other, ok := b.changed[operation.ID]; ok {
+		return Domain.Task{}, fmt.Errorf("task already changed by operation %d", other)
+	}
+	return b.taskRepo.GetTaskByID(operation.ID)
+}
+
+func (b *bulkBatch) update(index int, existing Domain.Task, set map[string]interface{}) {
+	if other, ok := b.changed[existing.ID]; ok {
+		b.fail(index, Domain.BulkUpdate, existing.ID, fmt.Errorf("task already changed by operation %d", other))
+		return
+	}
+
+	updatedTask, err := applySet(existing, set)
+	if existing.SeriesID != 0 {
+		updatedTask.Recurrence = existing.Recurrence
+	}
+	if err == nil {
+		updatedTask, err = b.prepareUpdate(existing, updatedTask)
+	}
+	if err != nil {
+		b.fail(index, Domain.BulkUpdate, existing.ID, err)
+		return
+	}
+	b.add(index, Domain.BulkUpdate, &existing, updatedTask)
+}
+
+func (b *bulkBatch) add(index int, op string, before *Domain.Task, task Domain.Task) {
+	b.changed[task.ID] = index
+	b.pending = append(b.pending, len(b.results))
+	b.writes = append(b.writes, Repositories.TaskWrite{Op: op, Task: task})
+	b.results = append(b.results, Domain.BulkResult{Index: index, Op: op, ID: task.ID, Task: &task, Before: before})
+}
+
+func (b *bulkBatch) fail(index int, op string, id int, err error) {
+	b.results = append(b.results, Domain.BulkResult{
+		Index:  index,
+		Op:     op,
+		ID:     id,
+		Status: Domain.BulkFailed,
+		Error:  err.Error(),
+	})
+}
+
+// skipPending marks the writes that were not applied.
+func (b *bulkBatch) skipPending(reason string) {
+	for _, index := range b.pending {
+		b.results[index].Status = Domain.BulkSkipped
+		b.results[index].Error = reason
+		b.results[index].Task = nil
+	}
+}
+
+// applySet sets fields of a task by their JSON names.
+func applySet(task Domain.Task, set map[string]interface{}) (Domain.Task, error) {
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return task, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return task, err
+	}
+
+	for name, value := range set {
+		if _, ok := fields[name]; !ok || bulkFixedFields[name] {
+			return task, fmt.Errorf("%w: field %q cannot be set", ErrInvalidTask, name)
+		}
+		fields[name] = value
+	}
+
+	encoded, err = json.Marshal(fields)
+	if err != nil {
+		return task, err
+	}
+	var updatedTask Domain.Task
+	if err := json.Unmarshal(encoded, &updatedTask); err != nil {
+		return task, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	return updatedTask, nil
+}
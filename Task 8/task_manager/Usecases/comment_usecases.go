@@ -0,0 +1,230 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotCommentAuthor = errors.New("only the author can change this comment")
+	ErrEmptyComment     = errors.New("comment body cannot be empty")
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.-]+)`)
+
+type ICommentService interface {
+	In(workspaceID int) ICommentService
+	WithContext(ctx context.Context) ICommentService
+	CreateComment(taskID int, comment Domain.Comment, author string) (Domain.Comment, error)
+	GetComments(taskID, skip, limit int) ([]Domain.Comment, int, error)
+	GetLatestComments(taskIDs []int, limit int) (map[int][]Domain.Comment, error)
+	UpdateComment(taskID, commentID int, body, author string) (Domain.Comment, error)
+	DeleteComment(taskID, commentID int, author string, isAdmin bool) error
+	GetActivity(taskID int) ([]Domain.Activity, error)
+}
+
+type CommentService struct {
+	*workspace
+}
+
+func NewCommentService(dbName string) ICommentService {
+	return &CommentService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the comments of the given workspace.
+func (s *CommentService) In(workspaceID int) ICommentService {
+	return &CommentService{s.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (s *CommentService) WithContext(ctx context.Context) ICommentService {
+	return &CommentService{s.withContext(ctx)}
+}
+
+func (s *CommentService) CreateComment(taskID int, comment Domain.Comment, author string) (Domain.Comment, error) {
+	defer s.span("CommentService.CreateComment")()
+	if _, err := s.taskRepo.GetTaskByID(taskID); err != nil {
+		return comment, err
+	}
+	if strings.TrimSpace(comment.Body) == "" {
+		return comment, ErrEmptyComment
+	}
+	if comment.ParentID != 0 {
+		parent, err := s.commentRepo.GetCommentByID(comment.ParentID)
+		if err != nil || parent.TaskID != taskID {
+			return comment, errors.New("parent comment not found")
+		}
+	}
+
+	comment.ID = s.commentRepo.GetNextCommentID()
+	comment.TaskID = taskID
+	comment.Author = author
+	comment.Mentions = s.resolveMentions(comment.Body)
+	comment.Deleted = false
+	comment.CreatedAt = time.Now().UTC()
+	comment.UpdatedAt = nil
+
+	if err := s.commentRepo.CreateComment(comment); err != nil {
+		return comment, err
+	}
+	s.indexComment(comment)
+	s.publishEvent(Domain.Event{
+		Type:   Domain.EventCommentCreated,
+		Actor:  author,
+		Data:   map[string]interface{}{"comment": comment},
+		TaskID: comment.TaskID,
+	})
+	return comment, nil
+}
+
+func (s *CommentService) GetComments(taskID, skip, limit int) ([]Domain.Comment, int, error) {
+	defer s.span("CommentService.GetComments")()
+	total, err := s.commentRepo.CountComments(taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	comments, err := s.commentRepo.GetComments(taskID, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// GetLatestComments returns the last limit comments of each of the given
+// tasks, in the order they were posted, with one call to the repository
+// however many tasks there are.
+func (s *CommentService) GetLatestComments(taskIDs []int, limit int) (map[int][]Domain.Comment, error) {
+	defer s.span("CommentService.GetLatestComments")()
+	return s.commentRepo.GetLatestComments(taskIDs, limit)
+}
+
+func (s *CommentService) UpdateComment(taskID, commentID int, body, author string) (Domain.Comment, error) {
+	defer s.span("CommentService.UpdateComment")()
+	comment, err := s.getTaskComment(taskID, commentID)
+	if err != nil {
+		return comment, err
+	}
+	if comment.Author != author {
+		return comment, ErrNotCommentAuthor
+	}
+	if strings.TrimSpace(body) == "" {
+		return comment, ErrEmptyComment
+	}
+
+	now := time.Now().UTC()
+	comment.Body = body
+	comment.Mentions = s.resolveMentions(body)
+	comment.UpdatedAt = &now
+	if err := s.commentRepo.UpdateComment(comment); err != nil {
+		return comment, err
+	}
+	s.indexComment(comment)
+	return comment, nil
+}
+
+// DeleteComment blanks out a comment rather than removing it, so that the
+// replies to it keep their place in the thread. Admins can delete any comment.
+func (s *CommentService) DeleteComment(taskID, commentID int, author string, isAdmin bool) error {
+	defer s.span("CommentService.DeleteComment")()
+	comment, err := s.getTaskComment(taskID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.Author != author && !isAdmin {
+		return ErrNotCommentAuthor
+	}
+
+	now := time.Now().UTC()
+	comment.Body = ""
+	comment.Mentions = []string{}
+	comment.Deleted = true
+	comment.UpdatedAt = &now
+	if err := s.commentRepo.UpdateComment(comment); err != nil {
+		return err
+	}
+	s.indexComment(comment)
+	return nil
+}
+
+// GetActivity interleaves the comments on a task with the revisions that
+// created it or changed its status, oldest first.
+func (s *CommentService) GetActivity(taskID int) ([]Domain.Activity, error) {
+	defer s.span("CommentService.GetActivity")()
+	comments, err := s.commentRepo.GetComments(taskID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.historyRepo.GetRevisions(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := []Domain.Activity{}
+	for i := range comments {
+		activity = append(activity, Domain.Activity{
+			Type:      Domain.ActivityComment,
+			Timestamp: comments[i].CreatedAt,
+			Actor:     comments[i].Author,
+			Comment:   &comments[i],
+		})
+	}
+	for i := range revisions {
+		if _, changed := revisions[i].Changes["status"]; !changed {
+			continue
+		}
+		activity = append(activity, Domain.Activity{
+			Type:      Domain.ActivityStatusChange,
+			Timestamp: revisions[i].Timestamp,
+			Actor:     revisions[i].Actor,
+			Revision:  &revisions[i],
+		})
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].Timestamp.Before(activity[j].Timestamp)
+	})
+	return activity, nil
+}
+
+// ParseMentions returns the distinct usernames mentioned with @ in a comment
+// body, in the order they first appear.
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		mentions = append(mentions, username)
+	}
+	return mentions
+}
+
+// resolveMentions keeps the mentions that name a member of the workspace.
+func (ws *workspace) resolveMentions(body string) []string {
+	mentions := []string{}
+	for _, username := range ParseMentions(body) {
+		if _, err := ws.member(username); err == nil {
+			mentions = append(mentions, username)
+		}
+	}
+	return mentions
+}
+
+func (ws *workspace) getTaskComment(taskID, commentID int) (Domain.Comment, error) {
+	comment, err := ws.commentRepo.GetCommentByID(commentID)
+	if err != nil || comment.TaskID != taskID || comment.Deleted {
+		return comment, ErrCommentNotFound
+	}
+	return comment, nil
+}
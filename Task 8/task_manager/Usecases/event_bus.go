@@ -0,0 +1,163 @@
+package Usecases
+
+import (
+	"sync"
+	"task_manager/Domain"
+	"time"
+)
+
+// EventHistory is how many of the latest events the bus keeps for the
+// streams that resume after a disconnection.
+const EventHistory = 1000
+
+// subscriberBuffer is how many events a stream may fall behind before it is
+// dropped. A dropped client reconnects and resumes from its last event.
+const subscriberBuffer = 64
+
+// EventBus hands the events published by the services to the handlers
+// registered on it and to the streams subscribed to it. It numbers the
+// events and keeps the latest ones so that streams can resume.
+type EventBus struct {
+	mu          sync.Mutex
+	lastID      int
+	history     []Domain.Event
+	capacity    int
+	handlers    []func(Domain.Event)
+	subscribers map[chan Domain.Event]struct{}
+	closed      bool
+}
+
+// Events is the bus the services publish to.
+var Events = NewEventBus(EventHistory)
+
+func NewEventBus(capacity int) *EventBus {
+	return &EventBus{capacity: capacity, subscribers: map[chan Domain.Event]struct{}{}}
+}
+
+// Handle registers a handler called with every event, in the goroutine that
+// publishes it.
+func (b *EventBus) Handle(handler func(Domain.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish numbers an event, passes it to the subscribers and then to the
+// handlers, and returns it as published.
+func (b *EventBus) Publish(event Domain.Event) Domain.Event {
+	b.mu.Lock()
+	b.lastID++
+	event.ID = b.lastID
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.capacity {
+		b.history = append([]Domain.Event(nil), b.history[len(b.history)-b.capacity:]...)
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	handlers := b.handlers
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return event
+}
+
+// Subscribe returns the kept events published after lastID, and a channel
+// receiving the events published from now on. The channel is closed when the
+// subscriber falls too far behind or cancels, or when the bus is closed. A
+// lastID of 0, or one the bus has not reached, as after a restart, resumes
+// nothing.
+func (b *EventBus) Subscribe(lastID int) ([]Domain.Event, <-chan Domain.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed := []Domain.Event{}
+	if lastID > 0 && lastID <= b.lastID {
+		for _, event := range b.history {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan Domain.Event, subscriberBuffer)
+	if b.closed {
+		close(ch)
+		return missed, ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return missed, ch, cancel
+}
+
+// Close closes the channels of the subscribers, which ends their streams so
+// that the server can shut down. The channels of later subscriptions come
+// closed; events are still numbered and handled.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publishEvent publishes an event of the workspace to the bus, and queues it
+// for the workspace's webhooks.
+func (ws *workspace) publishEvent(event Domain.Event) {
+	event.WorkspaceID = ws.id
+	ws.queueDeliveries(Events.Publish(event))
+}
+
+// publishTaskEvent publishes the event matching a revision of a task, and
+// task.status_changed when its status changed.
+func (ws *workspace) publishTaskEvent(action, actor string, before interface{}, after Domain.Task, changes map[string]Domain.FieldChange) {
+	ws.publishEvent(Domain.Event{
+		Type:      "task." + action,
+		Actor:     actor,
+		Data:      map[string]interface{}{"task": after, "changes": changes},
+		TaskID:    after.ID,
+		ProjectID: after.ProjectID,
+	})
+
+	if previous, ok := before.(Domain.Task); ok && previous.Status != after.Status {
+		ws.publishEvent(Domain.Event{
+			Type:      Domain.EventTaskStatusChanged,
+			Actor:     actor,
+			Data:      map[string]interface{}{"task": after, "from": previous.Status, "to": after.Status},
+			TaskID:    after.ID,
+			ProjectID: after.ProjectID,
+		})
+	}
+}
+
+// userEventData describes a user in events, without the password.
+func userEventData(user Domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"user": map[string]interface{}{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+			"role":     user.Role,
+		},
+	}
+}
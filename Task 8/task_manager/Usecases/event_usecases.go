@@ -0,0 +1,53 @@
+package Usecases
+
+import (
+	"context"
+	"task_manager/Domain"
+)
+
+type IEventService interface {
+	In(workspaceID int) IEventService
+	WithContext(ctx context.Context) IEventService
+	Subscribe(lastEventID int) ([]Domain.Event, <-chan Domain.Event, func())
+	CanSee(event Domain.Event, username string, isAdmin bool) bool
+}
+
+type EventService struct {
+	*workspace
+}
+
+func NewEventService(dbName string) IEventService {
+	return &EventService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the events of the given workspace.
+func (e *EventService) In(workspaceID int) IEventService {
+	return &EventService{e.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (e *EventService) WithContext(ctx context.Context) IEventService {
+	return &EventService{e.withContext(ctx)}
+}
+
+// Subscribe subscribes to the events published after lastEventID, as
+// EventBus.Subscribe does.
+func (e *EventService) Subscribe(lastEventID int) ([]Domain.Event, <-chan Domain.Event, func()) {
+	defer e.span("EventService.Subscribe")()
+	return Events.Subscribe(lastEventID)
+}
+
+// CanSee reports whether a user may receive an event. Streams carry the task
+// events of the workspace only: those about tasks outside projects go to
+// every member, the others to the members of the project and to admins.
+func (e *EventService) CanSee(event Domain.Event, username string, isAdmin bool) bool {
+	if !event.IsTaskEvent() || event.WorkspaceID != e.id {
+		return false
+	}
+	if isAdmin || event.ProjectID == 0 {
+		return true
+	}
+	_, err := e.getProjectAs(event.ProjectID, username, false, Domain.ProjectRoleViewer)
+	return err == nil
+}
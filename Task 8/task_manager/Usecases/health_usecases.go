@@ -0,0 +1,19 @@
+package Usecases
+
+import (
+	"context"
+	"task_manager/Repositories"
+	"time"
+)
+
+// ReadinessTimeout bounds how long a readiness check waits for the
+// database.
+var ReadinessTimeout = 2 * time.Second
+
+// CheckReadiness tells whether the service can serve requests, that is
+// whether its database answers within ReadinessTimeout.
+func CheckReadiness(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ReadinessTimeout)
+	defer cancel()
+	return Repositories.Ping(ctx)
+}
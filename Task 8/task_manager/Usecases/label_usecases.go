@@ -0,0 +1,166 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"task_manager/Domain"
+)
+
+var (
+	ErrInvalidLabel  = errors.New("invalid label")
+	ErrLabelNotFound = errors.New("label not found")
+	ErrInvalidTask   = errors.New("invalid task")
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type ILabelService interface {
+	In(workspaceID int) ILabelService
+	WithContext(ctx context.Context) ILabelService
+	GetLabels() ([]Domain.Label, error)
+	CreateLabel(label Domain.Label) (Domain.Label, error)
+	UpdateLabel(id int, label Domain.Label) (Domain.Label, error)
+	DeleteLabel(id int) error
+}
+
+type LabelService struct {
+	*workspace
+}
+
+func NewLabelService(dbName string) ILabelService {
+	return &LabelService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the labels of the given workspace.
+func (l *LabelService) In(workspaceID int) ILabelService {
+	return &LabelService{l.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (l *LabelService) WithContext(ctx context.Context) ILabelService {
+	return &LabelService{l.withContext(ctx)}
+}
+
+func (l *LabelService) GetLabels() ([]Domain.Label, error) {
+	defer l.span("LabelService.GetLabels")()
+	return l.labelRepo.GetLabels()
+}
+
+func (l *LabelService) CreateLabel(label Domain.Label) (Domain.Label, error) {
+	defer l.span("LabelService.CreateLabel")()
+	if err := validateLabel(label); err != nil {
+		return label, err
+	}
+	if _, err := l.labelRepo.GetLabelByName(label.Name); err == nil {
+		return label, fmt.Errorf("%w: %q already exists", ErrInvalidLabel, label.Name)
+	}
+
+	label.ID = l.labelRepo.GetNextLabelID()
+	if err := l.labelRepo.CreateLabel(label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+// UpdateLabel changes the name and color of a label. Renaming a label renames
+// it on every task that has it.
+func (l *LabelService) UpdateLabel(id int, label Domain.Label) (Domain.Label, error) {
+	defer l.span("LabelService.UpdateLabel")()
+	existing, err := l.labelRepo.GetLabelByID(id)
+	if err != nil {
+		return label, ErrLabelNotFound
+	}
+	if err := validateLabel(label); err != nil {
+		return label, err
+	}
+	if other, err := l.labelRepo.GetLabelByName(label.Name); err == nil && other.ID != id {
+		return label, fmt.Errorf("%w: %q already exists", ErrInvalidLabel, label.Name)
+	}
+
+	label.ID = id
+	if err := l.labelRepo.UpdateLabel(label); err != nil {
+		return label, err
+	}
+	if existing.Name != label.Name {
+		if err := l.taskRepo.RenameLabel(existing.Name, label.Name); err != nil {
+			return label, err
+		}
+		l.reindexTasks(l.taskRepo.FindTasks(Domain.TaskFilter{Labels: []string{label.Name}}))
+	}
+	return label, nil
+}
+
+// DeleteLabel removes a label and takes it off every task that has it.
+func (l *LabelService) DeleteLabel(id int) error {
+	defer l.span("LabelService.DeleteLabel")()
+	existing, err := l.labelRepo.GetLabelByID(id)
+	if err != nil {
+		return ErrLabelNotFound
+	}
+
+	labelled := l.taskRepo.FindTasks(Domain.TaskFilter{Labels: []string{existing.Name}})
+	if err := l.labelRepo.DeleteLabel(id); err != nil {
+		return err
+	}
+	if err := l.taskRepo.RemoveLabel(existing.Name); err != nil {
+		return err
+	}
+	l.reindexTasks(labelled)
+	return nil
+}
+
+// reindexTasks refreshes the search index entries of tasks changed in bulk.
+func (ws *workspace) reindexTasks(tasks []Domain.Task) {
+	for _, task := range tasks {
+		if updated, err := ws.taskRepo.GetTaskByID(task.ID); err == nil {
+			ws.indexTask(updated)
+		}
+	}
+}
+
+func validateLabel(label Domain.Label) error {
+	if strings.TrimSpace(label.Name) == "" || strings.Contains(label.Name, ",") {
+		return fmt.Errorf("%w: name cannot be empty or contain commas", ErrInvalidLabel)
+	}
+	if !colorPattern.MatchString(label.Color) {
+		return fmt.Errorf("%w: color must look like #1f883d", ErrInvalidLabel)
+	}
+	return nil
+}
+
+// validateClassification checks the labels, priority and assignee of a task:
+// every label must exist, the priority must be one of Domain.Priorities or
+// empty, the assignee must be a registered user, and estimates cannot be
+// negative.
+func (ws *workspace) validateClassification(task Domain.Task) error {
+	for _, name := range task.Labels {
+		if _, err := ws.labelRepo.GetLabelByName(name); err != nil {
+			return fmt.Errorf("%w: unknown label %q", ErrInvalidTask, name)
+		}
+	}
+	if task.Priority != "" && !isPriority(task.Priority) {
+		return fmt.Errorf("%w: priority must be one of %s", ErrInvalidTask, strings.Join(Domain.Priorities, ", "))
+	}
+	if task.Assignee != "" {
+		if _, err := ws.member(task.Assignee); err != nil {
+			return fmt.Errorf("%w: unknown assignee %q", ErrInvalidTask, task.Assignee)
+		}
+	}
+	if task.StoryPoints < 0 || task.Estimate < 0 {
+		return fmt.Errorf("%w: estimates cannot be negative", ErrInvalidTask)
+	}
+	return nil
+}
+
+func isPriority(priority string) bool {
+	for _, p := range Domain.Priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,407 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var (
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrProjectForbidden = errors.New("you do not have the required role in this project")
+	ErrProjectNotEmpty  = errors.New("project still has tasks")
+	ErrInvalidProject   = errors.New("invalid project")
+)
+
+// rankStep is the gap left between neighbouring tasks on a board, so that a
+// task can be moved between two others without renumbering the column.
+const rankStep = 1024.0
+
+var roleLevels = map[string]int{
+	Domain.ProjectRoleViewer:     1,
+	Domain.ProjectRoleMember:     2,
+	Domain.ProjectRoleMaintainer: 3,
+	Domain.ProjectRoleOwner:      4,
+}
+
+type IProjectService interface {
+	In(workspaceID int) IProjectService
+	WithContext(ctx context.Context) IProjectService
+	CreateProject(project Domain.Project, creator string) (Domain.Project, error)
+	GetProjects(username string, isAdmin bool) ([]Domain.Project, error)
+	GetProject(id int, username string, isAdmin bool) (Domain.Project, error)
+	UpdateProject(id int, project Domain.Project, username string, isAdmin bool) (Domain.Project, error)
+	DeleteProject(id int, username string, isAdmin bool) error
+	SetMember(id int, member Domain.ProjectMember, username string, isAdmin bool) (Domain.Project, error)
+	RemoveMember(id int, member string, username string, isAdmin bool) (Domain.Project, error)
+	CreateProjectTask(id int, task Domain.Task, username string, isAdmin bool) (Domain.Task, error)
+	GetBoard(id int, username string, isAdmin bool) ([]Domain.BoardColumnTasks, error)
+	MoveTask(id, taskID int, status string, position int, username string, isAdmin bool) (Domain.Task, error)
+}
+
+type ProjectService struct {
+	*workspace
+}
+
+func NewProjectService(dbName string) IProjectService {
+	return &ProjectService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the projects of the given workspace.
+func (p *ProjectService) In(workspaceID int) IProjectService {
+	return &ProjectService{p.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (p *ProjectService) WithContext(ctx context.Context) IProjectService {
+	return &ProjectService{p.withContext(ctx)}
+}
+
+// CreateProject creates a project owned by its creator. A project without
+// columns gets the default board.
+func (p *ProjectService) CreateProject(project Domain.Project, creator string) (Domain.Project, error) {
+	defer p.span("ProjectService.CreateProject")()
+	if len(project.Columns) == 0 {
+		project.Columns = Domain.DefaultColumns
+	}
+	if err := validateProject(project); err != nil {
+		return project, err
+	}
+
+	project.ID = p.projectRepo.GetNextProjectID()
+	project.Members = []Domain.ProjectMember{{Username: creator, Role: Domain.ProjectRoleOwner}}
+	project.CreatedAt = time.Now().UTC()
+
+	if err := p.projectRepo.CreateProject(project); err != nil {
+		return project, err
+	}
+	return project, nil
+}
+
+// GetProjects returns every project to admins and the projects the user is a
+// member of to everyone else.
+func (p *ProjectService) GetProjects(username string, isAdmin bool) ([]Domain.Project, error) {
+	defer p.span("ProjectService.GetProjects")()
+	if isAdmin {
+		return p.projectRepo.GetProjects()
+	}
+	return p.projectRepo.GetProjectsByMember(username)
+}
+
+func (p *ProjectService) GetProject(id int, username string, isAdmin bool) (Domain.Project, error) {
+	defer p.span("ProjectService.GetProject")()
+	return p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleViewer)
+}
+
+// UpdateProject changes the name, description and board columns of a project.
+func (p *ProjectService) UpdateProject(id int, project Domain.Project, username string, isAdmin bool) (Domain.Project, error) {
+	defer p.span("ProjectService.UpdateProject")()
+	existing, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleMaintainer)
+	if err != nil {
+		return existing, err
+	}
+
+	existing.Name = project.Name
+	existing.Description = project.Description
+	if len(project.Columns) > 0 {
+		existing.Columns = project.Columns
+	}
+	if err := validateProject(existing); err != nil {
+		return existing, err
+	}
+
+	if err := p.projectRepo.UpdateProject(existing); err != nil {
+		return existing, err
+	}
+	return existing, nil
+}
+
+func (p *ProjectService) DeleteProject(id int, username string, isAdmin bool) error {
+	defer p.span("ProjectService.DeleteProject")()
+	if _, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleOwner); err != nil {
+		return err
+	}
+	if len(p.taskRepo.GetTasksByProject(id)) > 0 {
+		return ErrProjectNotEmpty
+	}
+	return p.projectRepo.DeleteProject(id)
+}
+
+// SetMember adds a user to a project or changes their role. Only owners can
+// hand out or take away the owner role.
+func (p *ProjectService) SetMember(id int, member Domain.ProjectMember, username string, isAdmin bool) (Domain.Project, error) {
+	defer p.span("ProjectService.SetMember")()
+	project, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleMaintainer)
+	if err != nil {
+		return project, err
+	}
+	if _, ok := roleLevels[member.Role]; !ok {
+		return project, fmt.Errorf("%w: unknown role %q", ErrInvalidProject, member.Role)
+	}
+	if _, err := p.member(member.Username); err != nil {
+		return project, fmt.Errorf("user %q is not a member of the workspace", member.Username)
+	}
+
+	current := project.RoleOf(member.Username)
+	touchesOwner := member.Role == Domain.ProjectRoleOwner || current == Domain.ProjectRoleOwner
+	if touchesOwner && !isAdmin && project.RoleOf(username) != Domain.ProjectRoleOwner {
+		return project, ErrProjectForbidden
+	}
+
+	members := []Domain.ProjectMember{}
+	for _, existing := range project.Members {
+		if existing.Username != member.Username {
+			members = append(members, existing)
+		}
+	}
+	project.Members = append(members, member)
+	if err := validateProject(project); err != nil {
+		return project, err
+	}
+
+	if err := p.projectRepo.UpdateProject(project); err != nil {
+		return project, err
+	}
+	return project, nil
+}
+
+func (p *ProjectService) RemoveMember(id int, member string, username string, isAdmin bool) (Domain.Project, error) {
+	defer p.span("ProjectService.RemoveMember")()
+	project, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleMaintainer)
+	if err != nil {
+		return project, err
+	}
+
+	current := project.RoleOf(member)
+	if current == "" {
+		return project, fmt.Errorf("user %q is not a member of this project", member)
+	}
+	if current == Domain.ProjectRoleOwner && !isAdmin && project.RoleOf(username) != Domain.ProjectRoleOwner {
+		return project, ErrProjectForbidden
+	}
+
+	members := []Domain.ProjectMember{}
+	for _, existing := range project.Members {
+		if existing.Username != member {
+			members = append(members, existing)
+		}
+	}
+	project.Members = members
+	if err := validateProject(project); err != nil {
+		return project, err
+	}
+
+	if err := p.projectRepo.UpdateProject(project); err != nil {
+		return project, err
+	}
+	return project, nil
+}
+
+// CreateProjectTask creates a task in a project, at the bottom of the column
+// matching its status.
+func (p *ProjectService) CreateProjectTask(id int, task Domain.Task, username string, isAdmin bool) (Domain.Task, error) {
+	defer p.span("ProjectService.CreateProjectTask")()
+	project, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleMember)
+	if err != nil {
+		return task, err
+	}
+	if task.Status == "" {
+		task.Status = project.Columns[0].Status
+	}
+
+	ranks := columnRanks(p.taskRepo.GetTasksByProject(id), task.Status, 0)
+	task.ProjectID = id
+	task.Rank, _ = PositionRank(ranks, len(ranks))
+
+	return (&TaskService{p.workspace}).CreateTask(task, username)
+}
+
+// GetBoard returns the columns of a project's board with their tasks in
+// order. Tasks whose status matches no column are left out.
+func (p *ProjectService) GetBoard(id int, username string, isAdmin bool) ([]Domain.BoardColumnTasks, error) {
+	defer p.span("ProjectService.GetBoard")()
+	project, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := p.taskRepo.GetTasksByProject(id)
+	board := []Domain.BoardColumnTasks{}
+	for _, column := range project.Columns {
+		columnTasks := []Domain.Task{}
+		for _, task := range tasks {
+			if task.Status == column.Status {
+				columnTasks = append(columnTasks, task)
+			}
+		}
+		board = append(board, Domain.BoardColumnTasks{BoardColumn: column, Tasks: columnTasks})
+	}
+	return board, nil
+}
+
+// MoveTask moves a task to the given position of the column with the given
+// status, as when it is dragged and dropped on the board.
+func (p *ProjectService) MoveTask(id, taskID int, status string, position int, username string, isAdmin bool) (Domain.Task, error) {
+	defer p.span("ProjectService.MoveTask")()
+	project, err := p.getProjectAs(id, username, isAdmin, Domain.ProjectRoleMember)
+	if err != nil {
+		return Domain.Task{}, err
+	}
+	if !hasColumn(project, status) {
+		return Domain.Task{}, fmt.Errorf("%w: no column with status %q", ErrInvalidProject, status)
+	}
+
+	existing, err := p.taskRepo.GetTaskByID(taskID)
+	if err != nil || existing.ProjectID != id {
+		return existing, errors.New("task not found")
+	}
+
+	moved := existing
+	moved.Status = status
+	if err := p.validateStructure(moved); err != nil {
+		return existing, err
+	}
+
+	tasks := p.taskRepo.GetTasksByProject(id)
+	rank, ok := PositionRank(columnRanks(tasks, status, taskID), position)
+	if !ok {
+		if err := p.renumberColumn(tasks, status, taskID); err != nil {
+			return existing, err
+		}
+		rank, _ = PositionRank(columnRanks(p.taskRepo.GetTasksByProject(id), status, taskID), position)
+	}
+	moved.Rank = rank
+
+	// Moving a task to another column changes its status, and with it the
+	// times it was started and completed.
+	if moved.Status == existing.Status {
+		err = p.taskRepo.SetTaskPosition(taskID, status, rank)
+	} else {
+		stampTask(&existing, &moved, time.Now().UTC())
+		err = p.taskRepo.UpdateTask(taskID, moved)
+	}
+	if err != nil {
+		return existing, err
+	}
+	p.saveRevision(Domain.RevisionUpdated, username, existing, moved)
+
+	if !existing.IsCompleted() && moved.IsCompleted() {
+		if err := p.completeOccurrence(moved, username); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// PositionRank returns the rank placing an item at the given position among
+// items with the given ranks, in ascending order. It returns false when the
+// neighbouring ranks are too close to fit another one in between, in which
+// case the items must be renumbered first.
+func PositionRank(ranks []float64, position int) (float64, bool) {
+	if position < 0 {
+		position = 0
+	}
+	if position > len(ranks) {
+		position = len(ranks)
+	}
+
+	switch {
+	case len(ranks) == 0:
+		return rankStep, true
+	case position == 0:
+		return ranks[0] - rankStep, true
+	case position == len(ranks):
+		return ranks[len(ranks)-1] + rankStep, true
+	}
+
+	before, after := ranks[position-1], ranks[position]
+	rank := before + (after-before)/2
+	return rank, rank > before && rank < after
+}
+
+// getProjectAs loads a project and checks that the user has at least the
+// given role in it. Admins have every role in every project.
+func (ws *workspace) getProjectAs(id int, username string, isAdmin bool, role string) (Domain.Project, error) {
+	project, err := ws.projectRepo.GetProjectByID(id)
+	if err != nil {
+		return project, ErrProjectNotFound
+	}
+	if isAdmin {
+		return project, nil
+	}
+
+	current := project.RoleOf(username)
+	if current == "" {
+		return project, ErrProjectNotFound
+	}
+	if roleLevels[current] < roleLevels[role] {
+		return project, ErrProjectForbidden
+	}
+	return project, nil
+}
+
+func validateProject(project Domain.Project) error {
+	if strings.TrimSpace(project.Name) == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidProject)
+	}
+
+	statuses := map[string]bool{}
+	for _, column := range project.Columns {
+		if column.Status == "" || statuses[column.Status] {
+			return fmt.Errorf("%w: each column needs a distinct status", ErrInvalidProject)
+		}
+		statuses[column.Status] = true
+	}
+
+	if project.Members != nil {
+		owners := 0
+		for _, member := range project.Members {
+			if member.Role == Domain.ProjectRoleOwner {
+				owners++
+			}
+		}
+		if owners == 0 {
+			return fmt.Errorf("%w: a project needs at least one owner", ErrInvalidProject)
+		}
+	}
+	return nil
+}
+
+func hasColumn(project Domain.Project, status string) bool {
+	for _, column := range project.Columns {
+		if column.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// columnRanks returns the ranks of the tasks with the given status, in board
+// order, leaving out the task being moved.
+func columnRanks(tasks []Domain.Task, status string, skipID int) []float64 {
+	ranks := []float64{}
+	for _, task := range tasks {
+		if task.Status == status && task.ID != skipID {
+			ranks = append(ranks, task.Rank)
+		}
+	}
+	return ranks
+}
+
+func (ws *workspace) renumberColumn(tasks []Domain.Task, status string, skipID int) error {
+	rank := rankStep
+	for _, task := range tasks {
+		if task.Status != status || task.ID == skipID {
+			continue
+		}
+		if err := ws.taskRepo.SetTaskPosition(task.ID, status, rank); err != nil {
+			return err
+		}
+		rank += rankStep
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// RecurrenceActor is recorded as the author of the occurrences generated by
+// the scheduler.
+const RecurrenceActor = "scheduler"
+
+// RecurrenceInterval is how often the scheduler looks for series whose next
+// occurrence is due.
+var RecurrenceInterval = time.Hour
+
+var byDayPattern = regexp.MustCompile(`^([+-]?\d{1,2})?(SU|MO|TU|WE|TH|FR|SA)$`)
+
+// GetOccurrences returns the occurrences of the series a task belongs to, in
+// order. A task that does not recur is its only occurrence.
+func (t *TaskService) GetOccurrences(id int) ([]Domain.Task, error) {
+	defer t.span("TaskService.GetOccurrences")()
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.SeriesID == 0 {
+		return []Domain.Task{task}, nil
+	}
+
+	occurrences := []Domain.Task{}
+	for _, occurrence := range t.taskRepo.GetSeries(task.SeriesID) {
+		if occurrence.DeletedAt == nil {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences, nil
+}
+
+// UpdateFutureOccurrences updates a task and carries the change over to the
+// occurrences that follow it in its series. Due dates, statuses and board
+// positions belong to each occurrence and are only changed on the given
+// task. The recurrence rule can only be changed this way.
+func (t *TaskService) UpdateFutureOccurrences(id int, updatedTask Domain.Task, actor string) ([]Domain.Task, error) {
+	defer t.span("TaskService.UpdateFutureOccurrences")()
+	existing, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := t.updateTask(existing, updatedTask, actor)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []Domain.Task{updated}
+	if existing.SeriesID == 0 {
+		return tasks, nil
+	}
+
+	for _, occurrence := range t.taskRepo.GetSeries(existing.SeriesID) {
+		if occurrence.Occurrence <= existing.Occurrence || occurrence.DeletedAt != nil {
+			continue
+		}
+
+		changed := occurrence
+		changed.Title = updated.Title
+		changed.Description = updated.Description
+		changed.Assignee = updated.Assignee
+		changed.Labels = updated.Labels
+		changed.Priority = updated.Priority
+		changed.StoryPoints = updated.StoryPoints
+		changed.Estimate = updated.Estimate
+		changed.Checklist = resetChecklist(updated.Checklist)
+		changed.Recurrence = updated.Recurrence
+		if err := t.taskRepo.UpdateTask(changed.ID, changed); err != nil {
+			return tasks, err
+		}
+		t.saveRevision(Domain.RevisionUpdated, actor, occurrence, changed)
+		tasks = append(tasks, changed)
+	}
+	return tasks, nil
+}
+
+// GenerateOccurrences creates the next occurrence of every series whose last
+// occurrence is due by now, and returns them.
+func (t *TaskService) GenerateOccurrences(now time.Time) ([]Domain.Task, error) {
+	defer t.span("TaskService.GenerateOccurrences")()
+	today := truncateDay(now)
+	created := []Domain.Task{}
+	for _, latest := range t.taskRepo.GetLatestOccurrences() {
+		if latest.Recurrence == "" {
+			continue
+		}
+		due, err := ParseDueDate(latest.DueDate)
+		if err != nil || due.After(today) {
+			continue
+		}
+
+		next, ok, err := t.createNextOccurrence(latest, today, RecurrenceActor)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created = append(created, next)
+		}
+	}
+	return created, nil
+}
+
+// StartRecurrenceScheduler generates the due occurrences of recurring tasks
+// of every workspace in the background, right away and then every
+// RecurrenceInterval, until ctx is done. The returned channel is closed once
+// the scheduler has stopped.
+func StartRecurrenceScheduler(ctx context.Context, service ITaskService, workspaces IWorkspaceService) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(RecurrenceInterval)
+		defer ticker.Stop()
+		for {
+			forEachWorkspace(ctx, workspaces, func(id int) {
+				if _, err := service.In(id).GenerateOccurrences(time.Now()); err != nil {
+					slog.Error("occurrences not generated", "workspace_id", id, "error", err)
+				}
+			})
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// ParseRecurrence parses an RRULE value such as
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10", with or without the
+// "RRULE:" prefix. FREQ is one of DAILY, WEEKLY and MONTHLY; BYDAY days take
+// an ordinal, as in 2TU or -1FR, in monthly rules only; UNTIL is a date, as
+// in 20241231, and cannot be combined with COUNT.
+func ParseRecurrence(rule string) (Domain.Recurrence, error) {
+	var recurrence Domain.Recurrence
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return recurrence, fmt.Errorf("%w: %q is not a NAME=VALUE pair", ErrInvalidRecurrence, part)
+		}
+		if seen[name] {
+			return recurrence, fmt.Errorf("%w: %s is given twice", ErrInvalidRecurrence, name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			if value != Domain.FreqDaily && value != Domain.FreqWeekly && value != Domain.FreqMonthly {
+				return recurrence, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRecurrence)
+			}
+			recurrence.Freq = value
+		case "INTERVAL", "COUNT":
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				return recurrence, fmt.Errorf("%w: %s must be a positive number", ErrInvalidRecurrence, name)
+			}
+			if name == "INTERVAL" {
+				recurrence.Interval = number
+			} else {
+				recurrence.Count = number
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, err := parseWeekdayNum(day)
+				if err != nil {
+					return recurrence, err
+				}
+				recurrence.ByDay = append(recurrence.ByDay, weekday)
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102", strings.SplitN(value, "T", 2)[0])
+			if err != nil {
+				return recurrence, fmt.Errorf("%w: UNTIL must be a date such as 20241231", ErrInvalidRecurrence)
+			}
+			recurrence.Until = until
+		default:
+			return recurrence, fmt.Errorf("%w: %s is not supported", ErrInvalidRecurrence, name)
+		}
+	}
+
+	if recurrence.Freq == "" {
+		return recurrence, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if recurrence.Count > 0 && !recurrence.Until.IsZero() {
+		return recurrence, fmt.Errorf("%w: UNTIL and COUNT cannot be combined", ErrInvalidRecurrence)
+	}
+	if recurrence.Freq != Domain.FreqMonthly {
+		for _, day := range recurrence.ByDay {
+			if day.Ordinal != 0 {
+				return recurrence, fmt.Errorf("%w: BYDAY ordinals are only allowed in monthly rules", ErrInvalidRecurrence)
+			}
+		}
+	}
+	return recurrence, nil
+}
+
+// ParseDueDate returns the day of a due date, which starts with a YYYY-MM-DD
+// date.
+func ParseDueDate(dueDate string) (time.Time, error) {
+	if len(dueDate) < len("2006-01-02") {
+		return time.Time{}, fmt.Errorf("due date %q is not in YYYY-MM-DD form", dueDate)
+	}
+	day, err := time.Parse("2006-01-02", dueDate[:len("2006-01-02")])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("due date %q is not in YYYY-MM-DD form", dueDate)
+	}
+	return day, nil
+}
+
+func parseWeekdayNum(value string) (Domain.WeekdayNum, error) {
+	match := byDayPattern.FindStringSubmatch(value)
+	if match == nil {
+		return Domain.WeekdayNum{}, fmt.Errorf("%w: %q is not a BYDAY day", ErrInvalidRecurrence, value)
+	}
+
+	var day Domain.WeekdayNum
+	if match[1] != "" {
+		day.Ordinal, _ = strconv.Atoi(match[1])
+		if day.Ordinal == 0 || day.Ordinal < -5 || day.Ordinal > 5 {
+			return day, fmt.Errorf("%w: BYDAY ordinals go from -5 to 5", ErrInvalidRecurrence)
+		}
+	}
+	for weekday, code := range Domain.WeekdayCodes {
+		if code == match[2] {
+			day.Weekday = time.Weekday(weekday)
+		}
+	}
+	return day, nil
+}
+
+// prepareRecurrence validates and normalises the recurrence rule of a task,
+// and makes a task that starts recurring the first occurrence of its series.
+func prepareRecurrence(task *Domain.Task) error {
+	if task.Recurrence == "" {
+		return nil
+	}
+
+	recurrence, err := ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	if _, err := ParseDueDate(task.DueDate); err != nil {
+		return fmt.Errorf("%w: a recurring task needs a due date: %v", ErrInvalidTask, err)
+	}
+	task.Recurrence = recurrence.String()
+	if task.SeriesID == 0 {
+		task.SeriesID = task.ID
+		task.Occurrence = 1
+	}
+	return nil
+}
+
+// completeOccurrence creates the occurrence following a task that has just
+// been completed, if it is the last occurrence of its series.
+func (ws *workspace) completeOccurrence(task Domain.Task, actor string) error {
+	if task.Recurrence == "" {
+		return nil
+	}
+	series := ws.taskRepo.GetSeries(task.SeriesID)
+	if len(series) > 0 && series[len(series)-1].Occurrence != task.Occurrence {
+		return nil
+	}
+
+	_, _, err := ws.createNextOccurrence(task, truncateDay(time.Now()), actor)
+	return err
+}
+
+// createNextOccurrence creates the occurrence of a series following the
+// given one. Occurrences that would already be overdue are skipped. It
+// returns false when the series is over.
+func (ws *workspace) createNextOccurrence(latest Domain.Task, today time.Time, actor string) (Domain.Task, bool, error) {
+	recurrence, err := ParseRecurrence(latest.Recurrence)
+	if err != nil {
+		return latest, false, err
+	}
+	due, err := ParseDueDate(latest.DueDate)
+	if err != nil {
+		return latest, false, err
+	}
+
+	n := latest.Occurrence
+	for {
+		var ok bool
+		if due, ok = recurrence.Next(due, n); !ok {
+			return latest, false, nil
+		}
+		n++
+		if !due.Before(today) {
+			break
+		}
+	}
+
+	next := latest
+	next.ID = ws.getNextTaskID()
+	next.Occurrence = n
+	// External IDs are unique, and name the imported occurrence only.
+	next.ExternalID = ""
+	next.DueDate = due.Format("2006-01-02") + latest.DueDate[len("2006-01-02"):]
+	next.Status = Domain.StatusPending
+	next.DeletedAt = nil
+	next.BlockedBy = []int{}
+	next.Checklist = resetChecklist(latest.Checklist)
+	next.Labels = ws.existingLabels(latest.Labels)
+	next.Overdue = next.IsOverdueAt(time.Now())
+	if next.ParentID != 0 {
+		if _, err := ws.taskRepo.GetTaskByID(next.ParentID); err != nil {
+			next.ParentID = 0
+		}
+	}
+	next.Rank = 0
+	if next.ProjectID != 0 {
+		if project, err := ws.projectRepo.GetProjectByID(next.ProjectID); err == nil {
+			next.Status = project.Columns[0].Status
+			ranks := columnRanks(ws.taskRepo.GetTasksByProject(next.ProjectID), next.Status, 0)
+			next.Rank, _ = PositionRank(ranks, len(ranks))
+		} else {
+			next.ProjectID = 0
+		}
+	}
+	stampTask(nil, &next, time.Now().UTC())
+
+	if err := ws.taskRepo.CreateTask(next); err != nil {
+		return next, false, err
+	}
+	ws.saveRevision(Domain.RevisionCreated, actor, nil, next)
+	return next, true, nil
+}
+
+// resetChecklist returns a copy of a checklist with every item left to do.
+func resetChecklist(checklist []Domain.ChecklistItem) []Domain.ChecklistItem {
+	reset := make([]Domain.ChecklistItem, len(checklist))
+	for i, item := range checklist {
+		reset[i] = Domain.ChecklistItem{Text: item.Text}
+	}
+	return reset
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
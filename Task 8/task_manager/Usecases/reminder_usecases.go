@@ -0,0 +1,222 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+// ReminderLeadTimes are how long before a task is due its assignee is
+// reminded of it.
+var ReminderLeadTimes = []time.Duration{24 * time.Hour}
+
+// ReminderInterval is how often the scheduler checks due dates.
+var ReminderInterval = time.Minute
+
+// INotifier delivers notifications to users.
+type INotifier interface {
+	Notify(notification Domain.Notification) error
+}
+
+// notifier sends the reminders. Without one, tasks are still marked overdue
+// but nobody is reminded.
+var notifier INotifier
+
+// UseNotifier sets the notifier reminders are sent through.
+func UseNotifier(n INotifier) {
+	notifier = n
+}
+
+type IReminderService interface {
+	In(workspaceID int) IReminderService
+	WithContext(ctx context.Context) IReminderService
+	CheckDueDates(now time.Time) ([]Domain.Reminder, error)
+	GetReminders(taskID int) ([]Domain.Reminder, error)
+}
+
+type ReminderService struct {
+	*workspace
+}
+
+func NewReminderService(dbName string) IReminderService {
+	return &ReminderService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the reminders of the given workspace.
+func (r *ReminderService) In(workspaceID int) IReminderService {
+	return &ReminderService{r.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (r *ReminderService) WithContext(ctx context.Context) IReminderService {
+	return &ReminderService{r.withContext(ctx)}
+}
+
+// CheckDueDates marks the open tasks past their due time as overdue, clears
+// the mark from the others, and reminds the assignees of the tasks that are
+// due soon or overdue. It returns the reminders sent.
+func (r *ReminderService) CheckDueDates(now time.Time) ([]Domain.Reminder, error) {
+	defer r.span("ReminderService.CheckDueDates")()
+	for _, task := range r.taskRepo.GetOverdueTasks() {
+		if !task.IsOverdueAt(now) {
+			if err := r.taskRepo.SetOverdue(task.ID, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var longest time.Duration
+	for _, lead := range ReminderLeadTimes {
+		longest = max(longest, lead)
+	}
+	horizon := now.Add(longest)
+
+	sent := []Domain.Reminder{}
+	for _, task := range r.taskRepo.GetOpenTasksDueBefore(horizon.UTC().AddDate(0, 0, 1).Format("2006-01-02")) {
+		due, ok := task.Due()
+		if !ok {
+			continue
+		}
+		kind, lead, ok := ReminderFor(due, now, ReminderLeadTimes)
+		if !ok {
+			continue
+		}
+
+		if kind == Domain.ReminderOverdue && !task.Overdue {
+			if err := r.taskRepo.SetOverdue(task.ID, true); err != nil {
+				return sent, err
+			}
+			task.Overdue = true
+		}
+		reminder, ok, err := r.sendReminder(task, kind, lead, now)
+		if err != nil {
+			slog.ErrorContext(r.ctx, "reminder not sent", "task_id", task.ID, "error", err)
+			continue
+		}
+		if ok {
+			sent = append(sent, reminder)
+		}
+	}
+	return sent, nil
+}
+
+func (r *ReminderService) GetReminders(taskID int) ([]Domain.Reminder, error) {
+	defer r.span("ReminderService.GetReminders")()
+	if _, err := r.taskRepo.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+	return r.reminderRepo.GetReminders(taskID)
+}
+
+// StartReminderScheduler checks the due dates of every workspace in the
+// background, right away and then every ReminderInterval, until ctx is done.
+// The returned channel is closed once the scheduler has stopped.
+func StartReminderScheduler(ctx context.Context, service IReminderService, workspaces IWorkspaceService) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ReminderInterval)
+		defer ticker.Stop()
+		for {
+			forEachWorkspace(ctx, workspaces, func(id int) {
+				if _, err := service.In(id).CheckDueDates(time.Now()); err != nil {
+					slog.Error("due dates not checked", "workspace_id", id, "error", err)
+				}
+			})
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// ReminderFor returns the reminder due at the given time about a task due at
+// the given time: an overdue reminder once it is due, and before that a due
+// soon reminder for the shortest lead time already reached. It returns false
+// when no lead time has been reached yet.
+func ReminderFor(due, now time.Time, leadTimes []time.Duration) (string, time.Duration, bool) {
+	if !now.Before(due) {
+		return Domain.ReminderOverdue, 0, true
+	}
+
+	found := false
+	var shortest time.Duration
+	for _, lead := range leadTimes {
+		if !now.Before(due.Add(-lead)) && (!found || lead < shortest) {
+			shortest, found = lead, true
+		}
+	}
+	return Domain.ReminderDueSoon, shortest, found
+}
+
+// ReminderNotification writes the notification reminding a user of a task.
+func ReminderNotification(reminder Domain.Reminder, user Domain.User, task Domain.Task) Domain.Notification {
+	notification := Domain.Notification{
+		Kind:      reminder.Kind,
+		Recipient: user.Username,
+		Email:     user.Email,
+		Task:      task,
+	}
+	if reminder.Kind == Domain.ReminderOverdue {
+		notification.Subject = fmt.Sprintf("Task %q is overdue", task.Title)
+		notification.Body = fmt.Sprintf("Task #%d %q was due on %s and is not completed yet.", task.ID, task.Title, task.DueDate)
+	} else {
+		notification.Subject = fmt.Sprintf("Task %q is due in %s", task.Title, formatLead(time.Duration(reminder.LeadMinutes)*time.Minute))
+		notification.Body = fmt.Sprintf("Task #%d %q is due on %s.", task.ID, task.Title, task.DueDate)
+	}
+	return notification
+}
+
+// sendReminder sends a reminder to the assignee of a task unless it has
+// already been sent. The reminder is recorded first, so that it is sent once
+// even when several schedulers run, and forgotten if it cannot be sent.
+func (ws *workspace) sendReminder(task Domain.Task, kind string, lead time.Duration, now time.Time) (Domain.Reminder, bool, error) {
+	reminder := Domain.Reminder{
+		TaskID:      task.ID,
+		Kind:        kind,
+		LeadMinutes: int(lead / time.Minute),
+		DueDate:     task.DueDate,
+		Recipient:   task.Assignee,
+		SentAt:      now.UTC(),
+	}
+	if notifier == nil || task.Assignee == "" {
+		return reminder, false, nil
+	}
+	user, err := ws.userRepo.GetUserbyUsername(task.Assignee)
+	if err != nil {
+		return reminder, false, err
+	}
+
+	reminder.ID = ws.reminderRepo.GetNextReminderID()
+	claimed, err := ws.reminderRepo.Claim(reminder)
+	if err != nil || !claimed {
+		return reminder, false, err
+	}
+	if err := notifier.Notify(ReminderNotification(reminder, user, task)); err != nil {
+		if err := ws.reminderRepo.Release(reminder); err != nil {
+			slog.ErrorContext(ws.ctx, "reminder claim not released", "reminder_id", reminder.ID, "error", err)
+		}
+		return reminder, false, err
+	}
+	return reminder, true, nil
+}
+
+// formatLead formats a lead time without its zero minutes and seconds, as
+// in 24h or 1h30m.
+func formatLead(lead time.Duration) string {
+	text := lead.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
@@ -0,0 +1,134 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+)
+
+var ErrInvalidReport = errors.New("invalid report")
+
+type IReportService interface {
+	In(workspaceID int) IReportService
+	WithContext(ctx context.Context) IReportService
+	Throughput(filter Domain.ReportFilter) ([]Domain.ThroughputBucket, error)
+	CycleTime(filter Domain.ReportFilter) (Domain.CycleTimeReport, error)
+	Overdue(filter Domain.ReportFilter) (Domain.OverdueReport, error)
+	Workload(filter Domain.ReportFilter) ([]Domain.Workload, error)
+}
+
+type ReportService struct {
+	*workspace
+}
+
+func NewReportService(dbName string) IReportService {
+	return &ReportService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the reports of the given workspace.
+func (r *ReportService) In(workspaceID int) IReportService {
+	return &ReportService{r.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (r *ReportService) WithContext(ctx context.Context) IReportService {
+	return &ReportService{r.withContext(ctx)}
+}
+
+// UseMemoryReports computes reports in the API process, over every task of
+// the workspace, instead of with MongoDB aggregation pipelines.
+func UseMemoryReports() {
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	memoryReports = true
+	for _, ws := range workspaces {
+		ws.reportRepo = ws.memoryReports()
+	}
+}
+
+func (ws *workspace) memoryReports() Repositories.IReportRepository {
+	return Repositories.NewMemoryReportRepository(ws.taskRepo.GetTasks)
+}
+
+// Throughput counts the tasks created and completed per day, week or month,
+// weekly by default. Periods without either are left out.
+func (r *ReportService) Throughput(filter Domain.ReportFilter) ([]Domain.ThroughputBucket, error) {
+	defer r.span("ReportService.Throughput")()
+	if filter.Interval == "" {
+		filter.Interval = Domain.IntervalWeek
+	}
+	if filter.Interval != Domain.IntervalDay && filter.Interval != Domain.IntervalWeek && filter.Interval != Domain.IntervalMonth {
+		return nil, fmt.Errorf("%w: interval must be day, week or month", ErrInvalidReport)
+	}
+	if err := checkReportRange(filter); err != nil {
+		return nil, err
+	}
+	return r.reportRepo.Throughput(filter)
+}
+
+// CycleTime summarises the lead and cycle times of the tasks completed in
+// the filter's period.
+func (r *ReportService) CycleTime(filter Domain.ReportFilter) (Domain.CycleTimeReport, error) {
+	defer r.span("ReportService.CycleTime")()
+	if err := checkReportRange(filter); err != nil {
+		return Domain.CycleTimeReport{}, err
+	}
+	durations, err := r.reportRepo.Durations(filter)
+	if err != nil {
+		return Domain.CycleTimeReport{}, err
+	}
+	return Domain.CycleTimeReport{
+		LeadTime:  Summarize(durations.LeadTimes),
+		CycleTime: Summarize(durations.CycleTimes),
+	}, nil
+}
+
+func (r *ReportService) Overdue(filter Domain.ReportFilter) (Domain.OverdueReport, error) {
+	defer r.span("ReportService.Overdue")()
+	return r.reportRepo.Overdue(filter)
+}
+
+func (r *ReportService) Workload(filter Domain.ReportFilter) ([]Domain.Workload, error) {
+	defer r.span("ReportService.Workload")()
+	return r.reportRepo.Workload(filter)
+}
+
+// Summarize returns the mean, maximum and nearest-rank percentiles of
+// durations sorted in ascending order, rounded to hundredths.
+func Summarize(sorted []float64) Domain.Percentiles {
+	summary := Domain.Percentiles{Count: len(sorted)}
+	if len(sorted) == 0 {
+		return summary
+	}
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return round2(sorted[max(rank, 1)-1])
+	}
+	summary.Mean = round2(sum / float64(len(sorted)))
+	summary.P50 = percentile(50)
+	summary.P75 = percentile(75)
+	summary.P90 = percentile(90)
+	summary.P95 = percentile(95)
+	summary.Max = round2(sorted[len(sorted)-1])
+	return summary
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func checkReportRange(filter Domain.ReportFilter) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidReport)
+	}
+	return nil
+}
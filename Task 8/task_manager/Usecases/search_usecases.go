@@ -0,0 +1,367 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalidQuery = errors.New("invalid search query")
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// snippetLength is the length, in bytes, past which highlighted text is cut
+// down to the part around the first match.
+const snippetLength = 160
+
+type ISearchService interface {
+	In(workspaceID int) ISearchService
+	WithContext(ctx context.Context) ISearchService
+	Search(query string, limit int) ([]Domain.SearchHit, error)
+}
+
+type SearchService struct {
+	*workspace
+}
+
+func NewSearchService(dbName string) ISearchService {
+	return &SearchService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the search of the given workspace.
+func (s *SearchService) In(workspaceID int) ISearchService {
+	return &SearchService{s.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (s *SearchService) WithContext(ctx context.Context) ISearchService {
+	return &SearchService{s.withContext(ctx)}
+}
+
+// UseMemorySearch replaces the MongoDB text search with in-process inverted
+// indexes, one per workspace, filled with the current tasks and comments and
+// kept up to date as they change.
+func UseMemorySearch() {
+	workspacesMu.Lock()
+	memorySearch = true
+	opened := make([]*workspace, 0, len(workspaces))
+	for _, ws := range workspaces {
+		opened = append(opened, ws)
+	}
+	workspacesMu.Unlock()
+
+	// The indexes are built without the lock, which would otherwise hold up
+	// every other workspace until they all are.
+	for _, ws := range opened {
+		index := ws.searchIndex()
+		workspacesMu.Lock()
+		ws.searchRepo = index
+		workspacesMu.Unlock()
+	}
+}
+
+// searchIndex returns an in-process index of the workspace's tasks and
+// comments. A workspace whose index cannot be built keeps searching through
+// MongoDB.
+func (ws *workspace) searchIndex() Repositories.ISearchRepository {
+	index, err := ws.buildSearchIndex()
+	if err != nil {
+		slog.Error("search index not built", "workspace_id", ws.id, "error", err)
+		return Repositories.NewSearchRepository(Repositories.WorkspaceDatabase(ws.dbName, ws.id))
+	}
+	return index
+}
+
+func (ws *workspace) buildSearchIndex() (Repositories.ISearchRepository, error) {
+	tasks, err := ws.taskRepo.ListTasks()
+	if err != nil {
+		return nil, err
+	}
+	index := Repositories.NewMemorySearchRepository()
+	for _, task := range tasks {
+		index.IndexTask(task)
+		comments, err := ws.commentRepo.GetComments(task.ID, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			index.IndexComment(comment)
+		}
+	}
+	return index, nil
+}
+
+func (s *SearchService) Search(query string, limit int) ([]Domain.SearchHit, error) {
+	defer s.span("SearchService.Search")()
+	parsed, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := s.searchRepo.Search(parsed, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Highlights = Highlight(hits[i].Fields, parsed)
+	}
+	return hits, nil
+}
+
+// ParseSearchQuery parses a search query made of:
+//   - words, matched as whole words: deploy staging
+//   - phrases, matched word for word: "release notes"
+//   - prefixes, matching any word starting with them: deplo*
+//   - qualifiers restricting the tasks: status:pending, assignee:alice,
+//     label:backend, priority:P1 and due:<2024-09-01 (with <, <=, >, >=
+//     or = and a YYYY-MM-DD date)
+//
+// A query made only of qualifiers returns the matching tasks.
+func ParseSearchQuery(query string) (Domain.SearchQuery, error) {
+	var parsed Domain.SearchQuery
+	seen := map[string]bool{}
+	addTerm := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			parsed.Terms = append(parsed.Terms, term)
+		}
+	}
+
+	for _, token := range splitSearchQuery(query) {
+		if strings.HasPrefix(token, `"`) {
+			phrase := strings.Join(Domain.Tokenize(token), " ")
+			if phrase != "" {
+				parsed.Phrases = append(parsed.Phrases, phrase)
+			}
+			continue
+		}
+
+		if key, value, ok := strings.Cut(token, ":"); ok && value != "" {
+			handled, err := parseQualifier(&parsed, strings.ToLower(key), value)
+			if err != nil {
+				return parsed, err
+			}
+			if handled {
+				continue
+			}
+		}
+
+		words := Domain.Tokenize(token)
+		if strings.HasSuffix(token, "*") && len(words) > 0 {
+			parsed.Prefixes = append(parsed.Prefixes, words[len(words)-1])
+			words = words[:len(words)-1]
+		}
+		for _, word := range words {
+			addTerm(word)
+		}
+	}
+
+	if !parsed.HasText() && !parsed.HasQualifiers() {
+		return parsed, fmt.Errorf("%w: it needs a word to search for or a qualifier", ErrInvalidQuery)
+	}
+	return parsed, nil
+}
+
+func parseQualifier(query *Domain.SearchQuery, key, value string) (bool, error) {
+	switch key {
+	case "status":
+		query.Status = value
+	case "assignee":
+		query.Assignee = value
+	case "label":
+		query.Label = value
+	case "priority":
+		query.Priority = value
+	case "due":
+		op := "="
+		for _, candidate := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(value, candidate) {
+				op = candidate
+				value = strings.TrimPrefix(value, candidate)
+				break
+			}
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return false, fmt.Errorf("%w: due date %q is not in YYYY-MM-DD form", ErrInvalidQuery, value)
+		}
+		query.Due = append(query.Due, Domain.DueCondition{Op: op, Date: value})
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// splitSearchQuery splits a query on whitespace, keeping quoted phrases, quotes
+// included, in one piece.
+func splitSearchQuery(query string) []string {
+	tokens := []string{}
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !quoted && current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			current.WriteRune(r)
+			if quoted {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// Highlight wraps the words of each field matching the query in <mark> tags,
+// escaping the rest of the text as HTML. Long fields are cut down to a
+// snippet around the first match. Fields without a match are left out.
+func Highlight(fields map[string]string, query Domain.SearchQuery) map[string]string {
+	terms := map[string]bool{}
+	for _, term := range query.Terms {
+		terms[term] = true
+	}
+	phrases := [][]string{}
+	for _, phrase := range query.Phrases {
+		phrases = append(phrases, strings.Fields(phrase))
+	}
+
+	highlights := map[string]string{}
+	for field, text := range fields {
+		locations := wordPattern.FindAllStringIndex(text, -1)
+		words := make([]string, len(locations))
+		for i, location := range locations {
+			words[i] = strings.ToLower(text[location[0]:location[1]])
+		}
+
+		var marks [][2]int
+		for i, word := range words {
+			if terms[word] || hasAnyPrefix(word, query.Prefixes) {
+				marks = append(marks, [2]int{locations[i][0], locations[i][1]})
+			}
+		}
+		for _, phrase := range phrases {
+			for i := 0; i+len(phrase) <= len(words); i++ {
+				if equalWords(words[i:i+len(phrase)], phrase) {
+					marks = append(marks, [2]int{locations[i][0], locations[i+len(phrase)-1][1]})
+				}
+			}
+		}
+		if len(marks) == 0 {
+			continue
+		}
+
+		highlights[field] = markText(text, mergeRanges(marks))
+	}
+	return highlights
+}
+
+func markText(text string, marks [][2]int) string {
+	start, end := 0, len(text)
+	if len(text) > snippetLength {
+		start = marks[0][0] - snippetLength/3
+		if start < 0 {
+			start = 0
+		}
+		end = start + snippetLength
+		if end > len(text) {
+			end = len(text)
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	position := start
+	for _, mark := range marks {
+		if mark[1] <= start || mark[0] >= end {
+			continue
+		}
+		from, to := max(mark[0], start), min(mark[1], end)
+		builder.WriteString(html.EscapeString(text[position:from]))
+		builder.WriteString("<mark>" + html.EscapeString(text[from:to]) + "</mark>")
+		position = to
+	}
+	builder.WriteString(html.EscapeString(text[position:end]))
+	if end < len(text) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
+
+func mergeRanges(ranges [][2]int) [][2]int {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := [][2]int{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			last[1] = max(last[1], r[1])
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+func hasAnyPrefix(word string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// The index helpers below keep the workspace's search index up to date.
+
+func (ws *workspace) indexTask(task Domain.Task) {
+	ws.searchRepo.IndexTask(task)
+}
+
+func (ws *workspace) removeTasksFromIndex(ids []int) {
+	for _, id := range ids {
+		ws.searchRepo.RemoveTask(id)
+	}
+}
+
+func (ws *workspace) indexComment(comment Domain.Comment) {
+	ws.searchRepo.IndexComment(comment)
+}
@@ -0,0 +1,190 @@
+package Usecases
+
+import (
+	"errors"
+	"fmt"
+	"task_manager/Domain"
+)
+
+var (
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	ErrParentCycle     = errors.New("a task cannot be nested under itself or one of its subtasks")
+	ErrBlocked         = errors.New("task is blocked by tasks that are not completed")
+)
+
+func (t *TaskService) GetSubtasks(id int) ([]Domain.Task, Domain.Progress, error) {
+	defer t.span("TaskService.GetSubtasks")()
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return nil, Domain.Progress{}, err
+	}
+
+	return t.taskRepo.GetSubtasks(id), RollUpProgress(task, t.taskRepo.GetSubtasks), nil
+}
+
+func (t *TaskService) CreateSubtask(parentID int, task Domain.Task, actor string) (Domain.Task, error) {
+	defer t.span("TaskService.CreateSubtask")()
+	task.ParentID = parentID
+	return t.CreateTask(task, actor)
+}
+
+// GetDependencies returns the tasks blocking the given task and the tasks it
+// blocks.
+func (t *TaskService) GetDependencies(id int) ([]Domain.Task, []Domain.Task, error) {
+	defer t.span("TaskService.GetDependencies")()
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blockedBy := []Domain.Task{}
+	for _, blockerID := range task.BlockedBy {
+		if blocker, err := t.taskRepo.GetTaskByID(blockerID); err == nil {
+			blockedBy = append(blockedBy, blocker)
+		}
+	}
+	return blockedBy, t.taskRepo.GetBlockedTasks(id), nil
+}
+
+func (t *TaskService) AddDependency(id, blockerID int, actor string) error {
+	defer t.span("TaskService.AddDependency")()
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := t.taskRepo.GetTaskByID(blockerID); err != nil {
+		return fmt.Errorf("blocking task %d not found", blockerID)
+	}
+	if CreatesDependencyCycle(id, blockerID, t.blockersOf) {
+		return ErrDependencyCycle
+	}
+
+	if err := t.taskRepo.AddDependency(id, blockerID); err != nil {
+		return err
+	}
+	return t.saveTaskRevision(task, actor)
+}
+
+func (t *TaskService) RemoveDependency(id, blockerID int, actor string) error {
+	defer t.span("TaskService.RemoveDependency")()
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := t.taskRepo.RemoveDependency(id, blockerID); err != nil {
+		return err
+	}
+	return t.saveTaskRevision(task, actor)
+}
+
+// CreatesDependencyCycle reports whether making taskID blocked by blockerID
+// would close a loop, i.e. whether blockerID already depends on taskID,
+// directly or transitively.
+func CreatesDependencyCycle(taskID, blockerID int, blockedBy func(id int) []int) bool {
+	visited := map[int]bool{}
+	stack := []int{blockerID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == taskID {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, blockedBy(current)...)
+	}
+	return false
+}
+
+// RollUpProgress computes the progress of a task. A task with subtasks is as
+// far along as the average of its subtasks, a task with only a checklist is
+// as far along as its checked items, and any other task is either done or not.
+func RollUpProgress(task Domain.Task, subtasksOf func(id int) []Domain.Task) Domain.Progress {
+	progress := Domain.Progress{ChecklistTotal: len(task.Checklist)}
+	for _, item := range task.Checklist {
+		if item.Done {
+			progress.ChecklistDone++
+		}
+	}
+
+	subtasks := subtasksOf(task.ID)
+	progress.TotalSubtasks = len(subtasks)
+	for _, subtask := range subtasks {
+		if subtask.IsCompleted() {
+			progress.CompletedSubtasks++
+		}
+	}
+
+	switch {
+	case task.IsCompleted():
+		progress.Percent = 100
+	case len(subtasks) > 0:
+		total := 0.0
+		for _, subtask := range subtasks {
+			total += RollUpProgress(subtask, subtasksOf).Percent
+		}
+		progress.Percent = total / float64(len(subtasks))
+	case progress.ChecklistTotal > 0:
+		progress.Percent = 100 * float64(progress.ChecklistDone) / float64(progress.ChecklistTotal)
+	}
+	return progress
+}
+
+// validateStructure checks the project, parent and blockers of a task before
+// it is saved: the project and parent must exist, the parent must not be the
+// task itself or one of its descendants, and the task cannot be completed
+// while a blocker is still open.
+func (ws *workspace) validateStructure(task Domain.Task) error {
+	if task.ProjectID != 0 {
+		if _, err := ws.projectRepo.GetProjectByID(task.ProjectID); err != nil {
+			return ErrProjectNotFound
+		}
+	}
+	if task.ParentID != 0 {
+		if _, err := ws.taskRepo.GetTaskByID(task.ParentID); err != nil {
+			return fmt.Errorf("parent task %d not found", task.ParentID)
+		}
+		for ancestor := task.ParentID; ancestor != 0; {
+			if ancestor == task.ID {
+				return ErrParentCycle
+			}
+			parent, err := ws.taskRepo.GetTaskByID(ancestor)
+			if err != nil {
+				break
+			}
+			ancestor = parent.ParentID
+		}
+	}
+
+	if task.IsCompleted() {
+		for _, blockerID := range task.BlockedBy {
+			blocker, err := ws.taskRepo.GetTaskByID(blockerID)
+			if err == nil && !blocker.IsCompleted() {
+				return ErrBlocked
+			}
+		}
+	}
+	return nil
+}
+
+func (ws *workspace) blockersOf(id int) []int {
+	task, err := ws.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return nil
+	}
+	return task.BlockedBy
+}
+
+// saveTaskRevision records the current state of a task after a change that
+// did not go through UpdateTask.
+func (ws *workspace) saveTaskRevision(before Domain.Task, actor string) error {
+	after, err := ws.taskRepo.GetTaskByID(before.ID)
+	if err != nil {
+		return err
+	}
+	ws.saveRevision(Domain.RevisionUpdated, actor, before, after)
+	return nil
+}
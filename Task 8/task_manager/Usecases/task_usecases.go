@@ -1,75 +1,377 @@
 package Usecases
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"task_manager/Domain"
-	"task_manager/Repositories"
+	"time"
 )
 
-var taskRepo Repositories.ITaskRepository
+// TrashRetention is how long a deleted task stays in the trash before it is
+// purged for good.
+var TrashRetention = 30 * 24 * time.Hour
 
 type ITaskService interface {
+	In(workspaceID int) ITaskService
+	WithContext(ctx context.Context) ITaskService
 	GetTasks() []Domain.Task
 	GetTaskByID(id int) (Domain.Task, error)
-	CreateTask(task Domain.Task) (Domain.Task, error)
-	UpdateTask(id int, updatedTask Domain.Task) error
-	DeleteTask(id int) error
+	CreateTask(task Domain.Task, actor string) (Domain.Task, error)
+	UpdateTask(id int, updatedTask Domain.Task, actor string) error
+	DeleteTask(id int, actor string) error
+	GetHistory(id int) ([]Domain.TaskRevision, error)
+	RevertTask(id, revision int, actor string) (Domain.Task, error)
+	GetTrash() []Domain.Task
+	RestoreTask(id int, actor string) (Domain.Task, error)
+	PurgeTrash() error
+	GetSubtasks(id int) ([]Domain.Task, Domain.Progress, error)
+	CreateSubtask(parentID int, task Domain.Task, actor string) (Domain.Task, error)
+	GetDependencies(id int) ([]Domain.Task, []Domain.Task, error)
+	AddDependency(id, blockerID int, actor string) error
+	RemoveDependency(id, blockerID int, actor string) error
+	GetTasksByProject(projectID int) []Domain.Task
+	FindTasks(filter Domain.TaskFilter) []Domain.Task
+	FacetTasks(filter Domain.TaskFilter) (Domain.TaskFacets, error)
+	GetOccurrences(id int) ([]Domain.Task, error)
+	UpdateFutureOccurrences(id int, updatedTask Domain.Task, actor string) ([]Domain.Task, error)
+	GenerateOccurrences(now time.Time) ([]Domain.Task, error)
+	BulkTasks(request Domain.BulkRequest, actor string) ([]Domain.BulkResult, error)
+	ExportTasks(filter Domain.TaskFilter, format string, w io.Writer) error
+	ImportTasks(r io.Reader, options Domain.ImportOptions, actor string) (Domain.ImportReport, error)
 }
 
-type TaskService struct{}
+type TaskService struct {
+	*workspace
+}
 
 func NewTaskService(dbName string) ITaskService {
-	taskRepo = Repositories.NewTaskRepository(dbName)
-	return &TaskService{}
+	return &TaskService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the tasks of the given workspace.
+func (t *TaskService) In(workspaceID int) ITaskService {
+	return &TaskService{t.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (t *TaskService) WithContext(ctx context.Context) ITaskService {
+	return &TaskService{t.withContext(ctx)}
 }
 
 func (t *TaskService) GetTasks() []Domain.Task {
+	defer t.span("TaskService.GetTasks")()
+
+	return t.taskRepo.GetTasks()
+}
+
+func (t *TaskService) FindTasks(filter Domain.TaskFilter) []Domain.Task {
+	defer t.span("TaskService.FindTasks")()
+	return t.taskRepo.FindTasks(filter)
+}
+
+func (t *TaskService) FacetTasks(filter Domain.TaskFilter) (Domain.TaskFacets, error) {
+	defer t.span("TaskService.FacetTasks")()
+	return t.taskRepo.FacetTasks(filter)
+}
 
-	return taskRepo.GetTasks()
+func (t *TaskService) GetTasksByProject(projectID int) []Domain.Task {
+	defer t.span("TaskService.GetTasksByProject")()
+	return t.taskRepo.GetTasksByProject(projectID)
 }
 
 func (t *TaskService) GetTaskByID(id int) (Domain.Task, error) {
-	task, err := taskRepo.GetTaskByID(id)
+	defer t.span("TaskService.GetTaskByID")()
+	task, err := t.taskRepo.GetTaskByID(id)
 	if err != nil {
 		return task, err
 	}
 	return task, nil
 }
 
-func (t *TaskService) CreateTask(task Domain.Task) (Domain.Task, error) {
-	task.ID = getNextTaskID()
+func (t *TaskService) CreateTask(task Domain.Task, actor string) (Domain.Task, error) {
+	defer t.span("TaskService.CreateTask")()
+	task.ID = t.getNextTaskID()
+	task, err := t.prepareTask(task)
+	if err != nil {
+		return task, err
+	}
 
-	if err := taskRepo.CreateTask(task); err != nil {
+	if err := t.taskRepo.CreateTask(task); err != nil {
 		return task, err
 	}
+	t.saveRevision(Domain.RevisionCreated, actor, nil, task)
 	return task, nil
 }
 
-func (t *TaskService) UpdateTask(id int, updatedTask Domain.Task) error {
-	_, err := taskRepo.GetTaskByID(id)
+// UpdateTask updates a single task. The recurrence rule of a task that is
+// part of a series is left as it is; see UpdateFutureOccurrences.
+func (t *TaskService) UpdateTask(id int, updatedTask Domain.Task, actor string) error {
+	defer t.span("TaskService.UpdateTask")()
+	existing, err := t.taskRepo.GetTaskByID(id)
 	if err != nil {
 		return err
 	}
 
-	updatedTask.ID = id
-	if err := taskRepo.UpdateTask(id, updatedTask); err != nil {
+	if existing.SeriesID != 0 {
+		updatedTask.Recurrence = existing.Recurrence
+	}
+	_, err = t.updateTask(existing, updatedTask, actor)
+	return err
+}
+
+func (t *TaskService) DeleteTask(id int, actor string) error {
+	defer t.span("TaskService.DeleteTask")()
+	existing, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
 		return err
 	}
+
+	if err := t.taskRepo.DeleteTask(id); err != nil {
+		return err
+	}
+
+	deleted := existing
+	now := time.Now().UTC()
+	deleted.DeletedAt = &now
+	t.saveRevision(Domain.RevisionDeleted, actor, existing, deleted)
+	// The task is in the trash whether or not the expired ones are purged.
+	if err := t.PurgeTrash(); err != nil {
+		slog.ErrorContext(t.ctx, "trash not purged", "error", err)
+	}
 	return nil
 }
 
-func (t *TaskService) DeleteTask(id int) error {
-	_, err := taskRepo.GetTaskByID(id)
+func (t *TaskService) GetHistory(id int) ([]Domain.TaskRevision, error) {
+	defer t.span("TaskService.GetHistory")()
+	return t.historyRepo.GetRevisions(id)
+}
+
+// RevertTask restores the fields of a task to the values it had at the given
+// revision. The revert itself is recorded as a new revision.
+func (t *TaskService) RevertTask(id, revision int, actor string) (Domain.Task, error) {
+	defer t.span("TaskService.RevertTask")()
+	existing, err := t.taskRepo.GetTaskByID(id)
 	if err != nil {
-		return err
+		return existing, err
+	}
+
+	target, err := t.historyRepo.GetRevision(id, revision)
+	if err != nil {
+		return existing, errors.New("revision not found")
+	}
+
+	reverted := target.Task
+	reverted.ID = id
+	reverted.DeletedAt = nil
+	reverted.BlockedBy = existing.BlockedBy
+	reverted.Rank = existing.Rank
+	reverted.Recurrence = existing.Recurrence
+	reverted.SeriesID = existing.SeriesID
+	reverted.Occurrence = existing.Occurrence
+	reverted.Labels = t.existingLabels(reverted.Labels)
+	reverted.Overdue = reverted.IsOverdueAt(time.Now())
+	stampTask(&existing, &reverted, time.Now().UTC())
+	if err := t.validateStructure(reverted); err != nil {
+		return existing, err
+	}
+	if err := t.taskRepo.UpdateTask(id, reverted); err != nil {
+		return existing, err
+	}
+	t.saveRevision(Domain.RevisionReverted, actor, existing, reverted)
+	return reverted, nil
+}
+
+func (t *TaskService) GetTrash() []Domain.Task {
+	defer t.span("TaskService.GetTrash")()
+	t.PurgeTrash()
+	return t.taskRepo.GetDeletedTasks()
+}
+
+func (t *TaskService) RestoreTask(id int, actor string) (Domain.Task, error) {
+	defer t.span("TaskService.RestoreTask")()
+	var deleted interface{}
+	for _, task := range t.taskRepo.GetDeletedTasks() {
+		if task.ID == id {
+			deleted = task
+		}
+	}
+
+	if err := t.taskRepo.RestoreTask(id); err != nil {
+		return Domain.Task{}, err
 	}
 
-	if err := taskRepo.DeleteTask(id); err != nil {
+	task, err := t.taskRepo.GetTaskByID(id)
+	if err != nil {
+		return task, err
+	}
+	t.saveRevision(Domain.RevisionRestored, actor, deleted, task)
+	return task, nil
+}
+
+// PurgeTrash permanently removes the tasks, with their history, attachments
+// and worklogs, that have been in the trash for longer than TrashRetention.
+func (t *TaskService) PurgeTrash() error {
+	defer t.span("TaskService.PurgeTrash")()
+	ids, err := t.taskRepo.PurgeDeletedTasks(time.Now().UTC().Add(-TrashRetention))
+	if err != nil {
 		return err
 	}
+	if len(ids) == 0 {
+		return nil
+	}
+	t.removeTasksFromIndex(ids)
+	t.removeTaskAttachments(ids)
+	t.removeTaskWorklogs(ids)
+	return t.historyRepo.DeleteRevisions(ids)
+}
+
+// prepareTask checks a new task and fills in the fields it is not given.
+func (ws *workspace) prepareTask(task Domain.Task) (Domain.Task, error) {
+	task.DeletedAt = nil
+	task.SeriesID = 0
+	task.Occurrence = 0
+	if task.Checklist == nil {
+		task.Checklist = []Domain.ChecklistItem{}
+	}
+	if task.BlockedBy == nil {
+		task.BlockedBy = []int{}
+	}
+	if task.Labels == nil {
+		task.Labels = []string{}
+	}
+	if err := ws.validateClassification(task); err != nil {
+		return task, err
+	}
+	if err := prepareRecurrence(&task); err != nil {
+		return task, err
+	}
+	task.Overdue = task.IsOverdueAt(time.Now())
+	stampTask(nil, &task, time.Now().UTC())
+	if task.ExternalID != "" {
+		if other, err := ws.taskRepo.GetTaskByExternalID(task.ExternalID); err == nil {
+			return task, fmt.Errorf("%w: external ID %q is already used by task %d", ErrInvalidTask, task.ExternalID, other.ID)
+		}
+	}
+	for _, blockerID := range task.BlockedBy {
+		if _, err := ws.taskRepo.GetTaskByID(blockerID); err != nil {
+			return task, fmt.Errorf("blocking task %d not found", blockerID)
+		}
+	}
+	if err := ws.validateStructure(task); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+// updateTask replaces the fields of an existing task, keeping its
+// dependencies, board position and place in its series. Completing the last
+// occurrence of a series creates the next one.
+func (ws *workspace) updateTask(existing, updatedTask Domain.Task, actor string) (Domain.Task, error) {
+	updatedTask, err := ws.prepareUpdate(existing, updatedTask)
+	if err != nil {
+		return existing, err
+	}
+	if err := ws.taskRepo.UpdateTask(existing.ID, updatedTask); err != nil {
+		return existing, err
+	}
+	return updatedTask, ws.finishUpdate(existing, updatedTask, actor)
+}
+
+// prepareUpdate checks the new fields of an existing task, keeping the ones
+// that are not changed by updates.
+func (ws *workspace) prepareUpdate(existing, updatedTask Domain.Task) (Domain.Task, error) {
+	updatedTask.ID = existing.ID
+	updatedTask.DeletedAt = nil
+	updatedTask.BlockedBy = existing.BlockedBy
+	updatedTask.Rank = existing.Rank
+	updatedTask.SeriesID = existing.SeriesID
+	updatedTask.Occurrence = existing.Occurrence
+	updatedTask.ExternalID = existing.ExternalID
+	if updatedTask.Checklist == nil {
+		updatedTask.Checklist = []Domain.ChecklistItem{}
+	}
+	if updatedTask.Labels == nil {
+		updatedTask.Labels = []string{}
+	}
+	if err := ws.validateClassification(updatedTask); err != nil {
+		return updatedTask, err
+	}
+	if err := prepareRecurrence(&updatedTask); err != nil {
+		return updatedTask, err
+	}
+	updatedTask.Overdue = updatedTask.IsOverdueAt(time.Now())
+	stampTask(&existing, &updatedTask, time.Now().UTC())
+	if err := ws.validateStructure(updatedTask); err != nil {
+		return updatedTask, err
+	}
+	return updatedTask, nil
+}
+
+// finishUpdate records an update once it is stored, and creates the next
+// occurrence when it completes the last one of a series.
+func (ws *workspace) finishUpdate(existing, updatedTask Domain.Task, actor string) error {
+	ws.saveRevision(Domain.RevisionUpdated, actor, existing, updatedTask)
+
+	if !existing.IsCompleted() && updatedTask.IsCompleted() {
+		return ws.completeOccurrence(updatedTask, actor)
+	}
 	return nil
+}
+
+// stampTask sets the times a task was created, started and completed, from
+// those of the existing task and the status it now has. A nil existing task
+// is being created now.
+func stampTask(existing, task *Domain.Task, now time.Time) {
+	if existing == nil {
+		task.CreatedAt, task.StartedAt, task.CompletedAt = &now, nil, nil
+	} else {
+		task.CreatedAt, task.StartedAt, task.CompletedAt = existing.CreatedAt, existing.StartedAt, existing.CompletedAt
+	}
 
+	if task.StartedAt == nil && !task.IsPending() && !task.IsCompleted() {
+		task.StartedAt = &now
+	}
+	if !task.IsCompleted() {
+		task.CompletedAt = nil
+	} else if task.CompletedAt == nil {
+		task.CompletedAt = &now
+	}
 }
 
-func getNextTaskID() int {
-	return taskRepo.GetNextTaskID()
+func (ws *workspace) getNextTaskID() int {
+	return ws.taskRepo.GetNextTaskID()
+}
+
+// saveRevision stores a snapshot of the task after a change, along with the
+// fields that changed, and publishes the change. A nil before records every
+// field.
+func (ws *workspace) saveRevision(action, actor string, before interface{}, after Domain.Task) {
+	revision := Domain.TaskRevision{
+		TaskID:    after.ID,
+		Revision:  ws.historyRepo.GetNextRevision(after.ID),
+		Action:    action,
+		Actor:     actor,
+		Timestamp: time.Now().UTC(),
+		Task:      after,
+		Changes:   Diff(before, after),
+	}
+	ws.historyRepo.SaveRevision(revision)
+	ws.indexTask(after)
+	ws.publishTaskEvent(action, actor, before, after, revision.Changes)
+}
+
+// existingLabels drops the labels that have been deleted since a revision was
+// taken.
+func (ws *workspace) existingLabels(names []string) []string {
+	labels := []string{}
+	for _, name := range names {
+		if _, err := ws.labelRepo.GetLabelByName(name); err == nil {
+			labels = append(labels, name)
+		}
+	}
+	return labels
 }
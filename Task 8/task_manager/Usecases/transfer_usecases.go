@@ -0,0 +1,517 @@
+package Usecases
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrInvalidImport = errors.New("invalid import")
+)
+
+// ImportMaxRows is how many rows an import may have. Imports are applied
+// BulkMaxTasks rows at a time, so an atomic import may have no more than
+// BulkMaxTasks rows.
+var ImportMaxRows = 10000
+
+// taskColumns are the CSV columns of exports, in order. Every column but id
+// can be imported.
+var taskColumns = []string{
+	"id", "external_id", "title", "description", "status", "due_date", "assignee", "priority",
+	"labels", "story_points", "estimate_minutes", "project_id", "parent_id", "recurrence",
+}
+
+// importKinds are the task fields imports can set, with the kind of their
+// values. Lists are comma-separated in CSV; checklists can only be imported
+// from JSON.
+var importKinds = map[string]string{
+	"external_id":      "string",
+	"title":            "string",
+	"description":      "string",
+	"status":           "string",
+	"due_date":         "string",
+	"assignee":         "string",
+	"priority":         "string",
+	"labels":           "list",
+	"story_points":     "float",
+	"estimate_minutes": "int",
+	"project_id":       "int",
+	"parent_id":        "int",
+	"recurrence":       "string",
+	"checklist":        "json",
+}
+
+// ExportTasks writes the tasks matching a filter in the given format: CSV,
+// JSON Lines or iCalendar.
+func (t *TaskService) ExportTasks(filter Domain.TaskFilter, format string, w io.Writer) error {
+	defer t.span("TaskService.ExportTasks")()
+	return WriteTasks(w, format, t.taskRepo.FindTasks(filter), time.Now())
+}
+
+// ImportTasks creates the rows of a CSV or JSON import as tasks, or updates
+// the tasks they match by external ID, through BulkTasks. Rows that fail do
+// not stop the others, unless the import is atomic.
+func (t *TaskService) ImportTasks(r io.Reader, options Domain.ImportOptions, actor string) (Domain.ImportReport, error) {
+	defer t.span("TaskService.ImportTasks")()
+	report := Domain.ImportReport{DryRun: options.DryRun, Rows: []Domain.ImportRow{}}
+	rows, ignored, err := ParseImport(r, options.Format, options.Mapping)
+	if err != nil {
+		return report, err
+	}
+	report.Ignored = ignored
+	if len(rows) > ImportMaxRows {
+		return report, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, ImportMaxRows)
+	}
+	if options.Atomic && len(rows) > BulkMaxTasks {
+		return report, fmt.Errorf("%w: atomic imports may have up to %d rows", ErrInvalidImport, BulkMaxTasks)
+	}
+
+	operations := []Domain.BulkOperation{}
+	// operationRows holds the index of the row of each operation.
+	operationRows := []int{}
+	seen := map[string]int{}
+	for index, fields := range rows {
+		row := Domain.ImportRow{Row: index + 1}
+		operation, externalID, err := t.importOperation(fields, options.Format)
+		row.ExternalID = externalID
+		if err == nil && row.ExternalID != "" {
+			if other, ok := seen[row.ExternalID]; ok {
+				err = fmt.Errorf("external ID %q is already used by row %d", row.ExternalID, other)
+			}
+			seen[row.ExternalID] = row.Row
+		}
+		row.Op = operation.Op
+		if err != nil {
+			row.Status = Domain.BulkFailed
+			row.Error = err.Error()
+		} else {
+			operations = append(operations, operation)
+			operationRows = append(operationRows, index)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	if options.Atomic && len(operations) < len(report.Rows) {
+		for _, index := range operationRows {
+			report.Rows[index].Status = Domain.BulkSkipped
+			report.Rows[index].Error = "another row failed"
+		}
+		countImport(&report)
+		return report, ErrBulkFailed
+	}
+
+	for start := 0; start < len(operations); start += BulkMaxTasks {
+		end := min(start+BulkMaxTasks, len(operations))
+		request := Domain.BulkRequest{Atomic: options.Atomic, DryRun: options.DryRun, Operations: operations[start:end]}
+		results, err := t.BulkTasks(request, actor)
+		if err != nil && !errors.Is(err, ErrBulkFailed) {
+			return report, err
+		}
+		for _, result := range results {
+			row := &report.Rows[operationRows[start+result.Index]]
+			row.Status = result.Status
+			row.Error = result.Error
+			row.Before = result.Before
+			row.Task = result.Task
+			if result.Op == Domain.BulkUpdate || !options.DryRun {
+				row.TaskID = result.ID
+			}
+		}
+		if err != nil {
+			countImport(&report)
+			return report, err
+		}
+	}
+	countImport(&report)
+	return report, nil
+}
+
+// importOperation turns a row into the bulk operation updating the task
+// with its external ID, or else creating a task.
+func (ws *workspace) importOperation(fields map[string]interface{}, format string) (Domain.BulkOperation, string, error) {
+	operation := Domain.BulkOperation{Op: Domain.BulkCreate}
+	set, err := importSet(fields, format)
+	if err != nil {
+		externalID, _ := fields["external_id"].(string)
+		return operation, strings.TrimSpace(externalID), err
+	}
+
+	externalID, _ := set["external_id"].(string)
+	delete(set, "external_id")
+	if externalID != "" {
+		if existing, err := ws.taskRepo.GetTaskByExternalID(externalID); err == nil {
+			operation.Op = Domain.BulkUpdate
+			if existing.DeletedAt != nil {
+				return operation, externalID, fmt.Errorf("task %d with this external ID is in the trash", existing.ID)
+			}
+			operation.ID = existing.ID
+			operation.Set = set
+			return operation, externalID, nil
+		}
+	}
+
+	task, err := applySet(Domain.Task{}, set)
+	if err != nil {
+		return operation, externalID, err
+	}
+	task.ExternalID = externalID
+	operation.Task = &task
+	return operation, externalID, nil
+}
+
+// importSet reads the values of a row as task fields. CSV values are text;
+// JSON ones are taken as they are, but for external IDs given as numbers.
+func importSet(fields map[string]interface{}, format string) (map[string]interface{}, error) {
+	set := map[string]interface{}{}
+	for name, value := range fields {
+		text, isText := value.(string)
+		if format != Domain.FormatCSV || !isText {
+			if number, ok := value.(float64); ok && name == "external_id" {
+				value = strconv.FormatFloat(number, 'f', -1, 64)
+			}
+			set[name] = value
+			continue
+		}
+
+		text = strings.TrimSpace(text)
+		var err error
+		switch importKinds[name] {
+		case "int":
+			number := 0
+			if text != "" {
+				number, err = strconv.Atoi(text)
+			}
+			set[name] = number
+		case "float":
+			number := 0.0
+			if text != "" {
+				number, err = strconv.ParseFloat(text, 64)
+			}
+			set[name] = number
+		case "list":
+			values := []string{}
+			for _, value := range strings.Split(text, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			set[name] = values
+		default:
+			set[name] = text
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s %q", ErrInvalidTask, name, text)
+		}
+	}
+	return set, nil
+}
+
+func countImport(report *Domain.ImportReport) {
+	report.Created, report.Updated, report.Failed = 0, 0, 0
+	for _, row := range report.Rows {
+		switch {
+		case row.Status == Domain.BulkFailed:
+			report.Failed++
+		case row.Status != Domain.BulkSucceeded && row.Status != Domain.BulkValid:
+		case row.Op == Domain.BulkCreate:
+			report.Created++
+		case row.Op == Domain.BulkUpdate:
+			report.Updated++
+		}
+	}
+}
+
+// ParseImport reads the rows of a CSV or JSON import as task fields, keyed
+// by their JSON names. A CSV import has a header; a JSON import is an array
+// of objects or JSON Lines. Columns and keys are mapped to fields through the
+// mapping, or else by name. It also returns the columns that are ignored,
+// because they name no task field that can be imported.
+func ParseImport(r io.Reader, format string, mapping map[string]string) ([]map[string]interface{}, []string, error) {
+	columns := map[string]string{}
+	for source, field := range mapping {
+		if _, ok := importKinds[field]; !ok || (field == "checklist" && format == Domain.FormatCSV) {
+			return nil, nil, fmt.Errorf("%w: %q cannot be imported", ErrInvalidImport, field)
+		}
+		columns[strings.ToLower(strings.TrimSpace(source))] = field
+	}
+	ignored := map[string]bool{}
+	fieldOf := func(column string) (string, bool) {
+		if field, ok := columns[strings.ToLower(strings.TrimSpace(column))]; ok {
+			return field, true
+		}
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(column)), " ", "_")
+		if _, ok := importKinds[name]; ok && !(name == "checklist" && format == Domain.FormatCSV) {
+			return name, true
+		}
+		ignored[column] = true
+		return "", false
+	}
+
+	var rows []map[string]interface{}
+	var err error
+	switch format {
+	case Domain.FormatCSV:
+		rows, err = parseCSVImport(r, fieldOf)
+	case Domain.FormatJSON, Domain.FormatJSONL:
+		rows, err = parseJSONImport(r, fieldOf)
+	default:
+		return nil, nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := []string{}
+	for name := range ignored {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return rows, names, nil
+}
+
+func parseCSVImport(r io.Reader, fieldOf func(string) (string, bool)) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	fields := make([]string, len(header))
+	mapped := false
+	for i, column := range header {
+		if field, ok := fieldOf(column); ok {
+			fields[i] = field
+			mapped = true
+		}
+	}
+	if !mapped {
+		return nil, fmt.Errorf("%w: no column maps to a task field", ErrInvalidImport)
+	}
+
+	rows := []map[string]interface{}{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		row := map[string]interface{}{}
+		for i, value := range record {
+			if fields[i] != "" {
+				row[fields[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseJSONImport(r io.Reader, fieldOf func(string) (string, bool)) ([]map[string]interface{}, error) {
+	reader := bufio.NewReader(r)
+	objects := []map[string]interface{}{}
+	start, err := firstByte(reader)
+	if err == io.EOF {
+		return objects, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(reader)
+	if start == '[' {
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+	} else {
+		for {
+			object := map[string]interface{}{}
+			err := decoder.Decode(&object)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidImport, len(objects)+1, err)
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	rows := make([]map[string]interface{}, len(objects))
+	for i, object := range objects {
+		rows[i] = map[string]interface{}{}
+		for key, value := range object {
+			if field, ok := fieldOf(key); ok {
+				rows[i][field] = value
+			}
+		}
+	}
+	return rows, nil
+}
+
+// firstByte peeks at the first byte that is not white space, after any
+// byte order mark.
+func firstByte(reader *bufio.Reader) (byte, error) {
+	if bom, err := reader.Peek(3); err == nil && string(bom) == "\ufeff" {
+		reader.Discard(3)
+	}
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, reader.UnreadByte()
+		}
+	}
+}
+
+// WriteTasks writes tasks in the given format: CSV, JSON Lines, or an
+// iCalendar with one to-do per task, stamped with the given time.
+func WriteTasks(w io.Writer, format string, tasks []Domain.Task, now time.Time) error {
+	switch format {
+	case Domain.FormatCSV:
+		return writeCSV(w, tasks)
+	case Domain.FormatJSONL:
+		encoder := json.NewEncoder(w)
+		for _, task := range tasks {
+			if err := encoder.Encode(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Domain.FormatICS:
+		return writeICalendar(w, tasks, now)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+func writeCSV(w io.Writer, tasks []Domain.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taskColumns); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		record := []string{
+			strconv.Itoa(task.ID),
+			task.ExternalID,
+			task.Title,
+			task.Description,
+			task.Status,
+			task.DueDate,
+			task.Assignee,
+			task.Priority,
+			strings.Join(task.Labels, ","),
+			formatNumber(task.StoryPoints),
+			formatNumber(float64(task.Estimate)),
+			formatNumber(float64(task.ProjectID)),
+			formatNumber(float64(task.ParentID)),
+			task.Recurrence,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatNumber writes a number for CSV, leaving zero out.
+func formatNumber(number float64) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// icalPriorities maps task priorities to iCalendar ones, where 1 is the
+// most urgent.
+var icalPriorities = map[string]int{"P0": 1, "P1": 3, "P2": 5, "P3": 7}
+
+func writeICalendar(w io.Writer, tasks []Domain.Task, now time.Time) error {
+	var calendar bytes.Buffer
+	writeICalLine(&calendar, "BEGIN:VCALENDAR")
+	writeICalLine(&calendar, "VERSION:2.0")
+	writeICalLine(&calendar, "PRODID:-//task_manager//Tasks//EN")
+	for _, task := range tasks {
+		writeICalLine(&calendar, "BEGIN:VTODO")
+		writeICalLine(&calendar, fmt.Sprintf("UID:task-%d@task_manager", task.ID))
+		writeICalLine(&calendar, "DTSTAMP:"+now.UTC().Format("20060102T150405Z"))
+		writeICalLine(&calendar, "SUMMARY:"+escapeICalText(task.Title))
+		if task.Description != "" {
+			writeICalLine(&calendar, "DESCRIPTION:"+escapeICalText(task.Description))
+		}
+		if date, err := time.Parse("2006-01-02", task.DueDate); err == nil {
+			writeICalLine(&calendar, "DUE;VALUE=DATE:"+date.Format("20060102"))
+		} else if due, ok := task.Due(); ok {
+			writeICalLine(&calendar, "DUE:"+due.UTC().Format("20060102T150405Z"))
+		}
+		writeICalLine(&calendar, "STATUS:"+icalStatus(task))
+		if priority, ok := icalPriorities[task.Priority]; ok {
+			writeICalLine(&calendar, "PRIORITY:"+strconv.Itoa(priority))
+		}
+		if len(task.Labels) > 0 {
+			labels := make([]string, len(task.Labels))
+			for i, label := range task.Labels {
+				labels[i] = escapeICalText(label)
+			}
+			writeICalLine(&calendar, "CATEGORIES:"+strings.Join(labels, ","))
+		}
+		writeICalLine(&calendar, "END:VTODO")
+	}
+	writeICalLine(&calendar, "END:VCALENDAR")
+	_, err := calendar.WriteTo(w)
+	return err
+}
+
+func icalStatus(task Domain.Task) string {
+	switch {
+	case task.IsCompleted():
+		return "COMPLETED"
+	case task.Status == "" || strings.EqualFold(task.Status, Domain.StatusPending):
+		return "NEEDS-ACTION"
+	default:
+		return "IN-PROCESS"
+	}
+}
+
+func escapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// writeICalLine writes a content line, folded into lines of at most 75
+// bytes as iCalendar requires, without splitting characters.
+func writeICalLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
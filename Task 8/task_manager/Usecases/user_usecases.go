@@ -1,83 +1,194 @@
 package Usecases
 
 import (
+	"context"
 	"errors"
+	"net/mail"
 	"task_manager/Domain"
-	"task_manager/Repositories"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-var userRepo Repositories.IUserRepository
+// PasswordCost is the bcrypt cost passwords are hashed with.
+var PasswordCost = bcrypt.DefaultCost
 
 type IUserService interface {
+	In(workspaceID int) IUserService
+	WithContext(ctx context.Context) IUserService
 	GetUsers() []Domain.User
 	CreateUser(user Domain.User) error
 	Promote(id int) error
 	GetUserbyUsername(username string) (Domain.User, error)
+	GetUserByID(id int) (Domain.User, error)
+	GetUsersByUsernames(usernames []string) ([]Domain.User, error)
 }
 
-type UserService struct{}
+type UserService struct {
+	*workspace
+}
 
 func NewUserService(dbName string) IUserService {
-	userRepo = Repositories.NewUserRepository(dbName)
+	return &UserService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
 
-	return &UserService{}
+// In returns the service over the members of the given workspace.
+func (u *UserService) In(workspaceID int) IUserService {
+	return &UserService{u.open(workspaceID)}
 }
 
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (u *UserService) WithContext(ctx context.Context) IUserService {
+	return &UserService{u.withContext(ctx)}
+}
+
+// GetUsers returns the members of the workspace, with the role they have in
+// it.
 func (u *UserService) GetUsers() []Domain.User {
+	defer u.span("UserService.GetUsers")()
+	members, err := u.workspaceRepo.GetMembers(u.id)
+	if err != nil {
+		return nil
+	}
+	return u.withRoles(members, nil)
+}
 
-	return userRepo.GetUsers()
+// GetUsersByUsernames returns the members of the workspace among the given
+// usernames, with the role they have in it, with one call to each repository
+// however many usernames there are.
+func (u *UserService) GetUsersByUsernames(usernames []string) ([]Domain.User, error) {
+	defer u.span("UserService.GetUsersByUsernames")()
+	members, err := u.workspaceRepo.GetMembers(u.id)
+	if err != nil {
+		return nil, err
+	}
+	wanted := map[string]bool{}
+	for _, username := range usernames {
+		wanted[username] = true
+	}
+	return u.withRoles(members, wanted), nil
+}
 
+// withRoles returns the users of the given members, or of the ones wanted
+// when not nil, in the order of the members, with their role in the
+// workspace.
+func (u *UserService) withRoles(members []Domain.Membership, wanted map[string]bool) []Domain.User {
+	roles := map[string]string{}
+	usernames := []string{}
+	for _, member := range members {
+		if wanted == nil || wanted[member.Username] {
+			roles[member.Username] = member.Role
+			usernames = append(usernames, member.Username)
+		}
+	}
+
+	users := []Domain.User{}
+	found, err := u.userRepo.GetUsersByUsernames(usernames)
+	if err != nil {
+		return users
+	}
+	byUsername := map[string]Domain.User{}
+	for _, user := range found {
+		byUsername[user.Username] = user
+	}
+	for _, username := range usernames {
+		if user, ok := byUsername[username]; ok {
+			user.Role = roles[username]
+			users = append(users, user)
+		}
+	}
+	return users
 }
 
+// CreateUser registers a user along with a workspace of their own, which
+// they administer. The first user to register gets the default workspace.
 func (u *UserService) CreateUser(user Domain.User) error {
-	users := u.GetUsers()
+	defer u.span("UserService.CreateUser")()
+	users := u.userRepo.GetUsers()
 	if len(users) == 0 {
 		user.Role = "admin"
 	} else {
 		user.Role = "user"
 	}
 
-	user.ID = GetNextUserID()
+	user.ID = u.userRepo.GetNextUserID()
 
 	user_name := user.Username
 
+	if user.Email != "" {
+		if _, err := mail.ParseAddress(user.Email); err != nil {
+			return errors.New("invalid email address")
+		}
+	}
+
 	for _, user := range users {
 		if user.Username == user_name {
 			return errors.New("user already exists")
 		}
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), PasswordCost)
 	if err != nil {
 		return err
 	}
 
 	user.Password = string(hashedPassword)
-	if err := userRepo.CreateUser(user); err != nil {
+	if err := u.userRepo.CreateUser(user); err != nil {
+		return err
+	}
+
+	workspace, err := (&WorkspaceService{u.workspace}).CreateWorkspace(user.Username, user.Username)
+	if err != nil {
 		return err
 	}
+	u.open(workspace.ID).publishEvent(Domain.Event{Type: Domain.ActionUserRegistered, Actor: user.Username, Data: userEventData(user)})
 
 	return nil
 }
 
+// Promote makes a member of the workspace one of its admins.
 func (u *UserService) Promote(id int) error {
-	if err := userRepo.Promote(id); err != nil {
+	defer u.span("UserService.Promote")()
+	user, err := u.userRepo.GetUserByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	member, err := u.member(user.Username)
+	if err != nil {
 		return err
 	}
+
+	member.Role = Domain.WorkspaceRoleAdmin
+	if err := u.workspaceRepo.SetMember(member); err != nil {
+		return err
+	}
+	user.Role = member.Role
+	u.publishEvent(Domain.Event{Type: Domain.ActionUserPromoted, Data: userEventData(user)})
 	return nil
 }
 
+// GetUserbyUsername looks a user up in the whole instance, as logins do.
 func (u *UserService) GetUserbyUsername(username string) (Domain.User, error) {
-	user, err := userRepo.GetUserbyUsername(username)
+	defer u.span("UserService.GetUserbyUsername")()
+	user, err := u.userRepo.GetUserbyUsername(username)
 	if err != nil {
 		return user, err
 	}
 	return user, nil
 }
 
-func GetNextUserID() int {
-	id := userRepo.GetNextUserID()
-	return id
+// GetUserByID returns a member of the workspace, with the role they have in
+// it.
+func (u *UserService) GetUserByID(id int) (Domain.User, error) {
+	defer u.span("UserService.GetUserByID")()
+	user, err := u.userRepo.GetUserByID(id)
+	if err != nil {
+		return user, err
+	}
+	member, err := u.member(user.Username)
+	if err != nil {
+		return Domain.User{}, err
+	}
+	user.Role = member.Role
+	return user, nil
 }
@@ -0,0 +1,383 @@
+package Usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var (
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+)
+
+// WebhookMaxAttempts is how many times a delivery is attempted before it is
+// dead-lettered.
+var WebhookMaxAttempts = 8
+
+// WebhookPollInterval is how often the delivery worker looks for deliveries
+// due for a retry. New deliveries are sent right away.
+var WebhookPollInterval = 5 * time.Second
+
+const (
+	webhookLease      = time.Minute
+	webhookMaxBackoff = 6 * time.Hour
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookWake tells the delivery worker that new deliveries are queued.
+var webhookWake = make(chan struct{}, 1)
+
+type IWebhookService interface {
+	In(workspaceID int) IWebhookService
+	WithContext(ctx context.Context) IWebhookService
+	CreateWebhook(webhook Domain.Webhook) (Domain.Webhook, error)
+	GetWebhooks() ([]Domain.Webhook, error)
+	GetWebhook(id int) (Domain.Webhook, error)
+	UpdateWebhook(id int, webhook Domain.Webhook) (Domain.Webhook, error)
+	DeleteWebhook(id int) error
+	GetDeliveries(webhookID int, status string) ([]Domain.WebhookDelivery, error)
+	GetDelivery(webhookID, deliveryID int) (Domain.WebhookDelivery, error)
+	Redeliver(webhookID, deliveryID int) (Domain.WebhookDelivery, error)
+	DeliverDue(now time.Time) int
+}
+
+type WebhookService struct {
+	*workspace
+}
+
+func NewWebhookService(dbName string) IWebhookService {
+	return &WebhookService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the webhooks of the given workspace.
+func (w *WebhookService) In(workspaceID int) IWebhookService {
+	return &WebhookService{w.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (w *WebhookService) WithContext(ctx context.Context) IWebhookService {
+	return &WebhookService{w.withContext(ctx)}
+}
+
+// CreateWebhook registers a webhook. A secret is generated when none is
+// given; it is only returned here.
+func (w *WebhookService) CreateWebhook(webhook Domain.Webhook) (Domain.Webhook, error) {
+	defer w.span("WebhookService.CreateWebhook")()
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return webhook, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	if err := validateWebhook(webhook); err != nil {
+		return webhook, err
+	}
+
+	webhook.ID = w.webhookRepo.GetNextWebhookID()
+	webhook.CreatedAt = time.Now().UTC()
+	if err := w.webhookRepo.CreateWebhook(webhook); err != nil {
+		return webhook, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookService) GetWebhooks() ([]Domain.Webhook, error) {
+	defer w.span("WebhookService.GetWebhooks")()
+	webhooks, err := w.webhookRepo.GetWebhooks()
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, err
+}
+
+func (w *WebhookService) GetWebhook(id int) (Domain.Webhook, error) {
+	defer w.span("WebhookService.GetWebhook")()
+	webhook, err := w.webhookRepo.GetWebhookByID(id)
+	if err != nil {
+		return webhook, ErrWebhookNotFound
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// UpdateWebhook changes the URL, events and state of a webhook. Its secret
+// is kept unless a new one is given.
+func (w *WebhookService) UpdateWebhook(id int, webhook Domain.Webhook) (Domain.Webhook, error) {
+	defer w.span("WebhookService.UpdateWebhook")()
+	existing, err := w.webhookRepo.GetWebhookByID(id)
+	if err != nil {
+		return webhook, ErrWebhookNotFound
+	}
+
+	webhook.ID = id
+	webhook.CreatedAt = existing.CreatedAt
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+	if err := validateWebhook(webhook); err != nil {
+		return webhook, err
+	}
+	if err := w.webhookRepo.UpdateWebhook(webhook); err != nil {
+		return webhook, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (w *WebhookService) DeleteWebhook(id int) error {
+	defer w.span("WebhookService.DeleteWebhook")()
+	if err := w.webhookRepo.DeleteWebhook(id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (w *WebhookService) GetDeliveries(webhookID int, status string) ([]Domain.WebhookDelivery, error) {
+	defer w.span("WebhookService.GetDeliveries")()
+	if _, err := w.webhookRepo.GetWebhookByID(webhookID); err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return w.webhookRepo.GetDeliveries(webhookID, status)
+}
+
+func (w *WebhookService) GetDelivery(webhookID, deliveryID int) (Domain.WebhookDelivery, error) {
+	defer w.span("WebhookService.GetDelivery")()
+	delivery, err := w.webhookRepo.GetDeliveryByID(deliveryID)
+	if err != nil || delivery.WebhookID != webhookID {
+		return delivery, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver queues a delivery again, whatever became of it, as a new
+// delivery with the same payload.
+func (w *WebhookService) Redeliver(webhookID, deliveryID int) (Domain.WebhookDelivery, error) {
+	defer w.span("WebhookService.Redeliver")()
+	original, err := w.GetDelivery(webhookID, deliveryID)
+	if err != nil {
+		return original, err
+	}
+
+	now := time.Now().UTC()
+	delivery := Domain.WebhookDelivery{
+		ID:            w.webhookRepo.GetNextDeliveryID(),
+		WebhookID:     webhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        Domain.DeliveryPending,
+		Attempts:      []Domain.DeliveryAttempt{},
+		NextAttemptAt: now,
+		RedeliveryOf:  original.ID,
+		CreatedAt:     now,
+	}
+	if err := w.webhookRepo.CreateDelivery(delivery); err != nil {
+		return delivery, err
+	}
+	wakeWebhookWorker()
+	return delivery, nil
+}
+
+// DeliverDue attempts every delivery due by now and returns how many were
+// attempted.
+func (w *WebhookService) DeliverDue(now time.Time) int {
+	defer w.span("WebhookService.DeliverDue")()
+	attempted := 0
+	for {
+		delivery, err := w.webhookRepo.ClaimDueDelivery(now, webhookLease)
+		if err != nil {
+			return attempted
+		}
+		w.deliver(delivery)
+		attempted++
+	}
+}
+
+// StartWebhookWorker sends the queued deliveries of every workspace in the
+// background, as soon as they are queued and then as their retries fall due,
+// until ctx is done. The returned channel is closed once the worker has
+// stopped; the deliveries left are sent after the next start.
+func StartWebhookWorker(ctx context.Context, service IWebhookService, workspaces IWorkspaceService) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(WebhookPollInterval)
+		defer ticker.Stop()
+		for {
+			forEachWorkspace(ctx, workspaces, func(id int) {
+				service.In(id).DeliverDue(time.Now())
+			})
+			select {
+			case <-ticker.C:
+			case <-webhookWake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of a payload sent at the
+// given Unix time, as sent in the X-Webhook-Signature header. The timestamp
+// is signed along with the payload so that a captured request cannot be
+// replayed later on.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookBackoff returns how long to wait before retrying a delivery that
+// failed the given number of times: a minute, doubling with each failure.
+func WebhookBackoff(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	if failures > 10 {
+		return webhookMaxBackoff
+	}
+	return min(time.Minute<<(failures-1), webhookMaxBackoff)
+}
+
+// queueDeliveries queues an event for the webhooks of the workspace
+// subscribed to it.
+func (ws *workspace) queueDeliveries(event Domain.Event) {
+	subscribers, err := ws.webhookRepo.GetSubscribers(event.Type)
+	if err != nil || len(subscribers) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ws.ctx, "event not encoded", "event_id", event.ID, "error", err)
+		return
+	}
+	now := time.Now().UTC()
+	for _, webhook := range subscribers {
+		delivery := Domain.WebhookDelivery{
+			ID:            ws.webhookRepo.GetNextDeliveryID(),
+			WebhookID:     webhook.ID,
+			Event:         event.Type,
+			Payload:       string(payload),
+			Status:        Domain.DeliveryPending,
+			Attempts:      []Domain.DeliveryAttempt{},
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := ws.webhookRepo.CreateDelivery(delivery); err != nil {
+			slog.ErrorContext(ws.ctx, "delivery not queued", "webhook_id", webhook.ID, "event_id", event.ID, "error", err)
+		}
+	}
+	wakeWebhookWorker()
+}
+
+func wakeWebhookWorker() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver makes one attempt at a delivery and records its outcome: success,
+// a retry after a backoff, or the dead letter once WebhookMaxAttempts is
+// reached.
+func (ws *workspace) deliver(delivery Domain.WebhookDelivery) {
+	attempt := Domain.DeliveryAttempt{Timestamp: time.Now().UTC()}
+	webhook, err := ws.webhookRepo.GetWebhookByID(delivery.WebhookID)
+	if err != nil {
+		attempt.Error = "webhook not found"
+	} else {
+		attempt.StatusCode, err = postWebhook(webhook, delivery, attempt.Timestamp)
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+	}
+	attempt.DurationMs = time.Since(attempt.Timestamp).Milliseconds()
+
+	status := Domain.DeliverySucceeded
+	next := attempt.Timestamp
+	failures := len(delivery.Attempts) + 1
+	if attempt.Error != "" {
+		if failures >= WebhookMaxAttempts {
+			status = Domain.DeliveryDead
+		} else {
+			status = Domain.DeliveryPending
+			next = next.Add(WebhookBackoff(failures))
+		}
+	}
+	if err := ws.webhookRepo.RecordAttempt(delivery.ID, attempt, status, next); err != nil {
+		slog.ErrorContext(ws.ctx, "delivery attempt not recorded", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// postWebhook posts the payload of a delivery to a webhook and returns the
+// status code of the answer. Any answer but 2xx is an error.
+func postWebhook(webhook Domain.Webhook, delivery Domain.WebhookDelivery, now time.Time) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "task-manager-webhooks")
+	request.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.ID))
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	response, err := webhookClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+func validateWebhook(webhook Domain.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(webhook.Events) == 0 {
+		return fmt.Errorf("%w: it must subscribe to at least one event", ErrInvalidWebhook)
+	}
+	for _, event := range webhook.Events {
+		if event != Domain.WebhookAllEvents && !knownEvent(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+func knownEvent(eventType string) bool {
+	for _, known := range Domain.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
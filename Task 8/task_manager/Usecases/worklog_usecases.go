@@ -0,0 +1,258 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+var (
+	ErrInvalidWorklog  = errors.New("invalid worklog")
+	ErrWorklogNotFound = errors.New("worklog not found")
+	ErrTimerRunning    = errors.New("a timer is already running on this task")
+	ErrNoTimer         = errors.New("no timer running on this task")
+	ErrNotWorklogOwner = errors.New("only the user who logged the time can delete it")
+)
+
+// WorklogMaxMinutes is the longest time a single worklog entry can log.
+const WorklogMaxMinutes = 24 * 60
+
+// worklogNoteMax is the longest note a worklog can have, in characters.
+const worklogNoteMax = 2000
+
+type IWorklogService interface {
+	In(workspaceID int) IWorklogService
+	WithContext(ctx context.Context) IWorklogService
+	LogTime(taskID int, worklog Domain.Worklog, actor string) (Domain.Worklog, error)
+	StartTimer(taskID int, actor string) (Domain.Worklog, error)
+	StopTimer(taskID int, note string, actor string) (Domain.Worklog, error)
+	GetWorklogs(filter Domain.WorklogFilter) ([]Domain.Worklog, error)
+	DeleteWorklog(taskID, id int, actor string, isAdmin bool) (Domain.Worklog, error)
+	GetTotals(filter Domain.WorklogFilter, groupBy []string) ([]Domain.TimeTotal, error)
+	GetTimeReport(projectID int, taskFilter Domain.TaskFilter, filter Domain.WorklogFilter) (Domain.TimeReport, error)
+}
+
+type WorklogService struct {
+	*workspace
+}
+
+func NewWorklogService(dbName string) IWorklogService {
+	return &WorklogService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the worklogs of the given workspace.
+func (w *WorklogService) In(workspaceID int) IWorklogService {
+	return &WorklogService{w.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (w *WorklogService) WithContext(ctx context.Context) IWorklogService {
+	return &WorklogService{w.withContext(ctx)}
+}
+
+// LogTime records time spent on a task by hand. A worklog without a start
+// time is taken to have ended now.
+func (w *WorklogService) LogTime(taskID int, worklog Domain.Worklog, actor string) (Domain.Worklog, error) {
+	defer w.span("WorklogService.LogTime")()
+	if _, err := w.taskRepo.GetTaskByID(taskID); err != nil {
+		return worklog, err
+	}
+	if worklog.Minutes <= 0 || worklog.Minutes > WorklogMaxMinutes {
+		return worklog, fmt.Errorf("%w: minutes must be between 1 and %d", ErrInvalidWorklog, WorklogMaxMinutes)
+	}
+	worklog.Note = strings.TrimSpace(worklog.Note)
+	if len([]rune(worklog.Note)) > worklogNoteMax {
+		return worklog, fmt.Errorf("%w: note is longer than %d characters", ErrInvalidWorklog, worklogNoteMax)
+	}
+
+	now := time.Now().UTC()
+	if worklog.StartedAt.IsZero() {
+		worklog.StartedAt = now.Add(-time.Duration(worklog.Minutes) * time.Minute)
+	}
+	worklog.StartedAt = worklog.StartedAt.UTC()
+	if worklog.StartedAt.After(now) {
+		return worklog, fmt.Errorf("%w: started_at is in the future", ErrInvalidWorklog)
+	}
+	ended := worklog.StartedAt.Add(time.Duration(worklog.Minutes) * time.Minute)
+
+	worklog.ID = w.worklogRepo.GetNextWorklogID()
+	worklog.TaskID = taskID
+	worklog.User = actor
+	worklog.EndedAt = &ended
+	worklog.Running = false
+	worklog.CreatedAt = now
+	if err := w.worklogRepo.CreateWorklog(worklog); err != nil {
+		return worklog, err
+	}
+	return worklog, nil
+}
+
+// StartTimer starts timing the work of a user on a task. A user can have
+// timers running on several tasks, but only one per task.
+func (w *WorklogService) StartTimer(taskID int, actor string) (Domain.Worklog, error) {
+	defer w.span("WorklogService.StartTimer")()
+	if _, err := w.taskRepo.GetTaskByID(taskID); err != nil {
+		return Domain.Worklog{}, err
+	}
+
+	now := time.Now().UTC()
+	worklog := Domain.Worklog{
+		ID:        w.worklogRepo.GetNextWorklogID(),
+		TaskID:    taskID,
+		User:      actor,
+		StartedAt: now,
+		Running:   true,
+		CreatedAt: now,
+	}
+	started, err := w.worklogRepo.StartTimer(worklog)
+	if err != nil {
+		return worklog, err
+	}
+	if !started {
+		return worklog, ErrTimerRunning
+	}
+	return worklog, nil
+}
+
+// StopTimer stops the timer a user has running on a task, turning it into a
+// worklog of the time elapsed. The note replaces the timer's note when set.
+func (w *WorklogService) StopTimer(taskID int, note string, actor string) (Domain.Worklog, error) {
+	defer w.span("WorklogService.StopTimer")()
+	worklog, err := w.worklogRepo.GetRunningTimer(taskID, actor)
+	if err != nil {
+		return worklog, ErrNoTimer
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		if len([]rune(note)) > worklogNoteMax {
+			return worklog, fmt.Errorf("%w: note is longer than %d characters", ErrInvalidWorklog, worklogNoteMax)
+		}
+		worklog.Note = note
+	}
+
+	ended := time.Now().UTC()
+	worklog.EndedAt = &ended
+	worklog.Minutes = TimerMinutes(worklog.StartedAt, ended)
+	worklog.Running = false
+	if err := w.worklogRepo.StopTimer(worklog); err != nil {
+		return worklog, ErrNoTimer
+	}
+	return worklog, nil
+}
+
+func (w *WorklogService) GetWorklogs(filter Domain.WorklogFilter) ([]Domain.Worklog, error) {
+	defer w.span("WorklogService.GetWorklogs")()
+	return w.worklogRepo.GetWorklogs(filter)
+}
+
+// DeleteWorklog removes a worklog or a running timer. Users can delete their
+// own worklogs and admins any worklog.
+func (w *WorklogService) DeleteWorklog(taskID, id int, actor string, isAdmin bool) (Domain.Worklog, error) {
+	defer w.span("WorklogService.DeleteWorklog")()
+	worklog, err := w.worklogRepo.GetWorklogByID(id)
+	if err != nil || worklog.TaskID != taskID {
+		return worklog, ErrWorklogNotFound
+	}
+	if worklog.User != actor && !isAdmin {
+		return worklog, ErrNotWorklogOwner
+	}
+
+	if err := w.worklogRepo.DeleteWorklog(id); err != nil {
+		return worklog, ErrWorklogNotFound
+	}
+	return worklog, nil
+}
+
+// GetTotals sums the time logged by task, user or day, or a combination of
+// them. Running timers are not counted until they are stopped.
+func (w *WorklogService) GetTotals(filter Domain.WorklogFilter, groupBy []string) ([]Domain.TimeTotal, error) {
+	defer w.span("WorklogService.GetTotals")()
+	for _, group := range groupBy {
+		if group != Domain.GroupByTask && group != Domain.GroupByUser && group != Domain.GroupByDay {
+			return nil, fmt.Errorf("%w: cannot group by %q", ErrInvalidWorklog, group)
+		}
+	}
+	return w.worklogRepo.TotalWorklogs(filter, groupBy)
+}
+
+// GetTimeReport compares the time logged on the tasks matching a filter, and
+// belonging to a project when projectID is set, with their estimates.
+func (w *WorklogService) GetTimeReport(projectID int, taskFilter Domain.TaskFilter, filter Domain.WorklogFilter) (Domain.TimeReport, error) {
+	defer w.span("WorklogService.GetTimeReport")()
+	tasks := []Domain.Task{}
+	for _, task := range w.taskRepo.FindTasks(taskFilter) {
+		if projectID == 0 || task.ProjectID == projectID {
+			tasks = append(tasks, task)
+		}
+	}
+	if len(tasks) == 0 {
+		return BuildTimeReport(tasks, nil), nil
+	}
+
+	filter.TaskIDs = make([]int, len(tasks))
+	for i, task := range tasks {
+		filter.TaskIDs[i] = task.ID
+	}
+	totals, err := w.worklogRepo.TotalWorklogs(filter, []string{Domain.GroupByTask, Domain.GroupByUser})
+	if err != nil {
+		return Domain.TimeReport{}, err
+	}
+	return BuildTimeReport(tasks, totals), nil
+}
+
+// BuildTimeReport sets the time logged per task and user against the
+// estimates of the tasks. Tasks with neither an estimate nor time logged are
+// left out.
+func BuildTimeReport(tasks []Domain.Task, totals []Domain.TimeTotal) Domain.TimeReport {
+	logged := map[int]map[string]int{}
+	for _, total := range totals {
+		if logged[total.TaskID] == nil {
+			logged[total.TaskID] = map[string]int{}
+		}
+		logged[total.TaskID][total.User] += total.Minutes
+	}
+
+	report := Domain.TimeReport{Tasks: []Domain.TaskTimeUsage{}}
+	for _, task := range tasks {
+		usage := Domain.TaskTimeUsage{
+			TaskID:          task.ID,
+			Title:           task.Title,
+			Status:          task.Status,
+			Assignee:        task.Assignee,
+			EstimateMinutes: task.Estimate,
+			Users:           map[string]int{},
+		}
+		for user, minutes := range logged[task.ID] {
+			usage.Users[user] = minutes
+			usage.LoggedMinutes += minutes
+		}
+		if usage.EstimateMinutes == 0 && usage.LoggedMinutes == 0 {
+			continue
+		}
+		usage.VarianceMinutes = usage.LoggedMinutes - usage.EstimateMinutes
+		usage.OverEstimate = usage.EstimateMinutes > 0 && usage.VarianceMinutes > 0
+
+		report.EstimateMinutes += usage.EstimateMinutes
+		report.LoggedMinutes += usage.LoggedMinutes
+		report.Tasks = append(report.Tasks, usage)
+	}
+	return report
+}
+
+// TimerMinutes is the time between the start and end of a timer, rounded to
+// the nearest minute.
+func TimerMinutes(started, ended time.Time) int {
+	return int(math.Round(ended.Sub(started).Minutes()))
+}
+
+// removeTaskWorklogs deletes the worklogs of tasks purged from the trash.
+func (ws *workspace) removeTaskWorklogs(taskIDs []int) {
+	if err := ws.worklogRepo.DeleteWorklogsByTasks(taskIDs); err != nil {
+		slog.ErrorContext(ws.ctx, "worklogs of purged tasks left behind", "error", err)
+	}
+}
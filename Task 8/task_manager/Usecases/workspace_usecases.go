@@ -0,0 +1,431 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+var (
+	ErrInvalidWorkspace  = errors.New("invalid workspace")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrNotMember         = errors.New("not a member of the workspace")
+	ErrLastAdmin         = errors.New("the workspace must keep an admin")
+)
+
+// workspace holds the repositories a service works with. Those of the
+// workspace's data are all bound to the workspace's own database, so nothing
+// read or written through them can belong to another workspace; the users
+// and the memberships are shared by the whole instance. The services embed
+// the workspace they work on, and In gives them another one.
+//
+// The workspaces opened are shared by the services and serve no request in
+// particular. WithContext gives a service a copy of its workspace bound to
+// the context of a request, which the repositories then work within.
+type workspace struct {
+	id     int
+	dbName string
+	// ctx is the context of the request the workspace is bound to, nil for
+	// the shared workspaces.
+	ctx context.Context
+
+	taskRepo       Repositories.ITaskRepository
+	historyRepo    Repositories.ITaskHistoryRepository
+	projectRepo    Repositories.IProjectRepository
+	labelRepo      Repositories.ILabelRepository
+	commentRepo    Repositories.ICommentRepository
+	attachmentRepo Repositories.IAttachmentRepository
+	worklogRepo    Repositories.IWorklogRepository
+	reminderRepo   Repositories.IReminderRepository
+	reportRepo     Repositories.IReportRepository
+	searchRepo     Repositories.ISearchRepository
+	auditRepo      Repositories.IAuditRepository
+	webhookRepo    Repositories.IWebhookRepository
+
+	userRepo      Repositories.IUserRepository
+	workspaceRepo Repositories.IWorkspaceRepository
+}
+
+var (
+	workspacesMu sync.Mutex
+	// workspaces are the workspaces opened so far, by database name.
+	workspaces = map[string]*workspace{}
+	// memorySearch and memoryReports tell whether the workspaces search and
+	// report in the API process; see UseMemorySearch and UseMemoryReports.
+	memorySearch  bool
+	memoryReports bool
+)
+
+// openWorkspace returns the repositories of a workspace of the instance
+// database dbName, creating them the first time.
+func openWorkspace(dbName string, id int) *workspace {
+	database := Repositories.WorkspaceDatabase(dbName, id)
+
+	workspacesMu.Lock()
+	ws, ok := workspaces[database]
+	search, reports := memorySearch, memoryReports
+	workspacesMu.Unlock()
+	if ok {
+		return ws
+	}
+
+	// The workspace is set up without the lock, as building its search index
+	// reads all of it. Should another request open it meanwhile, the first
+	// one to finish wins.
+	ws = &workspace{
+		id:             id,
+		dbName:         dbName,
+		taskRepo:       Repositories.NewTaskRepository(database),
+		historyRepo:    Repositories.NewTaskHistoryRepository(database),
+		projectRepo:    Repositories.NewProjectRepository(database),
+		labelRepo:      Repositories.NewLabelRepository(database),
+		commentRepo:    Repositories.NewCommentRepository(database),
+		attachmentRepo: Repositories.NewAttachmentRepository(database),
+		worklogRepo:    Repositories.NewWorklogRepository(database),
+		reminderRepo:   Repositories.NewReminderRepository(database),
+		reportRepo:     Repositories.NewReportRepository(database),
+		auditRepo:      Repositories.NewAuditRepository(database),
+		webhookRepo:    Repositories.NewWebhookRepository(database),
+		userRepo:       Repositories.NewUserRepository(dbName),
+		workspaceRepo:  Repositories.NewWorkspaceRepository(dbName),
+	}
+	if search {
+		ws.searchRepo = ws.searchIndex()
+	} else {
+		ws.searchRepo = Repositories.NewSearchRepository(database)
+	}
+	if reports {
+		ws.reportRepo = ws.memoryReports()
+	}
+
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	if opened, ok := workspaces[database]; ok {
+		return opened
+	}
+	workspaces[database] = ws
+	return ws
+}
+
+// open returns the repositories of another workspace of the same instance,
+// bound to the same request.
+func (ws *workspace) open(id int) *workspace {
+	opened := openWorkspace(ws.dbName, id)
+	if ws.ctx != nil {
+		return opened.withContext(ws.ctx)
+	}
+	return opened
+}
+
+// withContext returns a copy of the workspace bound to the context of a
+// request.
+func (ws *workspace) withContext(ctx context.Context) *workspace {
+	bound := *ws
+	bound.bind(ctx)
+	return &bound
+}
+
+// bind makes the repositories of the workspace work within ctx.
+func (ws *workspace) bind(ctx context.Context) {
+	ws.ctx = ctx
+	ws.taskRepo = ws.taskRepo.WithContext(ctx)
+	ws.historyRepo = ws.historyRepo.WithContext(ctx)
+	ws.projectRepo = ws.projectRepo.WithContext(ctx)
+	ws.labelRepo = ws.labelRepo.WithContext(ctx)
+	ws.commentRepo = ws.commentRepo.WithContext(ctx)
+	ws.attachmentRepo = ws.attachmentRepo.WithContext(ctx)
+	ws.worklogRepo = ws.worklogRepo.WithContext(ctx)
+	ws.reminderRepo = ws.reminderRepo.WithContext(ctx)
+	ws.reportRepo = ws.reportRepo.WithContext(ctx)
+	ws.searchRepo = ws.searchRepo.WithContext(ctx)
+	ws.auditRepo = ws.auditRepo.WithContext(ctx)
+	ws.webhookRepo = ws.webhookRepo.WithContext(ctx)
+	ws.userRepo = ws.userRepo.WithContext(ctx)
+	ws.workspaceRepo = ws.workspaceRepo.WithContext(ctx)
+}
+
+var tracer = otel.Tracer("task_manager/Usecases")
+
+// span traces a service method, the repository calls it makes included, as
+// a span of the request the workspace is bound to, when deferred at its
+// start:
+//
+//	defer t.span("TaskService.GetTasks")()
+//
+// The shared workspaces serve no request and are not traced.
+func (ws *workspace) span(name string) func() {
+	if ws.ctx == nil {
+		return func() {}
+	}
+	parent := ws.ctx
+	ctx, span := tracer.Start(parent, name)
+	ws.bind(ctx)
+	return func() {
+		ws.bind(parent)
+		span.End()
+	}
+}
+
+// member returns the membership of a user in the workspace.
+func (ws *workspace) member(username string) (Domain.Membership, error) {
+	member, err := ws.workspaceRepo.GetMember(ws.id, username)
+	if err != nil {
+		return member, fmt.Errorf("%w: %s", ErrNotMember, username)
+	}
+	return member, nil
+}
+
+type IWorkspaceService interface {
+	In(workspaceID int) IWorkspaceService
+	WithContext(ctx context.Context) IWorkspaceService
+	CreateWorkspace(name, creator string) (Domain.Workspace, error)
+	GetAllWorkspaces() ([]Domain.Workspace, error)
+	GetWorkspaces(username string) ([]Domain.UserWorkspace, error)
+	GetMembership(workspaceID int, username string) (Domain.Membership, error)
+	GetDefaultMembership(username string) (Domain.Membership, error)
+	GetMembers() ([]Domain.Membership, error)
+	SetMember(member Domain.Membership) (Domain.Membership, error)
+	RemoveMember(username string) error
+	Migrate() error
+}
+
+type WorkspaceService struct {
+	*workspace
+}
+
+func NewWorkspaceService(dbName string) IWorkspaceService {
+	return &WorkspaceService{openWorkspace(dbName, Domain.DefaultWorkspaceID)}
+}
+
+// In returns the service over the members of the given workspace.
+func (w *WorkspaceService) In(workspaceID int) IWorkspaceService {
+	return &WorkspaceService{w.open(workspaceID)}
+}
+
+// WithContext returns the service working within ctx, the context of the
+// request it serves.
+func (w *WorkspaceService) WithContext(ctx context.Context) IWorkspaceService {
+	return &WorkspaceService{w.withContext(ctx)}
+}
+
+// CreateWorkspace creates a workspace administered by its creator.
+func (w *WorkspaceService) CreateWorkspace(name, creator string) (Domain.Workspace, error) {
+	defer w.span("WorkspaceService.CreateWorkspace")()
+	workspace := Domain.Workspace{
+		ID:        w.workspaceRepo.GetNextWorkspaceID(),
+		Name:      strings.TrimSpace(name),
+		CreatedBy: creator,
+		CreatedAt: time.Now().UTC(),
+	}
+	if workspace.Name == "" {
+		return workspace, fmt.Errorf("%w: name is required", ErrInvalidWorkspace)
+	}
+	if err := w.workspaceRepo.CreateWorkspace(workspace); err != nil {
+		return workspace, err
+	}
+
+	member := Domain.Membership{
+		WorkspaceID: workspace.ID,
+		Username:    creator,
+		Role:        Domain.WorkspaceRoleAdmin,
+		JoinedAt:    workspace.CreatedAt,
+	}
+	if err := w.workspaceRepo.SetMember(member); err != nil {
+		return workspace, err
+	}
+	return workspace, nil
+}
+
+// GetAllWorkspaces returns every workspace of the instance, for the
+// background jobs that go through all of them.
+func (w *WorkspaceService) GetAllWorkspaces() ([]Domain.Workspace, error) {
+	defer w.span("WorkspaceService.GetAllWorkspaces")()
+	return w.workspaceRepo.GetWorkspaces()
+}
+
+// GetWorkspaces returns the workspaces a user is a member of, with the role
+// the user has in each.
+func (w *WorkspaceService) GetWorkspaces(username string) ([]Domain.UserWorkspace, error) {
+	defer w.span("WorkspaceService.GetWorkspaces")()
+	memberships, err := w.workspaceRepo.GetMemberships(username)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := []Domain.UserWorkspace{}
+	for _, membership := range memberships {
+		workspace, err := w.workspaceRepo.GetWorkspaceByID(membership.WorkspaceID)
+		if err != nil {
+			continue
+		}
+		workspaces = append(workspaces, Domain.UserWorkspace{Workspace: workspace, Role: membership.Role})
+	}
+	return workspaces, nil
+}
+
+// GetMembership returns the membership of a user in a workspace. Workspaces
+// the user is not a member of are not found, whether they exist or not.
+func (w *WorkspaceService) GetMembership(workspaceID int, username string) (Domain.Membership, error) {
+	defer w.span("WorkspaceService.GetMembership")()
+	member, err := w.workspaceRepo.GetMember(workspaceID, username)
+	if err != nil {
+		return member, ErrWorkspaceNotFound
+	}
+	return member, nil
+}
+
+// GetDefaultMembership returns the membership of a user in the oldest
+// workspace the user is a member of, the one logins go to by default.
+func (w *WorkspaceService) GetDefaultMembership(username string) (Domain.Membership, error) {
+	defer w.span("WorkspaceService.GetDefaultMembership")()
+	memberships, err := w.workspaceRepo.GetMemberships(username)
+	if err != nil {
+		return Domain.Membership{}, err
+	}
+	if len(memberships) == 0 {
+		return Domain.Membership{}, ErrWorkspaceNotFound
+	}
+	return memberships[0], nil
+}
+
+func (w *WorkspaceService) GetMembers() ([]Domain.Membership, error) {
+	defer w.span("WorkspaceService.GetMembers")()
+	return w.workspaceRepo.GetMembers(w.id)
+}
+
+// SetMember adds a registered user to the workspace, or changes the role of
+// a member. The last admin cannot be demoted.
+func (w *WorkspaceService) SetMember(member Domain.Membership) (Domain.Membership, error) {
+	defer w.span("WorkspaceService.SetMember")()
+	member.WorkspaceID = w.id
+	if member.Role == "" {
+		member.Role = Domain.WorkspaceRoleMember
+	}
+	if member.Role != Domain.WorkspaceRoleAdmin && member.Role != Domain.WorkspaceRoleMember {
+		return member, fmt.Errorf("%w: role must be %s or %s", ErrInvalidWorkspace, Domain.WorkspaceRoleAdmin, Domain.WorkspaceRoleMember)
+	}
+	if _, err := w.userRepo.GetUserbyUsername(member.Username); err != nil {
+		return member, fmt.Errorf("%w: unknown user %q", ErrInvalidWorkspace, member.Username)
+	}
+
+	existing, err := w.workspaceRepo.GetMember(w.id, member.Username)
+	if err == nil {
+		member.JoinedAt = existing.JoinedAt
+		if member.Role != Domain.WorkspaceRoleAdmin {
+			if err := w.keepAnAdmin(existing); err != nil {
+				return member, err
+			}
+		}
+	} else {
+		member.JoinedAt = time.Now().UTC()
+	}
+
+	if err := w.workspaceRepo.SetMember(member); err != nil {
+		return member, err
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of the workspace. The last admin cannot be
+// removed.
+func (w *WorkspaceService) RemoveMember(username string) error {
+	defer w.span("WorkspaceService.RemoveMember")()
+	existing, err := w.member(username)
+	if err != nil {
+		return err
+	}
+	if err := w.keepAnAdmin(existing); err != nil {
+		return err
+	}
+	return w.workspaceRepo.RemoveMember(w.id, username)
+}
+
+// Migrate puts the users from before workspaces, who are members of none,
+// into the default workspace with the role they had, creating the workspace
+// if need be. The data from before workspaces is already in the default
+// workspace's database.
+func (w *WorkspaceService) Migrate() error {
+	defer w.span("WorkspaceService.Migrate")()
+	users := w.userRepo.GetUsers()
+	if len(users) == 0 {
+		return nil
+	}
+
+	if _, err := w.workspaceRepo.GetWorkspaceByID(Domain.DefaultWorkspaceID); err != nil {
+		workspace := Domain.Workspace{
+			ID:        Domain.DefaultWorkspaceID,
+			Name:      "Default",
+			CreatedBy: users[0].Username,
+			CreatedAt: time.Now().UTC(),
+		}
+		if err := w.workspaceRepo.CreateWorkspace(workspace); err != nil {
+			return err
+		}
+	}
+
+	for _, user := range users {
+		memberships, err := w.workspaceRepo.GetMemberships(user.Username)
+		if err != nil {
+			return err
+		}
+		if len(memberships) > 0 {
+			continue
+		}
+
+		member := Domain.Membership{
+			WorkspaceID: Domain.DefaultWorkspaceID,
+			Username:    user.Username,
+			Role:        Domain.WorkspaceRoleMember,
+			JoinedAt:    time.Now().UTC(),
+		}
+		if user.Role == "admin" {
+			member.Role = Domain.WorkspaceRoleAdmin
+		}
+		if err := w.workspaceRepo.SetMember(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachWorkspace runs a background job over every workspace of the
+// instance, stopping between two workspaces once ctx is done.
+func forEachWorkspace(ctx context.Context, workspaces IWorkspaceService, job func(id int)) {
+	all, err := workspaces.GetAllWorkspaces()
+	if err != nil {
+		slog.Error("workspaces not listed", "error", err)
+		return
+	}
+	for _, workspace := range all {
+		if ctx.Err() != nil {
+			return
+		}
+		job(workspace.ID)
+	}
+}
+
+// keepAnAdmin refuses to demote or remove a member who is the workspace's
+// last admin.
+func (w *WorkspaceService) keepAnAdmin(existing Domain.Membership) error {
+	if existing.Role != Domain.WorkspaceRoleAdmin {
+		return nil
+	}
+	members, err := w.workspaceRepo.GetMembers(w.id)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == Domain.WorkspaceRoleAdmin && member.Username != existing.Username {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}